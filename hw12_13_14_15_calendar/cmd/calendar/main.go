@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
 	internalgrpc "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/server/grpc"
 	internalhttp "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/server/http"
 	storage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
 var (
@@ -40,7 +42,15 @@ func main() {
 		log.Fatal(err)
 	}
 
-	logg := logger.New(cfg.Logger.Level)
+	logg, err := logger.NewFromConfig(cfg.Logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	shutdownTracing, err := tracing.Init(cfg.Tracing, "calendar")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	storage, err := storage.New(cfg)
 	if err != nil {
@@ -52,8 +62,13 @@ func main() {
 
 	calendar := app.New(storage)
 
-	server := internalhttp.NewServer(logg, calendar, cfg)
-	GRPCServer := internalgrpc.NewGRPCServer(logg, calendar, cfg)
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := internalhttp.NewServer(logg, calendar, authenticator, cfg)
+	GRPCServer := internalgrpc.NewGRPCServer(logg, calendar, authenticator, cfg)
 
 	ctx, cancel := signal.NotifyContext(context.Background(),
 		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
@@ -92,5 +107,10 @@ func main() {
 
 	wg.Wait()
 	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*3)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logg.Error("failed to flush traces: " + err.Error())
+	}
+	shutdownCancel()
 	os.Exit(1) //nolint:gocritic
 }
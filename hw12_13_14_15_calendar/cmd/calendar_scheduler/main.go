@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
@@ -16,6 +17,7 @@ import (
 	queue "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/init"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/scheduler"
 	storage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
 var (
@@ -40,7 +42,16 @@ func main() {
 		log.Fatal(err)
 	}
 
-	logg := logger.New(cfg.Logger.Level)
+	logg, err := logger.NewFromConfig(cfg.Logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	shutdownTracing, err := tracing.Init(cfg.Tracing, "calendar_scheduler")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	storage, err := storage.New(cfg)
 	if err != nil {
 		log.Fatal(err)
@@ -58,8 +69,17 @@ func main() {
 	}
 	defer storage.Close()
 
+	if err = queue.Connect(); err != nil {
+		log.Fatal(err) //nolint:gocritic
+	}
+	defer queue.Close()
+
 	calendar := app.New(storage)
-	scheduler := scheduler.New(logg, calendar, queue, cfg)
+	jobScheduler, err := scheduler.New(logg, calendar, queue, cfg)
+	if err != nil {
+		log.Fatal(err) //nolint:gocritic
+	}
+	adminServer := scheduler.NewAdminServer(logg, jobScheduler, cfg.Scheduler.Admin)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer cancel()
@@ -67,7 +87,12 @@ func main() {
 	go func() {
 		<-ctx.Done()
 		fmt.Println(ctx.Err())
-		os.Exit(1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+
+		if err := adminServer.Stop(ctx); err != nil {
+			logg.Error("failed to stop admin server: " + err.Error())
+		}
 	}()
 
 	logg.Info("calendar scheduler is running...")
@@ -76,12 +101,27 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := scheduler.Start(ctx); err != nil {
+		if err := jobScheduler.Start(ctx); err != nil {
 			logg.Error("failed to start scheduler: " + err.Error())
 		}
 	}()
 
+	if cfg.Scheduler.Admin.Port != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := adminServer.Start(); err != nil {
+				logg.Error("failed to start admin server: " + err.Error())
+			}
+		}()
+	}
+
 	wg.Wait()
 	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*3)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logg.Error("failed to flush traces: " + err.Error())
+	}
+	shutdownCancel()
 	os.Exit(1) //nolint:gocritic
 }
@@ -9,13 +9,17 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/notifier"
 	queue "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/init"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/sender"
+	storagepkg "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 	storage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
 var (
@@ -40,7 +44,16 @@ func main() {
 		log.Fatal(err)
 	}
 
-	logg := logger.New(cfg.Logger.Level)
+	logg, err := logger.NewFromConfig(cfg.Logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	shutdownTracing, err := tracing.Init(cfg.Tracing, "calendar_sender")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	storage, err := storage.New(cfg)
 	if err != nil {
 		log.Fatal(err)
@@ -56,8 +69,14 @@ func main() {
 		log.Fatal(err)
 	}
 
+	templates, err := notifier.LoadTemplates(cfg.Notifier.Templates)
+	if err != nil {
+		log.Fatal(fmt.Errorf("notification templates: %w", err))
+	}
+
 	calendar := app.New(storage)
-	sender := sender.New(logg, calendar, queue)
+	notificationSender := sender.New(logg, calendar, queue, newNotifiers(cfg, logg, templates))
+	adminServer := sender.NewAdminServer(logg, notificationSender, cfg.Sender.Admin)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer cancel()
@@ -65,7 +84,17 @@ func main() {
 	go func() {
 		<-ctx.Done()
 		fmt.Println(ctx.Err())
-		os.Exit(1)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+
+		if err := adminServer.Stop(ctx); err != nil {
+			logg.Error("failed to stop admin server: " + err.Error())
+		}
+
+		if err := shutdownTracing(ctx); err != nil {
+			logg.Error("failed to flush traces: " + err.Error())
+		}
+		os.Exit(1) //nolint:gocritic
 	}()
 
 	logg.Info("calendar sender is running...")
@@ -74,12 +103,41 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := sender.Start(ctx); err != nil {
+		if err := notificationSender.Start(ctx); err != nil {
 			logg.Error("failed to start sender: " + err.Error())
 		}
 	}()
 
+	if cfg.Sender.Admin.Port != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := adminServer.Start(); err != nil {
+				logg.Error("failed to start admin server: " + err.Error())
+			}
+		}()
+	}
+
 	wg.Wait()
 	cancel()
 	os.Exit(1) //nolint:gocritic
 }
+
+// newNotifiers возвращает каналы доставки уведомлений, настроенные в конфигурации.
+func newNotifiers(cfg *config.Config, logg *logger.Logger,
+	templates *notifier.Templates,
+) map[storagepkg.NotificationChannel]sender.Notifier {
+	notifiers := map[storagepkg.NotificationChannel]sender.Notifier{
+		storagepkg.ChannelLog: notifier.NewLog(logg, templates),
+	}
+
+	if cfg.Notifier.SMTP.Host != "" {
+		notifiers[storagepkg.ChannelEmail] = notifier.NewSMTP(cfg.Notifier.SMTP, templates)
+	}
+
+	if cfg.Notifier.Webhook.Secret != "" {
+		notifiers[storagepkg.ChannelWebhook] = notifier.NewWebhook(cfg.Notifier.Webhook, templates)
+	}
+
+	return notifiers
+}
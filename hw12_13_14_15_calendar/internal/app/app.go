@@ -2,10 +2,35 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gofrs/uuid"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// busyCheckHorizon ограничивает период проверки занятости для бесконечно повторяющихся событий:
+// пересечения повторений позже этого срока от начала серии не проверяются.
+const busyCheckHorizon = 366 * 24 * time.Hour
+
+// userLockPrefix - префикс имени блокировки событий пользователя.
+const userLockPrefix = "events:"
+
+// maxSnooze ограничивает время, на которое можно отложить напоминание.
+const maxSnooze = 24 * time.Hour
+
+var (
+	// ErrDateBusy возвращается, если время события пересекается с другим событием пользователя.
+	ErrDateBusy = errors.New("date busy")
+	// ErrForbidden возвращается, если пользователь не является владельцем изменяемого события.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidLimit возвращается, если размер страницы выборки событий вне допустимых пределов.
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidSnooze возвращается, если время, на которое откладывается напоминание, вне допустимых пределов.
+	ErrInvalidSnooze = errors.New("invalid snooze duration")
 )
 
 type App struct {
@@ -19,26 +44,68 @@ type Storage interface {
 	ListEventsByMonth(ctx context.Context, userID uuid.UUID, startDate storage.EventDate) ([]storage.Event, error)
 	ListEventsByPeriod(ctx context.Context, userID uuid.UUID, startDate,
 		finishDate storage.EventDate) ([]storage.Event, error)
+	ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error)
+	SearchEvents(ctx context.Context, userID uuid.UUID, terms []string, limit int) ([]storage.Event, error)
 	UpdateEvent(ctx context.Context, event storage.Event) error
-	PatchEvent(ctx context.Context, id uuid.UUID, userID *uuid.UUID, title, description *string, startTime,
-		finishTime *storage.EventTime, notifyBefore *int, notificationSent *bool) error
-	DeleteEvent(ctx context.Context, ID uuid.UUID) error
-	SelectEventsToNotify(ctx context.Context) ([]storage.Event, error)
+	PatchEvent(ctx context.Context, id uuid.UUID, patch storage.EventPatch) error
+	GetEvent(ctx context.Context, id uuid.UUID) (storage.Event, error)
+	UpdateAttendeeStatus(ctx context.Context, eventID, userID uuid.UUID, status storage.AttendeeStatus) error
+	ListBusyEvents(ctx context.Context, userID uuid.UUID, startTime,
+		finishTime storage.EventTime) ([]storage.Event, error)
+	DeleteEvent(ctx context.Context, id uuid.UUID, version int64) error
+	EnqueueNotifications(ctx context.Context) (enqueued int, err error)
+	RelayNotifications(ctx context.Context, limit int, publish storage.PublishFunc) (published int, err error)
+	ScheduleNotification(ctx context.Context, message storage.OutboxMessage) error
+	IsNotificationDelivered(ctx context.Context, key string) (bool, error)
+	MarkNotificationDelivered(ctx context.Context, key string) (first bool, err error)
 	PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error)
+	GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error)
+	UpdateUserSettings(ctx context.Context, settings storage.UserSettings) error
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (storage.NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, settings storage.NotificationSettings) error
+	ListDigestSubscribers(ctx context.Context) ([]storage.DigestSubscriber, error)
+	MarkDigestSent(ctx context.Context, userID uuid.UUID, date storage.EventDate) error
+	TryLock(ctx context.Context, name string) (storage.Lock, error)
+	Lock(ctx context.Context, name string) (storage.Lock, error)
 	Connect() error
 	Close() error
+	Ping(ctx context.Context) error
 }
 
 func (a *App) CreateEvent(ctx context.Context, userID uuid.UUID, title, description string, startTime,
-	finishTime storage.EventTime, notifyBefore int,
+	finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence, timeZone string,
+	transparent bool, attendees []uuid.UUID,
 ) error {
+	if err := recurrence.Validate(); err != nil {
+		return err
+	}
+
+	reminders, err := storage.NewReminders(reminders, nil, false)
+	if err != nil {
+		return err
+	}
+
+	timeZone, err = a.resolveTimeZone(ctx, userID, timeZone)
+	if err != nil {
+		return err
+	}
+
 	id, err := uuid.NewV4()
 	if err != nil {
 		return err
 	}
 
-	event := buildEvent(id, userID, title, description, startTime, finishTime, notifyBefore, false)
-	return a.storage.CreateEvent(ctx, *event)
+	event := buildEvent(id, userID, title, description, startTime, finishTime, reminders, recurrence,
+		timeZone, transparent)
+	event.Attendees = storage.NewAttendees(userID, attendees, nil)
+	event.TraceParent = tracing.TraceParent(ctx)
+	return a.withUserLock(ctx, userID, func() error {
+		if err := a.checkDateBusy(ctx, *event); err != nil {
+			return err
+		}
+
+		return a.storage.CreateEvent(ctx, *event)
+	})
 }
 
 func (a *App) ListEventsByDate(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error) {
@@ -57,31 +124,380 @@ func (a *App) ListEventsByMonth(ctx context.Context, userID uuid.UUID,
 	return a.storage.ListEventsByMonth(ctx, userID, date)
 }
 
-func (a *App) UpdateEvent(ctx context.Context, id, userID uuid.UUID, title, description string, startTime,
-	finishTime storage.EventTime, notifyBefore int, notificationSent bool,
+// ListEvents возвращает страницу выборки событий. Нулевой размер страницы заменяется значением по умолчанию.
+func (a *App) ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error) {
+	if query.From != nil && query.To != nil && !time.Time(*query.From).Before(time.Time(*query.To)) {
+		return storage.EventPage{}, ErrInvalidPeriod
+	}
+
+	limit, err := pageLimit(query.Limit)
+	if err != nil {
+		return storage.EventPage{}, err
+	}
+	query.Limit = limit
+
+	order, err := storage.ParseSortOrder(string(query.Order))
+	if err != nil {
+		return storage.EventPage{}, err
+	}
+	query.Order = order
+
+	return a.storage.ListEvents(ctx, query)
+}
+
+// pageLimit проверяет количество событий на странице, нулевое значение заменяется значением по умолчанию.
+func pageLimit(limit int) (int, error) {
+	switch {
+	case limit == 0:
+		return storage.DefaultEventsLimit, nil
+	case limit < 0 || limit > storage.MaxEventsLimit:
+		return 0, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidLimit, storage.MaxEventsLimit)
+	default:
+		return limit, nil
+	}
+}
+
+// UpdateEvent заменяет событие id. Изменять событие может только его владелец userID. Если version
+// не равна 0, событие изменяется, только если его версия совпадает, иначе возвращается storage.ErrVersionMismatch.
+// Отметки об отправке напоминаний сохраняются, если время начала и правило повторения не изменились.
+func (a *App) UpdateEvent(ctx context.Context, id, userID uuid.UUID, version int64, title, description string,
+	startTime, finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence,
+	timeZone string, transparent bool, attendees []uuid.UUID,
 ) error {
-	event := buildEvent(id, userID, title, description, startTime, finishTime, notifyBefore, notificationSent)
-	return a.storage.UpdateEvent(ctx, *event)
+	if err := recurrence.Validate(); err != nil {
+		return err
+	}
+
+	timeZone, err := a.resolveTimeZone(ctx, userID, timeZone)
+	if err != nil {
+		return err
+	}
+
+	previous, err := a.ownedEvent(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = checkVersion(previous, version); err != nil {
+		return err
+	}
+
+	keepSent := time.Time(previous.StartTime).Equal(time.Time(startTime)) &&
+		previous.Recurrence.RRule == recurrence.RRule
+	reminders, err = storage.NewReminders(reminders, previous.Reminders, keepSent)
+	if err != nil {
+		return err
+	}
+
+	event := buildEvent(id, userID, title, description, startTime, finishTime, reminders, recurrence,
+		timeZone, transparent)
+	event.Attendees = storage.NewAttendees(userID, attendees, previous.Attendees)
+	event.Version = previous.Version
+	event.TraceParent = tracing.TraceParent(ctx)
+	return a.withUserLock(ctx, userID, func() error {
+		if err := a.checkDateBusy(ctx, *event); err != nil {
+			return err
+		}
+
+		return a.storage.UpdateEvent(ctx, *event)
+	})
+}
+
+// GetEvent возвращает событие id, если оно видно пользователю userID: он владелец события
+// или приглашен и не отклонил приглашение. О невидимом событии возвращается storage.ErrEventNotFound,
+// чтобы не раскрывать посторонним его существование.
+func (a *App) GetEvent(ctx context.Context, id, userID uuid.UUID) (storage.Event, error) {
+	event, err := a.storage.GetEvent(ctx, id)
+	if err != nil {
+		return storage.Event{}, err
+	}
+
+	if !event.IsVisibleTo(userID) {
+		return storage.Event{}, fmt.Errorf("%w: event %s is not visible to user %s", storage.ErrEventNotFound, id,
+			userID)
+	}
+
+	return event, nil
+}
+
+// PatchEvent изменяет указанные в patch поля события id. Изменять событие может только его владелец userID,
+// передать событие другому пользователю нельзя. Ответы уже приглашенных участников сохраняются.
+// Версия version проверяется так же, как в UpdateEvent.
+func (a *App) PatchEvent(ctx context.Context, id, userID uuid.UUID, version int64, patch storage.EventPatch) error {
+	if patch.UserID != nil && *patch.UserID != userID {
+		return ErrForbidden
+	}
+
+	if patch.Recurrence != nil {
+		if err := patch.Recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if patch.TimeZone != nil {
+		if _, err := storage.LoadLocation(*patch.TimeZone); err != nil {
+			return err
+		}
+	}
+
+	event, err := a.ownedEvent(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = checkVersion(event, version); err != nil {
+		return err
+	}
+
+	if patch.Attendees != nil {
+		attendees := storage.NewAttendees(event.UserID, attendeeIDs(*patch.Attendees), event.Attendees)
+		patch.Attendees = &attendees
+	}
+
+	if patch.Reminders != nil {
+		keepSent := patch.StartTime == nil && patch.Recurrence == nil
+		reminders, err := storage.NewReminders(*patch.Reminders, event.Reminders, keepSent)
+		if err != nil {
+			return err
+		}
+		patch.Reminders = &reminders
+	}
+
+	patch.Apply(&event)
+	event.TraceParent = tracing.TraceParent(ctx)
+	if !patch.AffectsBusyTime() {
+		return a.storage.UpdateEvent(ctx, event)
+	}
+
+	return a.withUserLock(ctx, event.UserID, func() error {
+		if err := a.checkDateBusy(ctx, event); err != nil {
+			return err
+		}
+
+		return a.storage.UpdateEvent(ctx, event)
+	})
 }
 
-func (a *App) PatchEvent(ctx context.Context, id uuid.UUID, userID *uuid.UUID, title, description *string, startTime,
-	finishTime *storage.EventTime, notifyBefore *int, notificationSent *bool,
+// RespondToInvitation сохраняет ответ пользователя userID на приглашение на событие eventID.
+func (a *App) RespondToInvitation(ctx context.Context, eventID, userID uuid.UUID,
+	status storage.AttendeeStatus,
 ) error {
-	return a.storage.PatchEvent(ctx, id, userID, title, description, startTime, finishTime, notifyBefore, notificationSent)
+	if _, err := storage.ParseAttendeeStatus(string(status)); err != nil {
+		return err
+	}
+
+	return a.storage.UpdateAttendeeStatus(ctx, eventID, userID, status)
 }
 
-func (a *App) DeleteEvent(ctx context.Context, id uuid.UUID) error {
-	return a.storage.DeleteEvent(ctx, id)
+// SnoozeReminder откладывает уже отправленное напоминание reminderID о событии eventID на duration:
+// пользователь userID получит его повторно через duration. Напоминание других получателей не меняется.
+func (a *App) SnoozeReminder(ctx context.Context, eventID, reminderID, userID uuid.UUID,
+	duration time.Duration,
+) error {
+	if duration <= 0 || duration > maxSnooze {
+		return fmt.Errorf("%w: must be between 1 minute and %s", ErrInvalidSnooze, maxSnooze)
+	}
+
+	event, err := a.GetEvent(ctx, eventID, userID)
+	if err != nil {
+		return err
+	}
+
+	reminder, ok := event.Reminder(reminderID)
+	if !ok {
+		return fmt.Errorf("%w: %s", storage.ErrReminderNotFound, reminderID)
+	}
+
+	if reminder.SentAt == nil {
+		return fmt.Errorf("%w: %s", storage.ErrReminderNotSent, reminderID)
+	}
+
+	message := storage.SnoozedMessage(event, reminder, userID, time.Now().Add(duration))
+	message.TraceParent = tracing.TraceParent(ctx)
+	return a.storage.ScheduleNotification(ctx, message)
 }
 
-func (a *App) SelectEventsToNotify(ctx context.Context) ([]storage.Event, error) {
-	return a.storage.SelectEventsToNotify(ctx)
+// DeleteEvent удаляет событие id. Удалить событие может только его владелец userID.
+// Версия version проверяется так же, как в UpdateEvent.
+func (a *App) DeleteEvent(ctx context.Context, id, userID uuid.UUID, version int64) error {
+	event, err := a.ownedEvent(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = checkVersion(event, version); err != nil {
+		return err
+	}
+
+	return a.storage.DeleteEvent(ctx, id, version)
 }
 
 func (a *App) PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error) {
 	return a.storage.PurgeEvents(ctx, purgeIntervalDays)
 }
 
+// Ping проверяет соединение с хранилищем.
+func (a *App) Ping(ctx context.Context) error {
+	return a.storage.Ping(ctx)
+}
+
+// TryLock захватывает именованную блокировку, общую для всех процессов, работающих с хранилищем.
+func (a *App) TryLock(ctx context.Context, name string) (storage.Lock, error) {
+	return a.storage.TryLock(ctx, name)
+}
+
+func (a *App) GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error) {
+	return a.storage.GetUserSettings(ctx, userID)
+}
+
+func (a *App) UpdateUserSettings(ctx context.Context, userID uuid.UUID, timeZone string) error {
+	if _, err := storage.LoadLocation(timeZone); err != nil {
+		return err
+	}
+
+	return a.storage.UpdateUserSettings(ctx, storage.UserSettings{
+		UserID:   userID,
+		TimeZone: timeZone,
+	})
+}
+
+// GetNotificationSettings возвращает канал доставки уведомлений пользователя.
+func (a *App) GetNotificationSettings(ctx context.Context, userID uuid.UUID) (storage.NotificationSettings, error) {
+	return a.storage.GetNotificationSettings(ctx, userID)
+}
+
+// UpdateNotificationSettings выбирает канал доставки уведомлений пользователя, адрес получателя,
+// язык уведомлений и время ежедневного дайджеста (пустое значение отключает дайджест).
+func (a *App) UpdateNotificationSettings(ctx context.Context, userID uuid.UUID,
+	channel storage.NotificationChannel, address, locale, digestTime string,
+) error {
+	settings := storage.NotificationSettings{
+		UserID:     userID,
+		Channel:    channel,
+		Address:    address,
+		Locale:     locale,
+		DigestTime: digestTime,
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	return a.storage.UpdateNotificationSettings(ctx, settings)
+}
+
+// Location возвращает часовой пояс timeZone, а если он не задан - часовой пояс пользователя по умолчанию.
+func (a *App) Location(ctx context.Context, userID uuid.UUID, timeZone string) (*time.Location, error) {
+	timeZone, err := a.resolveTimeZone(ctx, userID, timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.LoadLocation(timeZone)
+}
+
+// ownedEvent возвращает событие id, если его владелец - пользователь userID.
+func (a *App) ownedEvent(ctx context.Context, id, userID uuid.UUID) (storage.Event, error) {
+	event, err := a.storage.GetEvent(ctx, id)
+	if err != nil {
+		return storage.Event{}, err
+	}
+
+	if event.UserID != userID {
+		return storage.Event{}, fmt.Errorf("%w: user %s is not the owner of event %s", ErrForbidden, userID, id)
+	}
+
+	return event, nil
+}
+
+// checkVersion проверяет, что версия события равна version. Нулевая version не проверяется.
+func checkVersion(event storage.Event, version int64) error {
+	if version != 0 && event.Version != version {
+		return fmt.Errorf("%w: expected version %d, current version %d", storage.ErrVersionMismatch, version,
+			event.Version)
+	}
+
+	return nil
+}
+
+func (a *App) resolveTimeZone(ctx context.Context, userID uuid.UUID, timeZone string) (string, error) {
+	if timeZone != "" {
+		_, err := storage.LoadLocation(timeZone)
+		return timeZone, err
+	}
+
+	settings, err := a.storage.GetUserSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return settings.TimeZone, nil
+}
+
+// withUserLock выполняет fn под блокировкой событий пользователя userID, общей для всех процессов,
+// работающих с хранилищем. Проверка занятости и запись события выполняются под ней, чтобы
+// одновременные изменения не заняли одно и то же время дважды.
+func (a *App) withUserLock(ctx context.Context, userID uuid.UUID, fn func() error) (err error) {
+	lock, err := a.storage.Lock(ctx, userLockPrefix+userID.String())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, lock.Unlock(context.WithoutCancel(ctx)))
+	}()
+
+	return fn()
+}
+
+// checkDateBusy проверяет, что событие (все его повторения) не пересекается с другими событиями
+// пользователя. Прозрачные события не проверяются и сами не занимают время. Для бесконечно
+// повторяющегося события проверяются только повторения в пределах busyCheckHorizon от начала серии.
+func (a *App) checkDateBusy(ctx context.Context, event storage.Event) error {
+	if event.Transparent {
+		return nil
+	}
+
+	periodStart := time.Time(event.StartTime)
+	periodFinish := time.Time(event.FinishTime)
+	if event.Recurrence.IsRecurring() {
+		seriesFinish, finite, err := event.SeriesFinishTime()
+		if err != nil {
+			return err
+		}
+
+		periodFinish = seriesFinish
+		if !finite {
+			periodFinish = periodStart.Add(busyCheckHorizon)
+		}
+	}
+
+	busyEvents, err := a.storage.ListBusyEvents(ctx, event.UserID, storage.EventTime(periodStart),
+		storage.EventTime(periodFinish))
+	if err != nil {
+		return err
+	}
+
+	occurrences, err := event.Occurrences(periodStart, periodFinish)
+	if err != nil {
+		return err
+	}
+
+	for _, busyEvent := range busyEvents {
+		if busyEvent.ID == event.ID {
+			continue
+		}
+
+		for _, occurrence := range occurrences {
+			if occurrence.Overlaps(busyEvent) {
+				return fmt.Errorf("%w: overlaps with event %q at %s", ErrDateBusy, busyEvent.Title,
+					time.Time(busyEvent.StartTime).Format(time.RFC3339))
+			}
+		}
+	}
+
+	return nil
+}
+
 func New(storage Storage) *App {
 	return &App{
 		storage: storage,
@@ -89,17 +505,19 @@ func New(storage Storage) *App {
 }
 
 func buildEvent(id, userID uuid.UUID, title, description string, startTime, finishTime storage.EventTime,
-	notifyBefore int, notificationSent bool,
+	reminders []storage.Reminder, recurrence storage.Recurrence, timeZone string, transparent bool,
 ) *storage.Event {
 	event := &storage.Event{
-		ID:               id,
-		UserID:           userID,
-		Title:            title,
-		Description:      description,
-		StartTime:        startTime,
-		FinishTime:       finishTime,
-		NotifyBefore:     notifyBefore,
-		NotificationSent: notificationSent,
+		ID:          id,
+		UserID:      userID,
+		Title:       title,
+		Description: description,
+		StartTime:   startTime,
+		FinishTime:  finishTime,
+		Reminders:   reminders,
+		Recurrence:  recurrence,
+		TimeZone:    timeZone,
+		Transparent: transparent,
 	}
 	return event
 }
@@ -0,0 +1,838 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/memory"
+	sqlstorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/sql"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// testDSNEnv - переменная окружения с адресом PostgreSQL с примененными миграциями.
+// Если она не задана, тесты SQL-хранилища пропускаются.
+const testDSNEnv = "CALENDAR_TEST_DSN"
+
+var (
+	errPublish  = errors.New("publish failed")
+	errSchedule = errors.New("schedule failed")
+)
+
+// forEachStorage запускает тест для каждого хранилища.
+func forEachStorage(t *testing.T, test func(t *testing.T, calendar *App)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		test(t, New(memorystorage.New()))
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		dsn := os.Getenv(testDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", testDSNEnv)
+		}
+
+		sqlStorage := sqlstorage.New(&config.Config{}, dsn)
+		require.NoError(t, sqlStorage.Connect())
+		defer sqlStorage.Close()
+
+		test(t, New(sqlStorage))
+	})
+}
+
+func TestOwnership(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		owner := uuid.Must(uuid.NewV4())
+		stranger := uuid.Must(uuid.NewV4())
+		startTime := storage.EventTime(at(10, 15, 0))
+		finishTime := storage.EventTime(at(10, 16, 0))
+
+		err := calendar.CreateEvent(ctx, owner, "Meeting", "", startTime, finishTime, nil, storage.Recurrence{}, "UTC",
+			false, []uuid.UUID{stranger})
+		require.NoError(t, err)
+
+		events, err := calendar.ListEventsByDate(ctx, owner, storage.EventDate(at(10, 0, 0)))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		id := events[0].ID
+
+		t.Run("update by another user", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, id, stranger, 0, "Hijacked", "", startTime, finishTime, nil,
+				storage.Recurrence{}, "UTC", false, nil)
+			require.ErrorIs(t, err, ErrForbidden)
+		})
+
+		t.Run("get by outsider", func(t *testing.T) {
+			_, err := calendar.GetEvent(ctx, id, uuid.Must(uuid.NewV4()))
+			require.ErrorIs(t, err, storage.ErrEventNotFound)
+		})
+
+		t.Run("patch by another user", func(t *testing.T) {
+			title := "Hijacked"
+			err := calendar.PatchEvent(ctx, id, stranger, 0, storage.EventPatch{Title: &title})
+			require.ErrorIs(t, err, ErrForbidden)
+		})
+
+		t.Run("reassign owner by patch", func(t *testing.T) {
+			err := calendar.PatchEvent(ctx, id, owner, 0, storage.EventPatch{UserID: &stranger})
+			require.ErrorIs(t, err, ErrForbidden)
+		})
+
+		t.Run("delete by another user", func(t *testing.T) {
+			err := calendar.DeleteEvent(ctx, id, stranger, 0)
+			require.ErrorIs(t, err, ErrForbidden)
+
+			events, err := calendar.ListEventsByDate(ctx, owner, storage.EventDate(at(10, 0, 0)))
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			require.Equal(t, "Meeting", events[0].Title)
+			require.Equal(t, owner, events[0].UserID)
+		})
+
+		t.Run("update and patch by owner", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, id, owner, 0, "Meeting", "Agenda", startTime, finishTime, nil,
+				storage.Recurrence{}, "UTC", false, nil)
+			require.NoError(t, err)
+
+			title := "Team meeting"
+			err = calendar.PatchEvent(ctx, id, owner, 0, storage.EventPatch{Title: &title})
+			require.NoError(t, err)
+
+			event, err := calendar.storage.GetEvent(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, "Team meeting", event.Title)
+			require.Equal(t, "Agenda", event.Description)
+		})
+
+		t.Run("delete by owner", func(t *testing.T) {
+			require.NoError(t, calendar.DeleteEvent(ctx, id, owner, 0))
+
+			err := calendar.DeleteEvent(ctx, id, owner, 0)
+			require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+			title := "Deleted"
+			err = calendar.PatchEvent(ctx, id, owner, 0, storage.EventPatch{Title: &title})
+			require.ErrorIs(t, err, storage.ErrEventNotFound)
+		})
+	})
+}
+
+func TestVersions(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		userID := uuid.Must(uuid.NewV4())
+		startTime := storage.EventTime(at(11, 10, 0))
+		finishTime := storage.EventTime(at(11, 11, 0))
+
+		err := calendar.CreateEvent(ctx, userID, "Meeting", "", startTime, finishTime, nil, storage.Recurrence{}, "UTC",
+			false, nil)
+		require.NoError(t, err)
+
+		events, err := calendar.ListEventsByDate(ctx, userID, storage.EventDate(at(11, 0, 0)))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		id := events[0].ID
+		require.Equal(t, int64(1), events[0].Version)
+
+		t.Run("update with current version", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, id, userID, 1, "Meeting", "Agenda", startTime, finishTime, nil,
+				storage.Recurrence{}, "UTC", false, nil)
+			require.NoError(t, err)
+
+			title := "Team meeting"
+			require.NoError(t, calendar.PatchEvent(ctx, id, userID, 2, storage.EventPatch{Title: &title}))
+
+			event, err := calendar.GetEvent(ctx, id, userID)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), event.Version)
+			require.Equal(t, "Team meeting", event.Title)
+		})
+
+		t.Run("stale version", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, id, userID, 1, "Stale", "", startTime, finishTime, nil,
+				storage.Recurrence{}, "UTC", false, nil)
+			require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+			title := "Stale"
+			err = calendar.PatchEvent(ctx, id, userID, 2, storage.EventPatch{Title: &title})
+			require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+			err = calendar.DeleteEvent(ctx, id, userID, 2)
+			require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+			event, err := calendar.GetEvent(ctx, id, userID)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), event.Version)
+			require.Equal(t, "Team meeting", event.Title)
+		})
+
+		t.Run("delete with current version", func(t *testing.T) {
+			require.NoError(t, calendar.DeleteEvent(ctx, id, userID, 3))
+		})
+	})
+}
+
+func TestListEvents(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		userID := uuid.Must(uuid.NewV4())
+		titles := []string{"Standup", "Planning", "Retro", "Team standup", "Lunch"}
+		for day, title := range titles {
+			err := calendar.CreateEvent(ctx, userID, title, "", storage.EventTime(at(day+1, 10, 0)),
+				storage.EventTime(at(day+1, 11, 0)), nil, storage.Recurrence{}, "UTC", false, nil)
+			require.NoError(t, err)
+		}
+
+		err := calendar.CreateEvent(ctx, userID, "Weekly sync", "", storage.EventTime(at(1, 15, 0)),
+			storage.EventTime(at(1, 16, 0)), nil, storage.Recurrence{RRule: "FREQ=WEEKLY"}, "UTC", false, nil)
+		require.NoError(t, err)
+
+		listAll := func(t *testing.T, query storage.EventQuery) []string {
+			t.Helper()
+			query.UserID = userID
+			result := make([]string, 0)
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 10)
+				page, err := calendar.ListEvents(ctx, query)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(page.Events), query.Limit)
+				for _, event := range page.Events {
+					result = append(result, event.Title)
+				}
+
+				if page.Next == nil {
+					return result
+				}
+				query.After = page.Next
+			}
+		}
+
+		t.Run("pages in ascending order", func(t *testing.T) {
+			result := listAll(t, storage.EventQuery{Limit: 2})
+			require.Equal(t, []string{"Standup", "Weekly sync", "Planning", "Retro", "Team standup", "Lunch"}, result)
+		})
+
+		t.Run("pages in descending order", func(t *testing.T) {
+			result := listAll(t, storage.EventQuery{Limit: 4, Order: storage.SortDesc})
+			require.Equal(t, []string{"Lunch", "Team standup", "Retro", "Planning", "Weekly sync", "Standup"}, result)
+		})
+
+		t.Run("period", func(t *testing.T) {
+			from := storage.EventTime(at(3, 0, 0))
+			to := storage.EventTime(at(5, 0, 0))
+			result := listAll(t, storage.EventQuery{Limit: 10, From: &from, To: &to})
+			require.Equal(t, []string{"Weekly sync", "Retro", "Team standup"}, result)
+		})
+
+		t.Run("title and notification filters", func(t *testing.T) {
+			result := listAll(t, storage.EventQuery{Limit: 10, Title: "STANDUP"})
+			require.Equal(t, []string{"Standup", "Team standup"}, result)
+
+			notificationSent := true
+			result = listAll(t, storage.EventQuery{Limit: 10, NotificationSent: &notificationSent})
+			require.Empty(t, result)
+		})
+
+		t.Run("invalid query", func(t *testing.T) {
+			_, err := calendar.ListEvents(ctx, storage.EventQuery{UserID: userID, Limit: storage.MaxEventsLimit + 1})
+			require.ErrorIs(t, err, ErrInvalidLimit)
+
+			from := storage.EventTime(at(5, 0, 0))
+			to := storage.EventTime(at(3, 0, 0))
+			_, err = calendar.ListEvents(ctx, storage.EventQuery{UserID: userID, From: &from, To: &to})
+			require.ErrorIs(t, err, ErrInvalidPeriod)
+		})
+	})
+}
+
+func TestSearchEvents(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		owner := uuid.Must(uuid.NewV4())
+		guest := uuid.Must(uuid.NewV4())
+		createEvent := func(day int, title, description string, attendees ...uuid.UUID) {
+			err := calendar.CreateEvent(ctx, owner, title, description, storage.EventTime(at(day, 10, 0)),
+				storage.EventTime(at(day, 11, 0)), nil, storage.Recurrence{}, "UTC", false, attendees)
+			require.NoError(t, err)
+		}
+
+		createEvent(1, "Lunch", "Discuss the budget over lunch")
+		createEvent(2, "Budget review", "Quarterly budget", guest)
+		createEvent(3, "Standup", "Daily sync")
+
+		titles := func(events []storage.Event) []string {
+			result := make([]string, 0, len(events))
+			for _, event := range events {
+				result = append(result, event.Title)
+			}
+			return result
+		}
+
+		t.Run("ranked by relevance", func(t *testing.T) {
+			events, err := calendar.SearchEvents(ctx, owner, "BUDGET", 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Budget review", "Lunch"}, titles(events))
+
+			events, err = calendar.SearchEvents(ctx, owner, "budget lunch", 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Lunch"}, titles(events))
+
+			events, err = calendar.SearchEvents(ctx, owner, "budget", 1)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Budget review"}, titles(events))
+		})
+
+		t.Run("visible events only", func(t *testing.T) {
+			events, err := calendar.SearchEvents(ctx, guest, "budget", 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Budget review"}, titles(events))
+
+			events, err = calendar.SearchEvents(ctx, uuid.Must(uuid.NewV4()), "budget", 0)
+			require.NoError(t, err)
+			require.Empty(t, events)
+		})
+
+		t.Run("follows changes", func(t *testing.T) {
+			events, err := calendar.SearchEvents(ctx, owner, "standup", 0)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+
+			title := "Retro"
+			require.NoError(t, calendar.PatchEvent(ctx, events[0].ID, owner, 0, storage.EventPatch{Title: &title}))
+			events, err = calendar.SearchEvents(ctx, owner, "standup", 0)
+			require.NoError(t, err)
+			require.Empty(t, events)
+
+			events, err = calendar.SearchEvents(ctx, owner, "retro", 0)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+
+			require.NoError(t, calendar.DeleteEvent(ctx, events[0].ID, owner, 0))
+			events, err = calendar.SearchEvents(ctx, owner, "retro", 0)
+			require.NoError(t, err)
+			require.Empty(t, events)
+		})
+
+		t.Run("empty query", func(t *testing.T) {
+			_, err := calendar.SearchEvents(ctx, owner, " - ", 0)
+			require.ErrorIs(t, err, ErrEmptySearch)
+		})
+	})
+}
+
+func TestNotificationOutbox(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		owner := uuid.Must(uuid.NewV4())
+		guest := uuid.Must(uuid.NewV4())
+		startTime := time.Now().UTC().Truncate(time.Second).Add(10 * time.Minute)
+		err := calendar.CreateEvent(ctx, owner, "Outbox meeting", "", storage.EventTime(startTime),
+			storage.EventTime(startTime.Add(time.Hour)), []storage.Reminder{{Offset: 15}},
+			storage.Recurrence{}, "UTC", false, []uuid.UUID{guest})
+		require.NoError(t, err)
+
+		events, err := calendar.SearchEvents(ctx, owner, "outbox", 0)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		id := events[0].ID
+		require.NoError(t, calendar.RespondToInvitation(ctx, id, guest, storage.Accepted))
+
+		// relay публикует сообщения теста и возвращает их ключи, сообщения других тестов тоже публикуются.
+		relay := func(t *testing.T, fail bool) ([]string, error) {
+			t.Helper()
+			keys := make([]string, 0)
+			_, err := calendar.RelayNotifications(ctx, storage.MaxEventsLimit,
+				func(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+					if fail {
+						return 0, errPublish
+					}
+
+					for _, message := range messages {
+						if message.EventID == id {
+							keys = append(keys, message.Key)
+						}
+					}
+					return len(messages), nil
+				})
+			return keys, err
+		}
+
+		t.Run("enqueue once", func(t *testing.T) {
+			_, err := calendar.EnqueueNotifications(ctx)
+			require.NoError(t, err)
+
+			event, err := calendar.GetEvent(ctx, id, owner)
+			require.NoError(t, err)
+			require.True(t, event.IsReminded())
+
+			enqueued, err := calendar.EnqueueNotifications(ctx)
+			require.NoError(t, err)
+			require.Zero(t, enqueued)
+		})
+
+		t.Run("failed publishing keeps messages", func(t *testing.T) {
+			_, err := relay(t, true)
+			require.ErrorIs(t, err, errPublish)
+
+			keys, err := relay(t, false)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{
+				storage.NotificationKey(id, storage.EventTime(startTime), 15, owner),
+				storage.NotificationKey(id, storage.EventTime(startTime), 15, guest),
+			}, keys)
+
+			keys, err = relay(t, false)
+			require.NoError(t, err)
+			require.Empty(t, keys)
+		})
+
+		t.Run("deduplicate delivery", func(t *testing.T) {
+			key := storage.NotificationKey(id, storage.EventTime(startTime), 15, guest)
+			first, err := calendar.MarkNotificationDelivered(ctx, key)
+			require.NoError(t, err)
+			require.True(t, first)
+
+			first, err = calendar.MarkNotificationDelivered(ctx, key)
+			require.NoError(t, err)
+			require.False(t, first)
+		})
+	})
+}
+
+func TestReminders(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		owner := uuid.Must(uuid.NewV4())
+		guest := uuid.Must(uuid.NewV4())
+		stranger := uuid.Must(uuid.NewV4())
+		startTime := time.Now().UTC().Truncate(time.Second).Add(10 * time.Minute)
+		finishTime := startTime.Add(time.Hour)
+		reminders := []storage.Reminder{{Offset: 15}, {Offset: 24 * 60, Channel: storage.ChannelEmail}}
+		err := calendar.CreateEvent(ctx, owner, "Reminders meeting", "", storage.EventTime(startTime),
+			storage.EventTime(finishTime), reminders, storage.Recurrence{}, "UTC", false, []uuid.UUID{guest})
+		require.NoError(t, err)
+
+		events, err := calendar.SearchEvents(ctx, owner, "reminders", 0)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		event := events[0]
+		require.NoError(t, calendar.RespondToInvitation(ctx, event.ID, guest, storage.Accepted))
+		require.Len(t, event.Reminders, 2)
+		require.Equal(t, 24*60, event.Reminders[0].Offset)
+		require.Equal(t, storage.ChannelEmail, event.Reminders[0].Channel)
+		reminderID := event.Reminders[1].ID
+
+		t.Run("invalid reminders", func(t *testing.T) {
+			err := calendar.CreateEvent(ctx, owner, "Invalid", "", storage.EventTime(startTime),
+				storage.EventTime(finishTime), []storage.Reminder{{Offset: -5}}, storage.Recurrence{}, "UTC", true, nil)
+			require.ErrorIs(t, err, storage.ErrInvalidReminder)
+		})
+
+		t.Run("snooze reminder that was not sent", func(t *testing.T) {
+			err := calendar.SnoozeReminder(ctx, event.ID, reminderID, guest, 5*time.Minute)
+			require.ErrorIs(t, err, storage.ErrReminderNotSent)
+		})
+
+		t.Run("enqueue due reminders", func(t *testing.T) {
+			stale, err := calendar.GetEvent(ctx, event.ID, owner)
+			require.NoError(t, err)
+
+			_, err = calendar.EnqueueNotifications(ctx)
+			require.NoError(t, err)
+
+			event, err := calendar.GetEvent(ctx, event.ID, owner)
+			require.NoError(t, err)
+			require.Equal(t, stale.Version, event.Version)
+			for _, reminder := range event.Reminders {
+				require.NotNil(t, reminder.SentAt, reminder.Offset)
+			}
+
+			// Изменение, прочитанное до отправки напоминаний, не стирает отметки об отправке.
+			stale.Description = "Agenda"
+			require.NoError(t, calendar.storage.UpdateEvent(ctx, stale))
+			event, err = calendar.GetEvent(ctx, event.ID, owner)
+			require.NoError(t, err)
+			require.Equal(t, "Agenda", event.Description)
+			for _, reminder := range event.Reminders {
+				require.NotNil(t, reminder.SentAt, reminder.Offset)
+			}
+		})
+
+		t.Run("update keeps sent reminders", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, event.ID, owner, 0, "Reminders meeting", "Agenda",
+				storage.EventTime(startTime), storage.EventTime(finishTime), []storage.Reminder{{Offset: 15}},
+				storage.Recurrence{}, "UTC", false, []uuid.UUID{guest})
+			require.NoError(t, err)
+
+			updated, err := calendar.GetEvent(ctx, event.ID, owner)
+			require.NoError(t, err)
+			require.Len(t, updated.Reminders, 1)
+			require.Equal(t, reminderID, updated.Reminders[0].ID)
+			require.NotNil(t, updated.Reminders[0].SentAt)
+		})
+
+		t.Run("snooze", func(t *testing.T) {
+			err := calendar.SnoozeReminder(ctx, event.ID, reminderID, stranger, 5*time.Minute)
+			require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+			err = calendar.SnoozeReminder(ctx, event.ID, uuid.Must(uuid.NewV4()), guest, 5*time.Minute)
+			require.ErrorIs(t, err, storage.ErrReminderNotFound)
+
+			for _, duration := range []time.Duration{0, -time.Minute, maxSnooze + time.Minute} {
+				err = calendar.SnoozeReminder(ctx, event.ID, reminderID, guest, duration)
+				require.ErrorIs(t, err, ErrInvalidSnooze)
+			}
+
+			require.NoError(t, calendar.SnoozeReminder(ctx, event.ID, reminderID, guest, 5*time.Minute))
+
+			// Отложенное напоминание не публикуется раньше времени.
+			_, err = calendar.RelayNotifications(ctx, storage.MaxEventsLimit,
+				func(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+					for _, message := range messages {
+						if message.EventID == event.ID {
+							require.True(t, message.NotBefore.IsZero() || !message.NotBefore.After(time.Now()))
+						}
+					}
+					return len(messages), nil
+				})
+			require.NoError(t, err)
+		})
+
+		t.Run("changed start time resets sent reminders", func(t *testing.T) {
+			err := calendar.UpdateEvent(ctx, event.ID, owner, 0, "Reminders meeting", "Agenda",
+				storage.EventTime(startTime.Add(time.Hour)), storage.EventTime(finishTime.Add(time.Hour)),
+				[]storage.Reminder{{Offset: 15}}, storage.Recurrence{}, "UTC", false, []uuid.UUID{guest})
+			require.NoError(t, err)
+
+			updated, err := calendar.GetEvent(ctx, event.ID, owner)
+			require.NoError(t, err)
+			require.Equal(t, reminderID, updated.Reminders[0].ID)
+			require.Nil(t, updated.Reminders[0].SentAt)
+		})
+	})
+}
+
+func TestNotificationSettings(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		userID := uuid.Must(uuid.NewV4())
+
+		settings, err := calendar.GetNotificationSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.ChannelLog, settings.Channel)
+
+		require.NoError(t, calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelWebhook,
+			"https://example.com/hook", "ru", "08:30"))
+		settings, err = calendar.GetNotificationSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.NotificationSettings{
+			UserID:     userID,
+			Channel:    storage.ChannelWebhook,
+			Address:    "https://example.com/hook",
+			Locale:     "ru",
+			DigestTime: "08:30",
+		}, settings)
+
+		for _, invalid := range []storage.NotificationSettings{
+			{Channel: "sms", Address: "+10000000000"},
+			{Channel: storage.ChannelEmail, Address: "alice"},
+			{Channel: storage.ChannelWebhook, Address: "ftp://example.com"},
+			{Channel: storage.ChannelWebhook, Address: "/hook"},
+			{Channel: storage.ChannelWebhook, Address: "http://localhost:8082/jobs"},
+			{Channel: storage.ChannelWebhook, Address: "http://127.0.0.1/hook"},
+			{Channel: storage.ChannelWebhook, Address: "http://169.254.169.254/latest/meta-data"},
+			{Channel: storage.ChannelWebhook, Address: "http://10.0.0.5:5432"},
+			{Channel: storage.ChannelWebhook, Address: "http://[::1]/hook"},
+		} {
+			err = calendar.UpdateNotificationSettings(ctx, userID, invalid.Channel, invalid.Address, "", "")
+			require.ErrorIs(t, err, storage.ErrInvalidNotificationChannel, invalid)
+		}
+
+		err = calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelLog, "", "not a locale", "")
+		require.ErrorIs(t, err, storage.ErrInvalidLocale)
+
+		for _, digestTime := range []string{"8:30", "25:00", "08:30:00", "morning"} {
+			err = calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelLog, "", "", digestTime)
+			require.ErrorIs(t, err, storage.ErrInvalidDigestTime, digestTime)
+		}
+	})
+}
+
+func TestDigests(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		userID := uuid.Must(uuid.NewV4())
+		tokyo, err := storage.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		require.NoError(t, calendar.UpdateUserSettings(ctx, userID, "Asia/Tokyo"))
+		require.NoError(t, calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelLog, "", "", "08:00"))
+
+		for title, startTime := range map[string]time.Time{
+			"Lunch":    time.Date(2030, time.March, 10, 12, 0, 0, 0, tokyo),
+			"Standup":  time.Date(2030, time.March, 10, 9, 0, 0, 0, tokyo),
+			"Tomorrow": time.Date(2030, time.March, 11, 9, 0, 0, 0, tokyo),
+		} {
+			err = calendar.CreateEvent(ctx, userID, title, "", storage.EventTime(startTime),
+				storage.EventTime(startTime.Add(30*time.Minute)), nil, storage.Recurrence{}, "Asia/Tokyo", false, nil)
+			require.NoError(t, err)
+		}
+
+		// digests публикует сообщения outbox и возвращает дайджесты пользователя теста.
+		digests := func(t *testing.T) []storage.OutboxMessage {
+			t.Helper()
+			result := make([]storage.OutboxMessage, 0)
+			_, err := calendar.RelayNotifications(ctx, storage.MaxEventsLimit,
+				func(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+					for _, message := range messages {
+						if message.Type == storage.NotificationDigest && message.UserID == userID {
+							result = append(result, message)
+						}
+					}
+					return len(messages), nil
+				})
+			require.NoError(t, err)
+			return result
+		}
+
+		t.Run("not before digest time", func(t *testing.T) {
+			_, err := calendar.EnqueueDigests(ctx, time.Date(2030, time.March, 10, 7, 59, 0, 0, tokyo))
+			require.NoError(t, err)
+			require.Empty(t, digests(t))
+		})
+
+		t.Run("agenda of local day", func(t *testing.T) {
+			_, err := calendar.EnqueueDigests(ctx, time.Date(2030, time.March, 10, 8, 0, 0, 0, tokyo))
+			require.NoError(t, err)
+
+			messages := digests(t)
+			require.Len(t, messages, 1)
+			date := storage.EventDate(time.Date(2030, time.March, 10, 0, 0, 0, 0, tokyo))
+			require.Equal(t, storage.DigestKey(userID, date), messages[0].Key)
+			require.Len(t, messages[0].Agenda, 2)
+			require.Equal(t, "Standup", messages[0].Agenda[0].Title)
+			require.Equal(t, "Lunch", messages[0].Agenda[1].Title)
+		})
+
+		t.Run("once a day", func(t *testing.T) {
+			_, err := calendar.EnqueueDigests(ctx, time.Date(2030, time.March, 10, 20, 0, 0, 0, tokyo))
+			require.NoError(t, err)
+			require.Empty(t, digests(t))
+
+			_, err = calendar.EnqueueDigests(ctx, time.Date(2030, time.March, 11, 8, 30, 0, 0, tokyo))
+			require.NoError(t, err)
+			messages := digests(t)
+			require.Len(t, messages, 1)
+			require.Len(t, messages[0].Agenda, 1)
+			require.Equal(t, "Tomorrow", messages[0].Agenda[0].Title)
+		})
+
+		t.Run("no digest without events", func(t *testing.T) {
+			_, err := calendar.EnqueueDigests(ctx, time.Date(2030, time.March, 12, 8, 0, 0, 0, tokyo))
+			require.NoError(t, err)
+			require.Empty(t, digests(t))
+		})
+	})
+}
+
+// failingDigestStorage не ставит в outbox дайджест пользователя userID.
+type failingDigestStorage struct {
+	Storage
+	userID uuid.UUID
+}
+
+func (s failingDigestStorage) ScheduleNotification(ctx context.Context, message storage.OutboxMessage) error {
+	if message.UserID == s.userID {
+		return errSchedule
+	}
+
+	return s.Storage.ScheduleNotification(ctx, message)
+}
+
+func TestDigestErrors(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		failed, succeeded := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+		startTime := time.Date(2031, time.April, 7, 9, 0, 0, 0, time.UTC)
+		for _, userID := range []uuid.UUID{failed, succeeded} {
+			require.NoError(t, calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelLog, "", "", "08:00"))
+			err := calendar.CreateEvent(ctx, userID, "Standup", "", storage.EventTime(startTime),
+				storage.EventTime(startTime.Add(15*time.Minute)), nil, storage.Recurrence{}, "UTC", false, nil)
+			require.NoError(t, err)
+		}
+
+		// digestUsers публикует сообщения outbox и возвращает пользователей теста, получивших дайджест.
+		digestUsers := func(t *testing.T) []uuid.UUID {
+			t.Helper()
+			result := make([]uuid.UUID, 0)
+			_, err := calendar.RelayNotifications(ctx, storage.MaxEventsLimit,
+				func(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+					for _, message := range messages {
+						if message.UserID == failed || message.UserID == succeeded {
+							result = append(result, message.UserID)
+						}
+					}
+					return len(messages), nil
+				})
+			require.NoError(t, err)
+			return result
+		}
+
+		store := calendar.storage
+		calendar.storage = failingDigestStorage{Storage: store, userID: failed}
+		now := time.Date(2031, time.April, 7, 8, 0, 0, 0, time.UTC)
+		_, err := calendar.EnqueueDigests(ctx, now)
+		require.ErrorIs(t, err, errSchedule)
+		require.ErrorContains(t, err, failed.String())
+		require.NotContains(t, err.Error(), succeeded.String())
+		require.Equal(t, []uuid.UUID{succeeded}, digestUsers(t))
+
+		calendar.storage = store
+		_, err = calendar.EnqueueDigests(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{failed}, digestUsers(t))
+	})
+}
+
+func TestLock(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		ctx := context.Background()
+		name := "lock_" + uuid.Must(uuid.NewV4()).String()
+
+		lock, err := calendar.TryLock(ctx, name)
+		require.NoError(t, err)
+
+		_, err = calendar.TryLock(ctx, name)
+		require.ErrorIs(t, err, storage.ErrLocked)
+
+		other, err := calendar.TryLock(ctx, name+"_other")
+		require.NoError(t, err)
+		require.NoError(t, other.Unlock(ctx))
+
+		select {
+		case <-lock.Lost():
+			require.Fail(t, "lock is lost while held")
+		default:
+		}
+
+		require.NoError(t, lock.Unlock(ctx))
+		require.NoError(t, lock.Unlock(ctx))
+		<-lock.Lost()
+
+		lock, err = calendar.TryLock(ctx, name)
+		require.NoError(t, err)
+
+		t.Run("wait for lock", func(t *testing.T) {
+			waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			_, err := calendar.storage.Lock(waitCtx, name)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+
+			acquired := make(chan storage.Lock)
+			go func() {
+				lock, err := calendar.storage.Lock(ctx, name)
+				require.NoError(t, err)
+				acquired <- lock
+			}()
+
+			require.NoError(t, lock.Unlock(ctx))
+			require.NoError(t, (<-acquired).Unlock(ctx))
+		})
+	})
+}
+
+// slowBusyStorage задерживает запись после проверки занятости, чтобы одновременные изменения событий
+// пересекались.
+type slowBusyStorage struct {
+	Storage
+}
+
+func (s slowBusyStorage) ListBusyEvents(ctx context.Context, userID uuid.UUID, startTime,
+	finishTime storage.EventTime,
+) ([]storage.Event, error) {
+	events, err := s.Storage.ListBusyEvents(ctx, userID, startTime, finishTime)
+	time.Sleep(10 * time.Millisecond)
+	return events, err
+}
+
+func TestConcurrentCreate(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		calendar.storage = slowBusyStorage{Storage: calendar.storage}
+		ctx := context.Background()
+		owner := uuid.Must(uuid.NewV4())
+		startTime := storage.EventTime(at(20, 10, 0))
+		finishTime := storage.EventTime(at(20, 11, 0))
+
+		const creators = 10
+		errs := make(chan error, creators)
+		for i := 0; i < creators; i++ {
+			go func() {
+				errs <- calendar.CreateEvent(ctx, owner, "Meeting", "", startTime, finishTime, nil,
+					storage.Recurrence{}, "UTC", false, nil)
+			}()
+		}
+
+		created := 0
+		for i := 0; i < creators; i++ {
+			if err := <-errs; err == nil {
+				created++
+			} else {
+				require.ErrorIs(t, err, ErrDateBusy)
+			}
+		}
+		require.Equal(t, 1, created)
+	})
+}
+
+func TestTraceParent(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, calendar *App) {
+		created := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		patched := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		ctx := tracing.ContextWithTraceParent(context.Background(), created)
+		owner := uuid.Must(uuid.NewV4())
+		startTime := time.Now().UTC().Truncate(time.Second).Add(10 * time.Minute)
+		err := calendar.CreateEvent(ctx, owner, "Traced meeting", "", storage.EventTime(startTime),
+			storage.EventTime(startTime.Add(time.Hour)), []storage.Reminder{{Offset: 15}},
+			storage.Recurrence{}, "UTC", false, nil)
+		require.NoError(t, err)
+
+		events, err := calendar.SearchEvents(ctx, owner, "traced", 0)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		id := events[0].ID
+		require.Equal(t, created, events[0].TraceParent)
+
+		title := "Traced meeting again"
+		err = calendar.PatchEvent(tracing.ContextWithTraceParent(context.Background(), patched), id, owner, 0,
+			storage.EventPatch{Title: &title})
+		require.NoError(t, err)
+
+		event, err := calendar.GetEvent(context.Background(), id, owner)
+		require.NoError(t, err)
+		require.Equal(t, patched, event.TraceParent)
+
+		_, err = calendar.EnqueueNotifications(context.Background())
+		require.NoError(t, err)
+
+		traceParents := make([]string, 0)
+		_, err = calendar.RelayNotifications(context.Background(), storage.MaxEventsLimit,
+			func(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+				for _, message := range messages {
+					if message.EventID == id {
+						traceParents = append(traceParents, message.TraceParent)
+					}
+				}
+				return len(messages), nil
+			})
+		require.NoError(t, err)
+		require.Equal(t, []string{patched}, traceParents)
+	})
+}
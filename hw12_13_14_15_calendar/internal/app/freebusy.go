@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// maxFreeSlotsPeriod ограничивает период поиска свободного времени.
+const maxFreeSlotsPeriod = 92 * 24 * time.Hour
+
+var (
+	ErrInvalidPeriod       = errors.New("invalid period")
+	ErrInvalidDuration     = errors.New("invalid duration")
+	ErrInvalidWorkingHours = errors.New("invalid working hours")
+	ErrNoUsers             = errors.New("no users specified")
+)
+
+// Interval - промежуток времени [Start, Finish).
+type Interval struct {
+	Start  time.Time // Начало промежутка
+	Finish time.Time // Окончание промежутка, не включается
+}
+
+// WorkingHours - рабочее время, в пределах которого ищутся свободные промежутки.
+// Нулевое значение означает поиск круглосуточно во все дни недели.
+type WorkingHours struct {
+	Start    time.Duration  // Начало рабочего дня, смещение от полуночи
+	Finish   time.Duration  // Окончание рабочего дня, смещение от полуночи
+	Weekdays []time.Weekday // Рабочие дни недели, пусто - все дни
+	Location *time.Location // Часовой пояс рабочего времени, nil - UTC
+}
+
+// FindFreeSlots возвращает промежутки в периоде period длительностью не меньше duration,
+// в которые все пользователи userIDs свободны. Прозрачные события время не занимают.
+func (a *App) FindFreeSlots(ctx context.Context, userIDs []uuid.UUID, period Interval, duration time.Duration,
+	workingHours WorkingHours,
+) ([]Interval, error) {
+	if len(userIDs) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	if !period.Start.Before(period.Finish) || period.Finish.Sub(period.Start) > maxFreeSlotsPeriod {
+		return nil, ErrInvalidPeriod
+	}
+
+	if duration <= 0 {
+		return nil, ErrInvalidDuration
+	}
+
+	if err := workingHours.validate(); err != nil {
+		return nil, err
+	}
+
+	busy := make([]Interval, 0)
+	for _, userID := range userIDs {
+		events, err := a.storage.ListBusyEvents(ctx, userID, storage.EventTime(period.Start),
+			storage.EventTime(period.Finish))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			busy = append(busy, Interval{Start: time.Time(event.StartTime), Finish: time.Time(event.FinishTime)})
+		}
+	}
+
+	busy = mergeIntervals(busy)
+	slots := make([]Interval, 0)
+	for _, window := range workingHours.windows(period) {
+		for _, free := range subtractIntervals(window, busy) {
+			if free.Finish.Sub(free.Start) >= duration {
+				slots = append(slots, free)
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// ParseWorkingHours создает рабочее время по началу и окончанию рабочего дня в формате "15:04".
+// Пустые значения означают начало и конец суток.
+func ParseWorkingHours(workStart, workFinish string, weekdays []time.Weekday,
+	loc *time.Location,
+) (WorkingHours, error) {
+	workingHours := WorkingHours{Weekdays: weekdays, Location: loc}
+	for _, weekday := range weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return workingHours, fmt.Errorf("%w: weekday %d", ErrInvalidWorkingHours, weekday)
+		}
+	}
+
+	var err error
+	if workingHours.Start, err = parseTimeOfDay(workStart); err != nil {
+		return workingHours, err
+	}
+
+	if workingHours.Finish, err = parseTimeOfDay(workFinish); err != nil {
+		return workingHours, err
+	}
+
+	return workingHours, workingHours.validate()
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidWorkingHours, value)
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+func (wh WorkingHours) validate() error {
+	if wh.Start < 0 || wh.Finish > 24*time.Hour || wh.Start > wh.Finish {
+		return ErrInvalidWorkingHours
+	}
+
+	if wh.Start == wh.Finish && wh.Start != 0 {
+		return ErrInvalidWorkingHours
+	}
+
+	return nil
+}
+
+func (wh WorkingHours) allDay() bool {
+	return wh.Start == 0 && (wh.Finish == 0 || wh.Finish == 24*time.Hour)
+}
+
+func (wh WorkingHours) isWorkday(weekday time.Weekday) bool {
+	if len(wh.Weekdays) == 0 {
+		return true
+	}
+
+	for _, workday := range wh.Weekdays {
+		if workday == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// windows возвращает рабочие промежутки внутри периода. Границы рабочего дня задаются по местному
+// времени, поэтому при переходе на летнее время рабочий день не смещается.
+func (wh WorkingHours) windows(period Interval) []Interval {
+	if wh.allDay() && len(wh.Weekdays) == 0 {
+		return []Interval{period}
+	}
+
+	loc := wh.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	finish := wh.Finish
+	if finish == 0 {
+		finish = 24 * time.Hour
+	}
+
+	result := make([]Interval, 0)
+	start := period.Start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(period.Finish); day = day.AddDate(0, 0, 1) {
+		if !wh.isWorkday(day.Weekday()) {
+			continue
+		}
+
+		window := Interval{
+			Start:  time.Date(day.Year(), day.Month(), day.Day(), 0, 0, int(wh.Start.Seconds()), 0, loc),
+			Finish: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, int(finish.Seconds()), 0, loc),
+		}
+		if window.Start.Before(period.Start) {
+			window.Start = period.Start
+		}
+
+		if window.Finish.After(period.Finish) {
+			window.Finish = period.Finish
+		}
+
+		if window.Start.Before(window.Finish) {
+			result = append(result, window)
+		}
+	}
+
+	return result
+}
+
+// mergeIntervals объединяет пересекающиеся и смежные промежутки.
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	result := make([]Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		last := len(result) - 1
+		if last >= 0 && !interval.Start.After(result[last].Finish) {
+			if interval.Finish.After(result[last].Finish) {
+				result[last].Finish = interval.Finish
+			}
+			continue
+		}
+
+		result = append(result, interval)
+	}
+
+	return result
+}
+
+// subtractIntervals возвращает части промежутка window, не занятые отсортированными промежутками busy.
+func subtractIntervals(window Interval, busy []Interval) []Interval {
+	result := make([]Interval, 0)
+	start := window.Start
+	for _, interval := range busy {
+		if !interval.Finish.After(start) {
+			continue
+		}
+
+		if !interval.Start.Before(window.Finish) {
+			break
+		}
+
+		if interval.Start.After(start) {
+			result = append(result, Interval{Start: start, Finish: interval.Start})
+		}
+		start = interval.Finish
+	}
+
+	if start.Before(window.Finish) {
+		result = append(result, Interval{Start: start, Finish: window.Finish})
+	}
+
+	return result
+}
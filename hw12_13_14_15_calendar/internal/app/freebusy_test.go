@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/memory"
+)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestFindFreeSlots(t *testing.T) {
+	ctx := context.Background()
+	calendar := New(memorystorage.New())
+	alice := uuid.Must(uuid.NewV4())
+	bob := uuid.Must(uuid.NewV4())
+
+	createEvent := func(userID uuid.UUID, start, finish time.Time, rrule string, transparent bool) {
+		err := calendar.CreateEvent(ctx, userID, "Busy", "", storage.EventTime(start), storage.EventTime(finish), nil,
+			storage.Recurrence{RRule: rrule}, "UTC", transparent, nil)
+		require.NoError(t, err)
+	}
+
+	// 1 января 2024 года - понедельник.
+	createEvent(alice, at(1, 10, 0), at(1, 11, 0), "FREQ=DAILY", false)
+	createEvent(bob, at(1, 10, 30), at(1, 12, 0), "", false)
+	createEvent(bob, at(1, 14, 0), at(1, 17, 40), "", false)
+	createEvent(bob, at(2, 9, 0), at(2, 18, 0), "", true)
+
+	workingHours, err := ParseWorkingHours("09:00", "18:00",
+		[]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, time.UTC)
+	require.NoError(t, err)
+
+	t.Run("merges busy time of all users", func(t *testing.T) {
+		slots, err := calendar.FindFreeSlots(ctx, []uuid.UUID{alice, bob},
+			Interval{Start: at(1, 0, 0), Finish: at(3, 0, 0)}, 30*time.Minute, workingHours)
+		require.NoError(t, err)
+		require.Equal(t, []Interval{
+			{Start: at(1, 9, 0), Finish: at(1, 10, 0)},
+			{Start: at(1, 12, 0), Finish: at(1, 14, 0)},
+			{Start: at(2, 9, 0), Finish: at(2, 10, 0)},
+			{Start: at(2, 11, 0), Finish: at(2, 18, 0)},
+		}, slots)
+	})
+
+	t.Run("skips days off", func(t *testing.T) {
+		slots, err := calendar.FindFreeSlots(ctx, []uuid.UUID{alice},
+			Interval{Start: at(6, 0, 0), Finish: at(8, 0, 0)}, time.Hour, workingHours)
+		require.NoError(t, err)
+		require.Empty(t, slots)
+	})
+
+	t.Run("without working hours", func(t *testing.T) {
+		slots, err := calendar.FindFreeSlots(ctx, []uuid.UUID{alice},
+			Interval{Start: at(6, 0, 0), Finish: at(7, 0, 0)}, time.Hour, WorkingHours{})
+		require.NoError(t, err)
+		require.Equal(t, []Interval{
+			{Start: at(6, 0, 0), Finish: at(6, 10, 0)},
+			{Start: at(6, 11, 0), Finish: at(7, 0, 0)},
+		}, slots)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := calendar.FindFreeSlots(ctx, nil, Interval{Start: at(1, 0, 0), Finish: at(2, 0, 0)}, time.Hour,
+			workingHours)
+		require.ErrorIs(t, err, ErrNoUsers)
+
+		_, err = calendar.FindFreeSlots(ctx, []uuid.UUID{alice},
+			Interval{Start: at(2, 0, 0), Finish: at(1, 0, 0)}, time.Hour, workingHours)
+		require.ErrorIs(t, err, ErrInvalidPeriod)
+
+		_, err = calendar.FindFreeSlots(ctx, []uuid.UUID{alice},
+			Interval{Start: at(1, 0, 0), Finish: at(2, 0, 0)}, 0, workingHours)
+		require.ErrorIs(t, err, ErrInvalidDuration)
+
+		_, err = ParseWorkingHours("18:00", "09:00", nil, time.UTC)
+		require.ErrorIs(t, err, ErrInvalidWorkingHours)
+	})
+}
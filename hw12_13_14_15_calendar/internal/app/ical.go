@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"io"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/ical"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// ImportReport - результат импорта документа iCalendar.
+type ImportReport struct {
+	Imported int           // Количество созданных событий
+	Errors   []ImportError // Ошибки по отдельным компонентам VEVENT
+}
+
+type ImportError struct {
+	Item    int    // Порядковый номер компонента VEVENT в документе, начиная с 1
+	UID     string // UID компонента, если задан
+	Message string // Текст ошибки
+}
+
+// ExportEvents записывает события пользователя за период [startDate, finishDate) в формате iCalendar.
+// Повторяющиеся события, пересекающиеся с периодом, записываются целиком с правилом повторения,
+// чтобы экспортированный документ можно было импортировать без потерь.
+func (a *App) ExportEvents(ctx context.Context, userID uuid.UUID, startDate, finishDate storage.EventDate,
+	w io.Writer,
+) error {
+	from, to := storage.EventTime(startDate), storage.EventTime(finishDate)
+	query := storage.EventQuery{UserID: userID, From: &from, To: &to, Limit: storage.MaxEventsLimit}
+	events := make([]storage.Event, 0)
+	for {
+		page, err := a.storage.ListEvents(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, page.Events...)
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+
+	return ical.Encode(w, events)
+}
+
+// ImportEvents создает события пользователя из документа iCalendar. Ошибочные компоненты VEVENT
+// пропускаются и попадают в отчет, остальные события импортируются.
+func (a *App) ImportEvents(ctx context.Context, userID uuid.UUID, r io.Reader) (*ImportReport, error) {
+	items, err := ical.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		Errors: make([]ImportError, 0),
+	}
+	for i, item := range items {
+		err = item.Err
+		if err == nil {
+			event := item.Event
+			err = a.CreateEvent(ctx, userID, event.Title, event.Description, event.StartTime, event.FinishTime,
+				event.Reminders, event.Recurrence, event.TimeZone, event.Transparent, attendeeIDs(event.Attendees))
+		}
+
+		if err != nil {
+			report.Errors = append(report.Errors, ImportError{
+				Item:    i + 1,
+				UID:     item.UID,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		report.Imported++
+	}
+
+	return report, nil
+}
+
+func attendeeIDs(attendees []storage.Attendee) []uuid.UUID {
+	result := make([]uuid.UUID, 0, len(attendees))
+	for _, attendee := range attendees {
+		result = append(result, attendee.UserID)
+	}
+
+	return result
+}
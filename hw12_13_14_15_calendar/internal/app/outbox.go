@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// EnqueueNotifications ставит в outbox уведомления о наступающих событиях и в той же транзакции
+// отмечает события как уведомленные, поэтому повторный запуск не создает дубликатов.
+func (a *App) EnqueueNotifications(ctx context.Context) (enqueued int, err error) {
+	return a.storage.EnqueueNotifications(ctx)
+}
+
+// EnqueueDigests ставит в outbox ежедневные дайджесты пользователям, у которых к моменту now наступило
+// время дайджеста. Дайджест составляется из событий дня по часовому поясу пользователя и не ставится,
+// если событий нет. Дайджест за день ставится один раз: повторная постановка игнорируется по ключу.
+// Ошибка дайджеста одного пользователя не прерывает постановку остальных: ошибки с идентификаторами
+// пользователей объединяются и возвращаются после обхода всех подписчиков.
+func (a *App) EnqueueDigests(ctx context.Context, now time.Time) (enqueued int, err error) {
+	subscribers, err := a.storage.ListDigestSubscribers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		date, ok := subscriber.DueDigest(now)
+		if !ok {
+			continue
+		}
+
+		scheduled, err := a.enqueueDigest(ctx, subscriber, date)
+		if scheduled {
+			enqueued++
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest of user %s: %w", subscriber.UserID, err))
+		}
+	}
+
+	return enqueued, errors.Join(errs...)
+}
+
+// enqueueDigest ставит в outbox дайджест подписчика за дату date и отмечает его отправленным.
+// Возвращает false, если событий за дату нет и дайджест не поставлен.
+func (a *App) enqueueDigest(ctx context.Context, subscriber storage.DigestSubscriber,
+	date storage.EventDate,
+) (scheduled bool, err error) {
+	events, err := a.ListEventsByDate(ctx, subscriber.UserID, date)
+	if err != nil {
+		return false, err
+	}
+
+	if len(events) > 0 {
+		err = a.storage.ScheduleNotification(ctx, storage.NewDigestMessage(subscriber.UserID, date, events))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return len(events) > 0, a.storage.MarkDigestSent(ctx, subscriber.UserID, date)
+}
+
+// RelayNotifications публикует до limit сообщений outbox функцией publish. Сообщение остается
+// в outbox, пока его публикация не подтверждена, поэтому доставка выполняется хотя бы один раз.
+func (a *App) RelayNotifications(ctx context.Context, limit int, publish storage.PublishFunc) (int, error) {
+	return a.storage.RelayNotifications(ctx, limit, publish)
+}
+
+// IsNotificationDelivered возвращает true, если уведомление с ключом дедупликации key уже доставлено.
+func (a *App) IsNotificationDelivered(ctx context.Context, key string) (bool, error) {
+	return a.storage.IsNotificationDelivered(ctx, key)
+}
+
+// MarkNotificationDelivered отмечает уведомление с ключом дедупликации key доставленным.
+// Возвращает false для повторной доставки того же уведомления.
+func (a *App) MarkNotificationDelivered(ctx context.Context, key string) (first bool, err error) {
+	return a.storage.MarkNotificationDelivered(ctx, key)
+}
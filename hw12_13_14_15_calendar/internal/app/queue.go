@@ -6,7 +6,8 @@ import (
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 )
 
-type CallbackFunc func(context.Context, []byte)
+// CallbackFunc обрабатывает сообщение из очереди. Если она вернула ошибку, сообщение обрабатывается повторно.
+type CallbackFunc func(context.Context, []byte) error
 
 type QueueApp struct {
 	queue Queue
@@ -15,14 +16,20 @@ type QueueApp struct {
 type Queue interface {
 	Connect() error
 	Close() error
-	PublishNotifications(ctx context.Context, events []storage.Event) (eventsOut []storage.Event, err error)
+	Ping(ctx context.Context) error
+	PublishNotifications(ctx context.Context, messages []storage.OutboxMessage) (published int, err error)
 	ReadAndProcessNotifications(ctx context.Context, fn CallbackFunc) error
 }
 
 func (a *QueueApp) PublishNotifications(ctx context.Context,
-	events []storage.Event,
-) (eventsOut []storage.Event, err error) {
-	return a.queue.PublishNotifications(ctx, events)
+	messages []storage.OutboxMessage,
+) (published int, err error) {
+	return a.queue.PublishNotifications(ctx, messages)
+}
+
+// Ping проверяет соединение с очередью.
+func (a *QueueApp) Ping(ctx context.Context) error {
+	return a.queue.Ping(ctx)
 }
 
 func (a *QueueApp) ReadAndProcessNotifications(ctx context.Context, fn CallbackFunc) error {
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// ErrEmptySearch возвращается, если в поисковом запросе нет ни одного слова.
+var ErrEmptySearch = errors.New("empty search query")
+
+// SearchEvents возвращает не больше limit событий пользователя userID, в названии или описании которых
+// встречаются все слова из text. События упорядочены по убыванию релевантности, совпадения
+// в названии важнее совпадений в описании.
+func (a *App) SearchEvents(ctx context.Context, userID uuid.UUID, text string,
+	limit int,
+) ([]storage.Event, error) {
+	terms := storage.SearchTerms(text)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.storage.SearchEvents(ctx, userID, terms, limit)
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+// Схемы заголовка Authorization.
+const (
+	SchemeBearer = "Bearer"
+	SchemeAPIKey = "ApiKey"
+)
+
+var (
+	ErrNoCredentials      = errors.New("credentials are not provided")
+	ErrUnsupportedScheme  = errors.New("unsupported authorization scheme")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	errUnsupportedAlg     = errors.New("unsupported JWT algorithm")
+)
+
+// Authenticator проверяет учетные данные запроса и возвращает ID аутентифицированного пользователя.
+type Authenticator interface {
+	Authenticate(ctx context.Context, scheme, credentials string) (uuid.UUID, error)
+}
+
+type contextKey struct{}
+
+// WithUserID возвращает контекст с аутентифицированным пользователем.
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserIDFromContext возвращает аутентифицированного пользователя из контекста.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return userID, ok
+}
+
+// ParseAuthorization разбирает значение заголовка Authorization вида "<scheme> <credentials>".
+func ParseAuthorization(value string) (scheme, credentials string, err error) {
+	scheme, credentials, found := strings.Cut(strings.TrimSpace(value), " ")
+	credentials = strings.TrimSpace(credentials)
+	if !found || scheme == "" || credentials == "" {
+		return "", "", ErrNoCredentials
+	}
+
+	return scheme, credentials, nil
+}
+
+// Schemes выбирает Authenticator по схеме авторизации (без учета регистра).
+type Schemes map[string]Authenticator
+
+func (s Schemes) Authenticate(ctx context.Context, scheme, credentials string) (uuid.UUID, error) {
+	authenticator, found := s[strings.ToLower(scheme)]
+	if !found {
+		return uuid.Nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, scheme)
+	}
+
+	return authenticator.Authenticate(ctx, scheme, credentials)
+}
+
+// New создает Authenticator по конфигурации: JWT (схема Bearer) и статические ключи API (схема ApiKey).
+// Если аутентификация не настроена, возвращается nil.
+func New(cfg config.AuthConf) (Authenticator, error) {
+	schemes := Schemes{}
+	if cfg.JWT.Algorithm != "" {
+		jwtAuthenticator, err := NewJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		schemes[strings.ToLower(SchemeBearer)] = jwtAuthenticator
+	}
+
+	if len(cfg.APIKeys) > 0 {
+		apiKeyAuthenticator, err := NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		schemes[strings.ToLower(SchemeAPIKey)] = apiKeyAuthenticator
+	}
+
+	if len(schemes) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return schemes, nil
+}
+
+// JWTAuthenticator проверяет подписанные токены JWT. ID пользователя передается в claim "sub".
+type JWTAuthenticator struct {
+	key     interface{}
+	options []jwt.ParserOption
+}
+
+func NewJWTAuthenticator(cfg config.JWTConf) (*JWTAuthenticator, error) {
+	authenticator := &JWTAuthenticator{
+		options: []jwt.ParserOption{jwt.WithValidMethods([]string{cfg.Algorithm}), jwt.WithExpirationRequired()},
+	}
+	if cfg.Issuer != "" {
+		authenticator.options = append(authenticator.options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		authenticator.options = append(authenticator.options, jwt.WithAudience(cfg.Audience))
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("%w: secret is required for %s", errUnsupportedAlg, cfg.Algorithm)
+		}
+		authenticator.key = []byte(cfg.Secret)
+	case jwt.SigningMethodRS256.Alg():
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+
+		authenticator.key, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedAlg, cfg.Algorithm)
+	}
+
+	return authenticator, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(_ context.Context, _, credentials string) (uuid.UUID, error) {
+	token, err := jwt.Parse(credentials, func(*jwt.Token) (interface{}, error) {
+		return a.key, nil
+	}, a.options...)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	subject, err := token.Claims.GetSubject()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	userID, err := uuid.FromString(subject)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: subject is not a user id", ErrInvalidCredentials)
+	}
+
+	return userID, nil
+}
+
+// APIKeyAuthenticator проверяет статические ключи API из конфигурации.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	key    []byte
+	userID uuid.UUID
+}
+
+func NewAPIKeyAuthenticator(keys []config.APIKeyConf) (*APIKeyAuthenticator, error) {
+	authenticator := &APIKeyAuthenticator{keys: make([]apiKey, 0, len(keys))}
+	for _, key := range keys {
+		userID, err := uuid.FromString(key.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id of API key: %w", err)
+		}
+
+		if key.Key == "" {
+			return nil, fmt.Errorf("empty API key for user %s", userID)
+		}
+
+		authenticator.keys = append(authenticator.keys, apiKey{key: []byte(key.Key), userID: userID})
+	}
+
+	return authenticator, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(_ context.Context, _, credentials string) (uuid.UUID, error) {
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(key.key, []byte(credentials)) == 1 {
+			return key.userID, nil
+		}
+	}
+
+	return uuid.Nil, ErrInvalidCredentials
+}
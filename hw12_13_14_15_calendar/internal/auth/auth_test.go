@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+const userID = "14e4a342-2ad9-4e1f-bd83-eff99332a49f"
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestParseAuthorization(t *testing.T) {
+	scheme, credentials, err := ParseAuthorization("Bearer  abc.def ")
+	require.NoError(t, err)
+	require.Equal(t, "Bearer", scheme)
+	require.Equal(t, "abc.def", credentials)
+
+	_, _, err = ParseAuthorization("Bearer")
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestNew(t *testing.T) {
+	authenticator, err := New(config.AuthConf{})
+	require.NoError(t, err)
+	require.Nil(t, authenticator)
+
+	_, err = New(config.AuthConf{JWT: config.JWTConf{Algorithm: "none"}})
+	require.Error(t, err)
+
+	_, err = New(config.AuthConf{APIKeys: []config.APIKeyConf{{Key: "key", UserID: "admin"}}})
+	require.Error(t, err)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	authenticator, err := New(config.AuthConf{
+		JWT: config.JWTConf{Algorithm: "HS256", Secret: "secret", Issuer: "calendar"},
+	})
+	require.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		token := signHS256(t, "secret", jwt.MapClaims{
+			"sub": userID, "iss": "calendar", "exp": time.Now().Add(time.Hour).Unix(),
+		})
+		id, err := authenticator.Authenticate(ctx, "bearer", token)
+		require.NoError(t, err)
+		require.Equal(t, uuid.FromStringOrNil(userID), id)
+	})
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"expired token":    {"sub": userID, "iss": "calendar", "exp": time.Now().Add(-time.Hour).Unix()},
+		"no expiration":    {"sub": userID, "iss": "calendar"},
+		"wrong issuer":     {"sub": userID, "iss": "other", "exp": time.Now().Add(time.Hour).Unix()},
+		"subject not uuid": {"sub": "admin", "iss": "calendar", "exp": time.Now().Add(time.Hour).Unix()},
+	} {
+		claims := claims
+		t.Run(name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(ctx, "Bearer", signHS256(t, "secret", claims))
+			require.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		token := signHS256(t, "other", jwt.MapClaims{"sub": userID, "exp": time.Now().Add(time.Hour).Unix()})
+		_, err := authenticator.Authenticate(ctx, "Bearer", token)
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := authenticator.Authenticate(ctx, "Basic", "dXNlcjpwYXNz")
+		require.ErrorIs(t, err, ErrUnsupportedScheme)
+	})
+}
+
+func TestJWTAuthenticatorRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKeyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	err = os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0o600)
+	require.NoError(t, err)
+
+	authenticator, err := New(config.AuthConf{JWT: config.JWTConf{Algorithm: "RS256", PublicKeyFile: publicKeyFile}})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": userID, "exp": time.Now().Add(time.Hour).Unix()}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	require.NoError(t, err)
+	id, err := authenticator.Authenticate(context.Background(), "Bearer", token)
+	require.NoError(t, err)
+	require.Equal(t, uuid.FromStringOrNil(userID), id)
+
+	_, err = authenticator.Authenticate(context.Background(), "Bearer", signHS256(t, "secret", claims))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	authenticator, err := New(config.AuthConf{APIKeys: []config.APIKeyConf{{Key: "s3cr3t", UserID: userID}}})
+	require.NoError(t, err)
+
+	id, err := authenticator.Authenticate(ctx, "ApiKey", "s3cr3t")
+	require.NoError(t, err)
+	require.Equal(t, uuid.FromStringOrNil(userID), id)
+
+	_, err = authenticator.Authenticate(ctx, "ApiKey", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = authenticator.Authenticate(ctx, "Bearer", "s3cr3t")
+	require.ErrorIs(t, err, ErrUnsupportedScheme)
+}
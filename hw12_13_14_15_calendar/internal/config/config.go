@@ -7,6 +7,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -18,10 +19,16 @@ type Config struct {
 	Server     ServerConf
 	GRPCServer GRPCServerConf
 	Scheduler  SchedulerConf
+	Sender     SenderConf
+	Auth       AuthConf
+	Notifier   NotifierConf
+	Tracing    TracingConf
 }
 
 type LoggerConf struct {
-	Level string
+	Level  string
+	Format string // "text" (по умолчанию), "json"
+	Output string // "stderr" (по умолчанию), "stdout" или путь к файлу
 }
 
 type DBConf struct {
@@ -30,8 +37,10 @@ type DBConf struct {
 }
 
 type QueueConf struct {
-	Type string // "rmq" only
-	RMQ  RMQConf
+	Type   string // "rmq", "sql"; "memory" - только в тестах
+	RMQ    RMQConf
+	SQL    SQLQueueConf    // Очередь в таблице PostgreSQL из настроек DB.SQL
+	Memory MemoryQueueConf // Очередь в памяти процесса, только для тестов
 }
 
 type ServerConf struct {
@@ -59,10 +68,131 @@ type RMQConf struct {
 	Password string
 	Host     string
 	Port     string
+	// MaxRetries - количество повторов обработки уведомления: 0 - 3 повтора, отрицательное значение - без повторов.
+	MaxRetries int `yaml:"maxRetries"`
+	// RetryDelay - задержка перед первым повтором, удваивается с каждым следующим, по умолчанию 10s.
+	RetryDelay time.Duration `yaml:"retryDelay"`
+	// DeadLetterExchange - exchange для уведомлений, исчерпавших повторы, опционально.
+	// Сообщения из него попадают в очередь "<Name>.dead".
+	DeadLetterExchange string `yaml:"deadLetterExchange"`
+}
+
+// SQLQueueConf - очередь уведомлений в таблице notification_queue. Получатели ждут уведомления
+// PostgreSQL (LISTEN/NOTIFY) и проверяют таблицу каждые PollInterval (по умолчанию 5s), чтобы забрать
+// сообщения, отложенные для повтора. Сообщения, исчерпавшие повторы, остаются в таблице с отметкой dead_at.
+type SQLQueueConf struct {
+	Name string
+	// MaxRetries - количество повторов обработки уведомления: 0 - 3 повтора, отрицательное значение - без повторов.
+	MaxRetries int `yaml:"maxRetries"`
+	// RetryDelay - задержка перед первым повтором, удваивается с каждым следующим, по умолчанию 10s.
+	RetryDelay   time.Duration `yaml:"retryDelay"`
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// MemoryQueueConf - очередь уведомлений в памяти процесса. Сообщения доступны только получателям
+// в том же процессе и теряются при его завершении. Сообщения, исчерпавшие повторы, отбрасываются.
+type MemoryQueueConf struct {
+	Capacity int // Количество сообщений, которые очередь вмещает без блокировки отправителя, по умолчанию 1000
+	// MaxRetries - количество повторов обработки уведомления: 0 - 3 повтора, отрицательное значение - без повторов.
+	MaxRetries int `yaml:"maxRetries"`
+	// RetryDelay - задержка перед первым повтором, удваивается с каждым следующим, по умолчанию 10s.
+	RetryDelay time.Duration `yaml:"retryDelay"`
+}
+
+// AuthConf - настройки аутентификации HTTP и GRPC серверов. Если не задан ни JWT, ни ключи API,
+// аутентификация отключена и пользователь берется из заголовка X-User-Id (HTTP) или тела запроса (GRPC).
+type AuthConf struct {
+	JWT     JWTConf
+	APIKeys []APIKeyConf `yaml:"apiKeys"`
+}
+
+type JWTConf struct {
+	Algorithm     string // "HS256", "RS256"
+	Secret        string // Секрет для HS256
+	PublicKeyFile string `yaml:"publicKeyFile"` // Открытый ключ в формате PEM для RS256
+	Issuer        string // Ожидаемый claim "iss", опционально
+	Audience      string // Ожидаемый claim "aud", опционально
+}
+
+type APIKeyConf struct {
+	Key    string
+	UserID string `yaml:"userId"`
+}
+
+// NotifierConf - настройки каналов доставки уведомлений. Канал "email" доступен, если задан SMTP.Host,
+// канал "webhook" - если задан Webhook.Secret. Канал "log" доступен всегда.
+type NotifierConf struct {
+	SMTP      SMTPConf
+	Webhook   WebhookConf
+	Templates TemplatesConf
+}
+
+// TemplatesConf - шаблоны уведомлений. Каталог Dir содержит шаблоны каналов (email/, webhook/, log/)
+// и каталоги сообщений locales/<locale>.yaml.
+type TemplatesConf struct {
+	Dir           string
+	DefaultLocale string `yaml:"defaultLocale"` // Язык уведомлений по умолчанию, по умолчанию "en"
+}
+
+type SMTPConf struct {
+	Host     string
+	Port     string
+	User     string // Пользователь для аутентификации PLAIN, опционально
+	Password string
+	From     string // Адрес отправителя
+}
+
+type WebhookConf struct {
+	Secret  string        // Ключ подписи HMAC-SHA256 тела запроса
+	Timeout time.Duration // Таймаут запроса, по умолчанию 10s
 }
 
 type SchedulerConf struct {
 	PurgeIntervalDays int `yaml:"purgeIntervalDays"`
+	Jobs              JobsConf
+	Leader            LeaderConf
+	Admin             ServerConf // Адрес HTTP-сервера администрирования, если не задан порт - сервер не запускается
+}
+
+type SenderConf struct {
+	Admin ServerConf // Адрес HTTP-сервера администрирования, если не задан порт - сервер не запускается
+}
+
+// LeaderConf - выбор ведущего экземпляра планировщика. Задания выполняет только экземпляр, захвативший
+// блокировку Lock в хранилище (по умолчанию "calendar_scheduler"). Остальные экземпляры пытаются захватить
+// ее каждые RetryInterval (по умолчанию 5s) и становятся ведущими, если прежний ведущий завершился.
+type LeaderConf struct {
+	Lock          string
+	RetryInterval time.Duration `yaml:"retryInterval"`
+}
+
+// TracingConf - экспорт трассировок OpenTelemetry. Exporter "otlp" отправляет трассировки по OTLP/HTTP
+// на Endpoint (по умолчанию localhost:4318), "stdout" - записывает их в формате JSON в файл File
+// (по умолчанию в стандартный вывод). Если Exporter не задан, трассировки не записываются,
+// но контекст трассировки передается между сервисами.
+type TracingConf struct {
+	Exporter    string // "", "otlp", "stdout"
+	Endpoint    string
+	Insecure    bool // Отправлять трассировки по HTTP без TLS
+	File        string
+	SampleRatio float64 `yaml:"sampleRatio"` // Доля записываемых трассировок от 0 до 1, 0 - все трассировки
+}
+
+// JobsConf - расписание заданий планировщика.
+type JobsConf struct {
+	Purge  JobConf // Удаление старых событий
+	Notify JobConf // Постановка напоминаний в outbox и публикация outbox в очередь
+	Digest JobConf // Постановка ежедневных дайджестов в outbox и публикация outbox в очередь
+}
+
+// JobConf - расписание задания. Schedule - выражение cron из пяти полей ("*/5 * * * *") или дескриптор
+// ("@hourly", "@every 30s"), по умолчанию задание выполняется каждую минуту. Запуск задания откладывается
+// на случайное время до Jitter, чтобы экземпляры планировщика не обращались к базе одновременно.
+// Задание, не завершившееся за Timeout (по умолчанию 1m), прерывается отменой контекста.
+type JobConf struct {
+	Schedule string
+	Jitter   time.Duration
+	Timeout  time.Duration
 }
 
 func NewConfig() *Config {
@@ -0,0 +1,106 @@
+// Package health содержит обработчики проверок живости (/healthz) и готовности (/readyz) сервисов календаря.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Пути проверок живости и готовности.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Состояния сервиса в ответе.
+const (
+	StatusOK       = "ok"
+	StatusNotReady = "not ready"
+)
+
+// checkPassed - результат успешной проверки в ответе.
+const checkPassed = "ok"
+
+// readinessTimeout ограничивает время выполнения всех проверок готовности.
+const readinessTimeout = 3 * time.Second
+
+// CheckFunc проверяет зависимость сервиса и возвращает ошибку, если она недоступна.
+type CheckFunc func(ctx context.Context) error
+
+// Check - именованная проверка готовности.
+type Check struct {
+	Name  string
+	Check CheckFunc
+}
+
+// Report - ответ проверки готовности: общее состояние и результат каждой проверки ("ok" или текст ошибки).
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Run выполняет проверки одновременно и возвращает отчет. Сервис готов, если прошли все проверки.
+func Run(ctx context.Context, checks ...Check) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]string, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := checkPassed
+			if err := check.Check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result != checkPassed {
+				report.Status = StatusNotReady
+			}
+		}(check)
+	}
+
+	wg.Wait()
+	return report
+}
+
+// LivenessHandler отвечает 200, пока процесс способен обрабатывать запросы.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler выполняет проверки checks с таймаутом и отвечает 200, если все они прошли,
+// и 503, если хотя бы одна не прошла. В теле ответа - Report.
+func ReadinessHandler(checks ...Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		report := Run(ctx, checks...)
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeReport(w, status, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	res, err := json.Marshal(report)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(res)
+}
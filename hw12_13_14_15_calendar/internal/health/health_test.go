@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+func passed(_ context.Context) error {
+	return nil
+}
+
+func failed(_ context.Context) error {
+	return errUnavailable
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no checks", func(t *testing.T) {
+		require.Equal(t, Report{Status: StatusOK, Checks: map[string]string{}}, Run(ctx))
+	})
+
+	t.Run("all checks passed", func(t *testing.T) {
+		report := Run(ctx, Check{Name: "storage", Check: passed}, Check{Name: "queue", Check: passed})
+		require.Equal(t, StatusOK, report.Status)
+		require.Equal(t, map[string]string{"storage": "ok", "queue": "ok"}, report.Checks)
+	})
+
+	t.Run("check failed", func(t *testing.T) {
+		report := Run(ctx, Check{Name: "storage", Check: passed}, Check{Name: "queue", Check: failed})
+		require.Equal(t, StatusNotReady, report.Status)
+		require.Equal(t, map[string]string{"storage": "ok", "queue": "unavailable"}, report.Checks)
+	})
+
+	t.Run("checks run concurrently", func(t *testing.T) {
+		slow := func(ctx context.Context) error {
+			select {
+			case <-time.After(100 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		start := time.Now()
+		report := Run(ctx, Check{Name: "storage", Check: slow}, Check{Name: "queue", Check: slow})
+		require.Equal(t, StatusOK, report.Status)
+		require.Less(t, time.Since(start), 190*time.Millisecond)
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	serve := func(t *testing.T, handler http.Handler) *httptest.ResponseRecorder {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		return recorder
+	}
+
+	t.Run("liveness", func(t *testing.T) {
+		recorder := serve(t, LivenessHandler())
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		recorder := serve(t, ReadinessHandler(Check{Name: "storage", Check: passed}))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"status":"ok","checks":{"storage":"ok"}}`, recorder.Body.String())
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		recorder := serve(t, ReadinessHandler(Check{Name: "storage", Check: failed}))
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		require.JSONEq(t, `{"status":"not ready","checks":{"storage":"unavailable"}}`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,526 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+	prodID      = "-//otus-go-pro//calendar//EN"
+	maxLineLen  = 75
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+	dateLayout  = "20060102"
+
+	// attendeePrefix - схема адреса участника: пользователи календаря не имеют e-mail и задаются своим ID.
+	attendeePrefix = "urn:uuid:"
+)
+
+var (
+	ErrNoCalendar      = errors.New("VCALENDAR component not found")
+	ErrMalformedLine   = errors.New("malformed content line")
+	ErrUnbalancedBlock = errors.New("unbalanced BEGIN/END")
+	errNoDTStart       = errors.New("DTSTART is required")
+	errNoSummary       = errors.New("SUMMARY is required")
+	errInvalidTime     = errors.New("invalid date-time value")
+	errInvalidDuration = errors.New("invalid duration value")
+)
+
+var durationRegexp = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Item - результат разбора одного компонента VEVENT.
+type Item struct {
+	UID   string        // Значение свойства UID, если задано
+	Event storage.Event // Событие, заполнено если Err == nil
+	Err   error         // Ошибка разбора компонента
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type component struct {
+	name       string
+	properties []property
+	children   []*component
+	err        error // Первая ошибка разбора строк компонента
+}
+
+// Encode записывает события в виде документа VCALENDAR. Повторяющееся событие записывается
+// один раз с правилом повторения RRULE и исключениями EXDATE.
+func Encode(w io.Writer, events []storage.Event) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+	writeTime := func(name string, event storage.Event, t storage.EventTime) {
+		loc := event.Location()
+		if loc == time.UTC {
+			write(name, time.Time(t).UTC().Format(utcLayout))
+			return
+		}
+
+		write(name+";TZID="+loc.String(), time.Time(t).In(loc).Format(localLayout))
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", prodID)
+	write("CALSCALE", "GREGORIAN")
+	for _, event := range events {
+		write("BEGIN", "VEVENT")
+		write("UID", event.ID.String())
+		write("DTSTAMP", time.Now().UTC().Format(utcLayout))
+		writeTime("DTSTART", event, event.StartTime)
+		writeTime("DTEND", event, event.FinishTime)
+		if event.Recurrence.IsRecurring() {
+			write("RRULE", event.Recurrence.RRule)
+			if len(event.Recurrence.ExDates) > 0 {
+				write("EXDATE", storage.FormatExDates(event.Recurrence.ExDates))
+			}
+		}
+		write("SUMMARY", escape(event.Title))
+		if event.Description != "" {
+			write("DESCRIPTION", escape(event.Description))
+		}
+		if event.Transparent {
+			write("TRANSP", "TRANSPARENT")
+		}
+		for _, attendee := range event.Attendees {
+			write("ATTENDEE;PARTSTAT="+strings.ToUpper(string(attendee.Status)), attendeePrefix+attendee.UserID.String())
+		}
+
+		for _, reminder := range event.Reminders {
+			write("BEGIN", "VALARM")
+			write("ACTION", "DISPLAY")
+			write("DESCRIPTION", escape(event.Title))
+			write("TRIGGER", fmt.Sprintf("-PT%dM", reminder.Offset))
+			write("END", "VALARM")
+		}
+		write("END", "VEVENT")
+	}
+	write("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeLine записывает строку содержимого, перенося ее согласно RFC 5545: каждая строка, включая
+// пробел в начале строки продолжения, не длиннее 75 октетов.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLen
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = maxLineLen - 1
+	}
+
+	w.WriteString(line + "\r\n")
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
+
+// Decode разбирает документ VCALENDAR и возвращает по элементу на каждый компонент VEVENT.
+// Ошибка в отдельном VEVENT, в том числе некорректная строка или несбалансированный BEGIN/END
+// внутри него, не прерывает разбор, а возвращается в соответствующем элементе.
+func Decode(r io.Reader) ([]Item, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &component{}
+	stack := []*component{root}
+	for _, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			setError(stack, err)
+			continue
+		}
+
+		current := stack[len(stack)-1]
+		switch prop.name {
+		case "BEGIN":
+			child := &component{name: strings.ToUpper(prop.value)}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case "END":
+			stack = closeComponent(stack, strings.ToUpper(prop.value))
+		default:
+			current.properties = append(current.properties, prop)
+		}
+	}
+
+	for len(stack) > 1 {
+		setError(stack, fmt.Errorf("%w: %s is not closed", ErrUnbalancedBlock, stack[len(stack)-1].name))
+		stack = stack[:len(stack)-1]
+	}
+
+	items := make([]Item, 0)
+	found := false
+	for _, calendar := range root.children {
+		if calendar.name != "VCALENDAR" {
+			continue
+		}
+
+		found = true
+		for _, child := range calendar.children {
+			if child.name == "VEVENT" {
+				items = append(items, decodeEvent(child))
+			}
+		}
+	}
+
+	if !found {
+		return nil, ErrNoCalendar
+	}
+
+	return items, nil
+}
+
+// closeComponent закрывает ближайший к вершине стека компонент name вместе с вложенными в него
+// незакрытыми компонентами. END без соответствующего BEGIN пропускается.
+func closeComponent(stack []*component, name string) []*component {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].name != name {
+			continue
+		}
+
+		for j := len(stack) - 1; j > i; j-- {
+			setError(stack[:j+1], fmt.Errorf("%w: %s is not closed", ErrUnbalancedBlock, stack[j].name))
+		}
+		return stack[:i]
+	}
+
+	setError(stack, fmt.Errorf("%w: END:%s", ErrUnbalancedBlock, name))
+	return stack
+}
+
+// setError запоминает ошибку err в ближайшем к вершине стека компоненте VEVENT, если в нем еще
+// нет ошибки. Ошибки вне VEVENT не относятся ни к одному элементу и пропускаются.
+func setError(stack []*component, err error) {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].name != "VEVENT" {
+			continue
+		}
+
+		if stack[i].err == nil {
+			stack[i].err = err
+		}
+		return
+	}
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseLine(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+
+	if colon <= 0 {
+		return prop, fmt.Errorf("%w: %q", ErrMalformedLine, line)
+	}
+
+	prop.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+
+	return prop, nil
+}
+
+func (c *component) property(name string) (property, bool) {
+	for _, prop := range c.properties {
+		if prop.name == name {
+			return prop, true
+		}
+	}
+
+	return property{}, false
+}
+
+func decodeEvent(c *component) Item {
+	item := Item{}
+	if uid, ok := c.property("UID"); ok {
+		item.UID = uid.value
+	}
+
+	if c.err != nil {
+		item.Err = c.err
+		return item
+	}
+
+	event, err := decodeEventFields(c)
+	if err != nil {
+		item.Err = err
+		return item
+	}
+
+	item.Event = event
+	return item
+}
+
+func decodeEventFields(c *component) (event storage.Event, err error) {
+	summary, ok := c.property("SUMMARY")
+	if !ok || summary.value == "" {
+		return event, errNoSummary
+	}
+
+	event.Title = unescape(summary.value)
+	if description, ok := c.property("DESCRIPTION"); ok {
+		event.Description = unescape(description.value)
+	}
+
+	dtstart, ok := c.property("DTSTART")
+	if !ok {
+		return event, errNoDTStart
+	}
+
+	startTime, allDay, err := parseTime(dtstart)
+	if err != nil {
+		return event, err
+	}
+
+	finishTime, err := finishTime(c, startTime, allDay)
+	if err != nil {
+		return event, err
+	}
+
+	event.StartTime = storage.EventTime(startTime)
+	event.FinishTime = storage.EventTime(finishTime)
+	event.TimeZone = dtstart.params["TZID"]
+	if transp, ok := c.property("TRANSP"); ok {
+		event.Transparent = strings.EqualFold(transp.value, "TRANSPARENT")
+	}
+
+	event.Reminders, err = reminders(c, startTime)
+	if err != nil {
+		return event, err
+	}
+
+	if rrule, ok := c.property("RRULE"); ok {
+		if _, err = storage.ParseRRule(rrule.value); err != nil {
+			return event, err
+		}
+		event.Recurrence.RRule = rrule.value
+	}
+
+	event.Attendees = attendees(c)
+	for _, prop := range c.properties {
+		if prop.name != "EXDATE" {
+			continue
+		}
+
+		for _, value := range strings.Split(prop.value, ",") {
+			exDate, _, err := parseTime(property{params: prop.params, value: value})
+			if err != nil {
+				return event, err
+			}
+			event.Recurrence.ExDates = append(event.Recurrence.ExDates, storage.EventTime(exDate))
+		}
+	}
+
+	return event, nil
+}
+
+// attendees возвращает участников события, заданных адресом urn:uuid. Участники с другими
+// адресами (например, mailto) пропускаются.
+func attendees(c *component) []storage.Attendee {
+	var result []storage.Attendee
+	for _, prop := range c.properties {
+		if prop.name != "ATTENDEE" || !strings.HasPrefix(strings.ToLower(prop.value), attendeePrefix) {
+			continue
+		}
+
+		userID, err := uuid.FromString(prop.value[len(attendeePrefix):])
+		if err != nil {
+			continue
+		}
+
+		status, err := storage.ParseAttendeeStatus(strings.ToLower(prop.params["PARTSTAT"]))
+		if err != nil {
+			status = storage.NeedsAction
+		}
+		result = append(result, storage.Attendee{UserID: userID, Status: status})
+	}
+
+	return result
+}
+
+func finishTime(c *component, startTime time.Time, allDay bool) (time.Time, error) {
+	if dtend, ok := c.property("DTEND"); ok {
+		finishTime, _, err := parseTime(dtend)
+		return finishTime, err
+	}
+
+	if duration, ok := c.property("DURATION"); ok {
+		d, err := ParseDuration(duration.value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return startTime.Add(d), nil
+	}
+
+	// RFC 5545: без DTEND и DURATION событие на дату длится один день, иначе - заканчивается в момент начала.
+	if allDay {
+		return startTime.AddDate(0, 0, 1), nil
+	}
+
+	return startTime, nil
+}
+
+// reminders возвращает напоминания для компонентов VALARM события. Напоминания после начала
+// события и повторы с тем же смещением пропускаются.
+func reminders(c *component, startTime time.Time) ([]storage.Reminder, error) {
+	var result []storage.Reminder
+	seen := make(map[int]bool)
+	for _, alarm := range c.children {
+		if alarm.name != "VALARM" {
+			continue
+		}
+
+		trigger, ok := alarm.property("TRIGGER")
+		if !ok {
+			continue
+		}
+
+		var offset int
+		if trigger.params["VALUE"] == "DATE-TIME" {
+			triggerTime, _, err := parseTime(trigger)
+			if err != nil {
+				return nil, err
+			}
+			offset = int(startTime.Sub(triggerTime).Minutes())
+		} else {
+			d, err := ParseDuration(trigger.value)
+			if err != nil {
+				return nil, err
+			}
+			offset = int(-d.Minutes())
+		}
+
+		if offset < 0 || seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		result = append(result, storage.Reminder{Offset: offset})
+	}
+
+	return result, nil
+}
+
+// parseTime разбирает значение типа DATE или DATE-TIME с учетом параметра TZID.
+// Время без указания зоны (floating) считается временем UTC.
+func parseTime(prop property) (t time.Time, allDay bool, err error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err = time.Parse(dateLayout, value)
+		if err != nil {
+			return t, false, fmt.Errorf("%w: %q", errInvalidTime, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(utcLayout, value)
+	} else {
+		loc := time.UTC
+		if tzid, ok := prop.params["TZID"]; ok {
+			if loc, err = time.LoadLocation(tzid); err != nil {
+				return t, false, fmt.Errorf("%w: unknown TZID %q", errInvalidTime, tzid)
+			}
+		}
+		t, err = time.ParseInLocation(localLayout, value, loc)
+	}
+
+	if err != nil {
+		return t, false, fmt.Errorf("%w: %q", errInvalidTime, value)
+	}
+
+	return t.UTC(), false, nil
+}
+
+// ParseDuration разбирает значение типа DURATION (RFC 5545), например "-PT15M" или "P1DT2H".
+func ParseDuration(value string) (time.Duration, error) {
+	matches := durationRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("%w: %q", errInvalidDuration, value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if matches[i+2] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", errInvalidDuration, value)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	if matches[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
@@ -0,0 +1,218 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:first@example.com\r\n" +
+	"DTSTART;TZID=Europe/Moscow:20240102T150000\r\n" +
+	"DTEND;TZID=Europe/Moscow:20240102T160000\r\n" +
+	"SUMMARY:Meeting\\, important\r\n" +
+	"DESCRIPTION:Very important meeting\\nwith a very long description that has to be \r\n" +
+	" folded\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:-PT30M\r\n" +
+	"END:VALARM\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER;VALUE=DATE-TIME:20240102T110000Z\r\n" +
+	"END:VALARM\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:PT5M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:broken@example.com\r\n" +
+	"SUMMARY:No start\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:third@example.com\r\n" +
+	"DTSTART:20240105T100000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"RRULE:FREQ=WEEKLY;COUNT=4\r\n" +
+	"EXDATE:20240112T100000Z\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestDecode(t *testing.T) {
+	items, err := Decode(strings.NewReader(calendar))
+	require.NoError(t, err)
+	require.Equal(t, 3, len(items))
+
+	t.Run("event with time zone and alarms", func(t *testing.T) {
+		require.NoError(t, items[0].Err)
+		require.Equal(t, "first@example.com", items[0].UID)
+		event := items[0].Event
+		require.Equal(t, "Meeting, important", event.Title)
+		require.Equal(t, "Very important meeting\nwith a very long description that has to be folded", event.Description)
+		require.Equal(t, time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC), time.Time(event.StartTime))
+		require.Equal(t, time.Date(2024, time.January, 2, 13, 0, 0, 0, time.UTC), time.Time(event.FinishTime))
+		require.Equal(t, []storage.Reminder{{Offset: 30}, {Offset: 60}}, event.Reminders)
+	})
+
+	t.Run("broken event is reported", func(t *testing.T) {
+		require.ErrorIs(t, items[1].Err, errNoDTStart)
+		require.Equal(t, "broken@example.com", items[1].UID)
+	})
+
+	t.Run("recurring event with duration", func(t *testing.T) {
+		require.NoError(t, items[2].Err)
+		event := items[2].Event
+		require.Equal(t, time.Date(2024, time.January, 5, 11, 30, 0, 0, time.UTC), time.Time(event.FinishTime))
+		require.Equal(t, "FREQ=WEEKLY;COUNT=4", event.Recurrence.RRule)
+		require.Equal(t, []storage.EventTime{storage.EventTime(time.Date(2024, time.January, 12, 10, 0, 0, 0,
+			time.UTC))}, event.Recurrence.ExDates)
+	})
+
+	t.Run("not a calendar", func(t *testing.T) {
+		_, err := Decode(strings.NewReader("BEGIN:VCARD\r\nEND:VCARD\r\n"))
+		require.ErrorIs(t, err, ErrNoCalendar)
+	})
+
+	t.Run("unbalanced calendar", func(t *testing.T) {
+		items, err := Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(items))
+		require.ErrorIs(t, items[0].Err, ErrUnbalancedBlock)
+	})
+
+	t.Run("malformed lines are reported on their event", func(t *testing.T) {
+		items, err := Decode(strings.NewReader("BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VEVENT\r\nUID:malformed\r\nDTSTART:20240105T100000Z\r\nSUMMARY:One\r\nbroken\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:stray-end\r\nDTSTART:20240105T100000Z\r\nSUMMARY:Two\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:unclosed-alarm\r\nDTSTART:20240105T100000Z\r\nSUMMARY:Three\r\nBEGIN:VALARM\r\n" +
+			"END:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:good\r\nDTSTART:20240105T100000Z\r\nSUMMARY:Four\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"))
+		require.NoError(t, err)
+		require.Equal(t, 4, len(items))
+		require.ErrorIs(t, items[0].Err, ErrMalformedLine)
+		require.Equal(t, "malformed", items[0].UID)
+		require.ErrorIs(t, items[1].Err, ErrUnbalancedBlock)
+		require.Equal(t, "stray-end", items[1].UID)
+		require.ErrorIs(t, items[2].Err, ErrUnbalancedBlock)
+		require.NoError(t, items[3].Err)
+		require.Equal(t, "Four", items[3].Event.Title)
+	})
+}
+
+func TestEncode(t *testing.T) {
+	id, _ := uuid.NewV4()
+	events := []storage.Event{{
+		ID:          id,
+		Title:       "Meeting; " + strings.Repeat("очень ", 20),
+		Description: "Line one\nLine two",
+		StartTime:   storage.EventTime(time.Date(2024, time.January, 2, 15, 0, 0, 0, time.UTC)),
+		FinishTime:  storage.EventTime(time.Date(2024, time.January, 2, 16, 0, 0, 0, time.UTC)),
+		Reminders:   []storage.Reminder{{Offset: 60}, {Offset: 15}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, events))
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		require.LessOrEqual(t, len(line), maxLineLen)
+	}
+	require.Contains(t, buf.String(), "UID:"+id.String()+"\r\n")
+	require.Contains(t, buf.String(), "DTSTART:20240102T150000Z\r\n")
+	require.Contains(t, buf.String(), "TRIGGER:-PT60M\r\n")
+	require.Contains(t, buf.String(), "TRIGGER:-PT15M\r\n")
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(items))
+	require.NoError(t, items[0].Err)
+	require.Equal(t, events[0].Title, items[0].Event.Title)
+	require.Equal(t, events[0].Description, items[0].Event.Description)
+	require.Equal(t, events[0].StartTime, items[0].Event.StartTime)
+	require.Equal(t, events[0].FinishTime, items[0].Event.FinishTime)
+	require.Equal(t, events[0].Reminders, items[0].Event.Reminders)
+}
+
+func TestEncodeRecurring(t *testing.T) {
+	id, _ := uuid.NewV4()
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	event := storage.Event{
+		ID:         id,
+		Title:      "Standup",
+		StartTime:  storage.EventTime(time.Date(2024, time.January, 5, 10, 0, 0, 0, moscow)),
+		FinishTime: storage.EventTime(time.Date(2024, time.January, 5, 10, 30, 0, 0, moscow)),
+		TimeZone:   "Europe/Moscow",
+		Recurrence: storage.Recurrence{
+			RRule:   "FREQ=WEEKLY;COUNT=4",
+			ExDates: []storage.EventTime{storage.EventTime(time.Date(2024, time.January, 12, 7, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, []storage.Event{event}))
+	require.Contains(t, buf.String(), "UID:"+id.String()+"\r\n")
+	require.Contains(t, buf.String(), "DTSTART;TZID=Europe/Moscow:20240105T100000\r\n")
+	require.Contains(t, buf.String(), "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+	require.Contains(t, buf.String(), "EXDATE:20240112T070000Z\r\n")
+
+	items, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(items))
+	require.NoError(t, items[0].Err)
+	decoded := items[0].Event
+	require.True(t, time.Time(event.StartTime).Equal(time.Time(decoded.StartTime)))
+	require.True(t, time.Time(event.FinishTime).Equal(time.Time(decoded.FinishTime)))
+	require.Equal(t, event.TimeZone, decoded.TimeZone)
+	require.Equal(t, event.Recurrence, decoded.Recurrence)
+}
+
+func TestWriteLine(t *testing.T) {
+	for _, line := range []string{strings.Repeat("a", 300), "DESCRIPTION: " + strings.Repeat("ж", 100)} {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeLine(w, line)
+		require.NoError(t, w.Flush())
+
+		folded := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+		require.Greater(t, len(folded), 1)
+		for _, part := range folded {
+			require.LessOrEqual(t, len(part), maxLineLen)
+		}
+		require.Equal(t, maxLineLen, len(folded[0]))
+
+		lines, err := unfold(&buf)
+		require.NoError(t, err)
+		require.Equal(t, []string{line}, lines)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"-PT15M":   -15 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"-P1W":     -7 * 24 * time.Hour,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+	} {
+		d, err := ParseDuration(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, d, value)
+	}
+
+	for _, value := range []string{"", "P", "PT", "15M", "-PT"} {
+		_, err := ParseDuration(value)
+		require.Error(t, err, value)
+	}
+}
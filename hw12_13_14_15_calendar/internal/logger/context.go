@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Поля записей журнала.
+const (
+	FieldRequestID  = "request_id"
+	FieldUserID     = "user_id"
+	FieldEventID    = "event_id"
+	FieldTraceID    = "trace_id"
+	FieldSpanID     = "span_id"
+	FieldMethod     = "method"
+	FieldPath       = "path"
+	FieldProto      = "proto"
+	FieldStatus     = "status"
+	FieldDuration   = "duration" // Длительность в секундах
+	FieldRemoteAddr = "remote_addr"
+	FieldUserAgent  = "user_agent"
+)
+
+// RequestIDHeader - заголовок HTTP и ключ метаданных GRPC с ID запроса.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength ограничивает длину ID запроса, переданного клиентом.
+const maxRequestIDLength = 128
+
+type contextKey struct{}
+
+// requestFields - поля журнала запроса. Все производные контексты запроса ссылаются на одни и те же
+// поля, поэтому поле, добавленное обработчиком, попадает и в запись о запросе, которую middleware
+// пишет после обработки.
+type requestFields struct {
+	mu     sync.Mutex
+	fields Fields
+}
+
+// ContextWithRequestID возвращает ctx запроса с ID requestID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestFields{fields: Fields{FieldRequestID: requestID}})
+}
+
+// RequestIDFromContext возвращает ID запроса из ctx или пустую строку, если в ctx нет запроса.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := contextFields(ctx)[FieldRequestID].(string)
+	return requestID
+}
+
+// AddField добавляет поле key во все записи журнала запроса ctx, в том числе сделанные ранее
+// полученными контекстами этого запроса. Если в ctx нет запроса, поле не добавляется.
+func AddField(ctx context.Context, key string, value interface{}) {
+	request, ok := ctx.Value(contextKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+
+	request.mu.Lock()
+	defer request.mu.Unlock()
+	request.fields[key] = value
+}
+
+// NewRequestID возвращает ID запроса, переданный клиентом в requestID, если он допустим,
+// иначе - новый ID.
+func NewRequestID(requestID string) string {
+	if validRequestID(requestID) {
+		return requestID
+	}
+
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// validRequestID разрешает непустые ID не длиннее maxRequestIDLength из латинских букв, цифр и знаков "-_.:".
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// contextFields возвращает поля запроса и ID трассировки и span из ctx.
+func contextFields(ctx context.Context) Fields {
+	fields := Fields{}
+	if request, ok := ctx.Value(contextKey{}).(*requestFields); ok {
+		request.mu.Lock()
+		for key, value := range request.fields {
+			fields[key] = value
+		}
+		request.mu.Unlock()
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields[FieldTraceID] = spanContext.TraceID().String()
+		fields[FieldSpanID] = spanContext.SpanID().String()
+	}
+
+	return fields
+}
@@ -1,10 +1,15 @@
+// Package logger пишет структурированный журнал сервисов календаря в текстовом формате или в JSON.
+// Записи, сделанные с контекстом запроса, содержат ID запроса, пользователя, события и трассировки.
 package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -12,72 +17,176 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+// Форматы журнала.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Назначения журнала, кроме пути к файлу.
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
 )
 
-type Logger struct{}
+// Fields - поля записи журнала.
+type Fields map[string]interface{}
 
+// Logger пишет в журнал, настроенный New или NewFromConfig, записи с полями fields.
+type Logger struct {
+	fields Fields
+}
+
+// New настраивает журнал с уровнем level в текстовом формате и стандартном потоке ошибок.
 func New(level string) *Logger {
-	logLevel, err := logrus.ParseLevel(level)
+	logger, err := NewFromConfig(config.LoggerConf{Level: level})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return logger
+}
+
+// NewFromConfig настраивает уровень, формат и назначение журнала по конфигурации cfg.
+func NewFromConfig(cfg config.LoggerConf) (*Logger, error) {
+	logLevel, err := logrus.ParseLevel(cfg.Level)
 	if err != nil {
-		log.Fatalf("failed to parse the level: %v", err)
+		return nil, fmt.Errorf("failed to parse the level: %w", err)
+	}
+
+	var formatter logrus.Formatter
+	switch cfg.Format {
+	case "", FormatText:
+		formatter = &logrus.TextFormatter{}
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	output, err := openOutput(cfg.Output)
+	if err != nil {
+		return nil, err
 	}
 
 	logrus.SetLevel(logLevel)
-	return &Logger{}
+	logrus.SetFormatter(formatter)
+	logrus.SetOutput(output)
+	return &Logger{}, nil
+}
+
+// openOutput открывает назначение журнала: стандартный поток или файл, в который дописываются записи.
+// Файл открыт до завершения процесса.
+func openOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", OutputStderr:
+		return os.Stderr, nil
+	case OutputStdout:
+		return os.Stdout, nil
+	default:
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		return file, nil
+	}
+}
+
+// FromContext возвращает логгер, добавляющий в записи поля запроса и трассировки из ctx.
+func FromContext(ctx context.Context) *Logger {
+	return Logger{}.WithContext(ctx)
+}
+
+// WithContext возвращает логгер, добавляющий в записи поля запроса и трассировки из ctx.
+func (l Logger) WithContext(ctx context.Context) *Logger {
+	return l.WithFields(contextFields(ctx))
+}
+
+// WithFields возвращает логгер, добавляющий в записи поля fields.
+func (l Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return &Logger{fields: merged}
+}
+
+func (l Logger) entry() *logrus.Entry {
+	return logrus.WithFields(logrus.Fields(l.fields))
 }
 
 func (l Logger) Info(msg ...interface{}) {
-	logrus.Info(msg...)
+	l.entry().Info(msg...)
 }
 
 func (l Logger) Infof(format string, args ...interface{}) {
-	logrus.Infof(format, args...)
+	l.entry().Infof(format, args...)
 }
 
 func (l Logger) Error(msg ...interface{}) {
-	logrus.Error(msg...)
+	l.entry().Error(msg...)
 }
 
 func (l Logger) Warn(msg ...interface{}) {
-	logrus.Warn(msg...)
+	l.entry().Warn(msg...)
 }
 
 func (l Logger) Debug(msg ...interface{}) {
-	logrus.Debug(msg...)
+	l.entry().Debug(msg...)
 }
 
+// LogHTTPRequest пишет запись о выполненном HTTP-запросе с полями запроса из его контекста.
 func (l Logger) LogHTTPRequest(request *http.Request, duration time.Duration, statusCode int) {
-	ip, _, err := net.SplitHostPort(request.RemoteAddr)
-	if err != nil {
-		logrus.Errorf("error splitting host and port: %q", request.RemoteAddr)
-	}
-
-	formatString := "%s [%s] %s %s %s %d %d %s"
-	t := time.Now().Format("02/Jan/2006:15:04:05 -0700")
-	logrus.Infof(formatString, ip, t, request.Method, request.URL.String(), request.Proto, statusCode,
-		duration.Microseconds(), request.UserAgent())
+	l.WithContext(request.Context()).WithFields(Fields{
+		FieldRemoteAddr: remoteIP(request.RemoteAddr),
+		FieldMethod:     request.Method,
+		FieldPath:       request.URL.String(),
+		FieldProto:      request.Proto,
+		FieldStatus:     statusCode,
+		FieldDuration:   duration.Seconds(),
+		FieldUserAgent:  request.UserAgent(),
+	}).Info("http request")
 }
 
+// LogGRPCRequest пишет запись о выполненном GRPC-запросе с полями запроса из контекста ctx.
 func (l Logger) LogGRPCRequest(ctx context.Context, info *grpc.UnaryServerInfo, duration time.Duration,
 	statusCode string,
 ) {
-	peer, ok := peer.FromContext(ctx)
-	if !ok {
-		logrus.Errorf("error receiving peer information: %s", peer.Addr.String())
+	remoteAddr := ""
+	if peer, ok := peer.FromContext(ctx); ok {
+		remoteAddr = remoteIP(peer.Addr.String())
 	}
 
 	userAgent := ""
-	metadata, ok := metadata.FromIncomingContext(ctx)
-	if ok {
+	if metadata, ok := metadata.FromIncomingContext(ctx); ok {
 		userAgent = strings.Join(metadata["user-agent"], " ")
 	}
 
-	ip, _, err := net.SplitHostPort(peer.Addr.String())
+	l.WithContext(ctx).WithFields(Fields{
+		FieldRemoteAddr: remoteAddr,
+		FieldMethod:     info.FullMethod,
+		FieldProto:      "HTTP/2",
+		FieldStatus:     statusCode,
+		FieldDuration:   duration.Seconds(),
+		FieldUserAgent:  userAgent,
+	}).Info("grpc request")
+}
+
+// remoteIP возвращает IP-адрес из адреса клиента addr или addr, если в нем нет порта.
+func remoteIP(addr string) string {
+	ip, _, err := net.SplitHostPort(addr)
 	if err != nil {
-		logrus.Errorf("error splitting host and port: %q", peer.Addr.String())
+		return addr
 	}
 
-	formatString := "%s [%s] %s %s %s %s %d %s"
-	t := time.Now().Format("02/Jan/2006:15:04:05 -0700")
-	logrus.Infof(formatString, ip, t, "rpc", info.FullMethod, "HTTP/2", statusCode, duration.Microseconds(), userAgent)
+	return ip
 }
@@ -1,14 +1,168 @@
 package logger
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 )
 
+// newJSONLogger настраивает журнал в формате JSON в файле и возвращает функцию, читающую записи журнала.
+// После теста журнал возвращается в стандартный поток ошибок.
+func newJSONLogger(t *testing.T) (*Logger, func() []map[string]interface{}) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "calendar.log")
+	logger, err := NewFromConfig(config.LoggerConf{Level: "debug", Format: FormatJSON, Output: file})
+	require.NoError(t, err)
+	t.Cleanup(func() { New("info") })
+
+	return logger, func() []map[string]interface{} {
+		t.Helper()
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var records []map[string]interface{}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			records = append(records, record)
+		}
+		require.NoError(t, scanner.Err())
+		return records
+	}
+}
+
 func TestLogger(t *testing.T) {
 	t.Run("dummy", func(t *testing.T) {
 		var err error
 		require.NoError(t, err)
 	})
+
+	t.Run("json", func(t *testing.T) {
+		logger, records := newJSONLogger(t)
+		logger.WithFields(Fields{FieldEventID: "42"}).Info("event created")
+		logger.Debug("debug message")
+
+		logged := records()
+		require.Len(t, logged, 2)
+		require.Equal(t, "info", logged[0]["level"])
+		require.Equal(t, "event created", logged[0]["msg"])
+		require.Equal(t, "42", logged[0][FieldEventID])
+		require.Equal(t, "debug message", logged[1]["msg"])
+		require.NotContains(t, logged[1], FieldEventID)
+	})
+
+	t.Run("level", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "calendar.log")
+		logger, err := NewFromConfig(config.LoggerConf{Level: "warn", Output: file})
+		require.NoError(t, err)
+		t.Cleanup(func() { New("info") })
+
+		logger.Info("skipped")
+		logger.Warn("written")
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NotContains(t, string(content), "skipped")
+		require.Contains(t, string(content), `level=warning msg=written`)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewFromConfig(config.LoggerConf{Level: "verbose"})
+		require.Error(t, err)
+
+		_, err = NewFromConfig(config.LoggerConf{Level: "info", Format: "xml"})
+		require.EqualError(t, err, `unknown log format "xml"`)
+
+		_, err = NewFromConfig(config.LoggerConf{Level: "info", Output: filepath.Join(t.TempDir(), "missing", "log")})
+		require.Error(t, err)
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("request fields", func(t *testing.T) {
+		logger, records := newJSONLogger(t)
+		ctx := ContextWithRequestID(context.Background(), "request-1")
+		handlerCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		AddField(handlerCtx, FieldUserID, "user-1")
+
+		require.Equal(t, "request-1", RequestIDFromContext(handlerCtx))
+		FromContext(handlerCtx).Info("from handler")
+		logger.WithContext(ctx).Info("from middleware")
+
+		for _, record := range records() {
+			require.Equal(t, "request-1", record[FieldRequestID], record["msg"])
+			require.Equal(t, "user-1", record[FieldUserID], record["msg"])
+		}
+	})
+
+	t.Run("without request", func(t *testing.T) {
+		ctx := context.Background()
+		AddField(ctx, FieldUserID, "user-1")
+		require.Empty(t, RequestIDFromContext(ctx))
+		require.Empty(t, contextFields(ctx))
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		require.NoError(t, err)
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		require.NoError(t, err)
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+
+		fields := contextFields(ctx)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[FieldTraceID])
+		require.Equal(t, "00f067aa0ba902b7", fields[FieldSpanID])
+	})
+}
+
+func TestNewRequestID(t *testing.T) {
+	for _, requestID := range []string{"request-1", "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", "svc:42_a.b"} {
+		require.Equal(t, requestID, NewRequestID(requestID))
+	}
+
+	for _, requestID := range []string{"", "request 1", "request\n1", strings.Repeat("a", maxRequestIDLength+1)} {
+		generated := NewRequestID(requestID)
+		require.NotEqual(t, requestID, generated)
+		require.True(t, validRequestID(generated), generated)
+	}
+	require.NotEqual(t, NewRequestID(""), NewRequestID(""))
+}
+
+func TestLogHTTPRequest(t *testing.T) {
+	logger, records := newJSONLogger(t)
+	request := httptest.NewRequest(http.MethodGet, "/events/42", nil)
+	request.Header.Set("User-Agent", "calendar-test")
+	ctx := ContextWithRequestID(request.Context(), "request-1")
+	AddField(ctx, FieldEventID, "42")
+
+	logger.LogHTTPRequest(request.WithContext(ctx), 1500*time.Millisecond, http.StatusNotFound)
+
+	logged := records()
+	require.Len(t, logged, 1)
+	require.Equal(t, "http request", logged[0]["msg"])
+	require.Equal(t, "request-1", logged[0][FieldRequestID])
+	require.Equal(t, "42", logged[0][FieldEventID])
+	require.Equal(t, http.MethodGet, logged[0][FieldMethod])
+	require.Equal(t, "/events/42", logged[0][FieldPath])
+	require.Equal(t, "192.0.2.1", logged[0][FieldRemoteAddr])
+	require.Equal(t, "calendar-test", logged[0][FieldUserAgent])
+	require.InDelta(t, float64(http.StatusNotFound), logged[0][FieldStatus], 0)
+	require.InDelta(t, 1.5, logged[0][FieldDuration], 0.001)
 }
@@ -0,0 +1,189 @@
+// Package metrics содержит метрики Prometheus сервисов календаря. Метрики регистрируются
+// в реестре по умолчанию вместе с метриками среды выполнения Go и процесса.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "calendar"
+
+// Значения метки status для операций, у которых нет собственного кода ответа.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Number of GRPC requests by method and status code.",
+	}, []string{"method", "status"})
+
+	grpcRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "GRPC request latency by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status"})
+
+	storageQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "query_duration_seconds",
+		Help:      "Storage query latency by query.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "job_runs_total",
+		Help:      "Number of finished scheduler job runs by job and outcome.",
+	}, []string{"job", "outcome"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "job_duration_seconds",
+		Help:      "Scheduler job run duration by job.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+
+	purgedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "purged_events_total",
+		Help:      "Number of old events purged by the scheduler.",
+	})
+
+	queuePublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "published_messages_total",
+		Help:      "Number of notifications published to the queue.",
+	}, []string{"queue"})
+
+	queuePublishErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "publish_errors_total",
+		Help:      "Number of failed notification publishing attempts.",
+	}, []string{"queue"})
+
+	queueConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "consumed_messages_total",
+		Help:      "Number of notifications consumed from the queue by processing status.",
+	}, []string{"queue", "status"})
+
+	senderDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sender",
+		Name:      "deliveries_total",
+		Help:      "Number of notification delivery attempts by channel and status.",
+	}, []string{"channel", "status"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		httpRequests,
+		httpRequestDuration,
+		grpcRequests,
+		grpcRequestDuration,
+		storageQueryDuration,
+		jobRuns,
+		jobDuration,
+		purgedEvents,
+		queuePublished,
+		queuePublishErrors,
+		queueConsumed,
+		senderDeliveries,
+	)
+}
+
+// Handler возвращает обработчик /metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest учитывает HTTP-запрос к маршруту route (шаблону пути).
+func ObserveHTTPRequest(route, method string, statusCode int, duration time.Duration) {
+	status := strconv.Itoa(statusCode)
+	httpRequests.WithLabelValues(route, method, status).Inc()
+	httpRequestDuration.WithLabelValues(route, method, status).Observe(duration.Seconds())
+}
+
+// ObserveGRPCRequest учитывает GRPC-запрос к методу method.
+func ObserveGRPCRequest(method, status string, duration time.Duration) {
+	grpcRequests.WithLabelValues(method, status).Inc()
+	grpcRequestDuration.WithLabelValues(method, status).Observe(duration.Seconds())
+}
+
+// ObserveStorageQuery учитывает длительность запроса query к хранилищу, начатого в start.
+// Вызывается отложенно: defer metrics.ObserveStorageQuery("create_event", time.Now()).
+func ObserveStorageQuery(query string, start time.Time) {
+	storageQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
+
+// ObserveJobRun учитывает завершенный запуск задания планировщика.
+func ObserveJobRun(job, outcome string, duration time.Duration) {
+	jobRuns.WithLabelValues(job, outcome).Inc()
+	jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
+// AddPurgedEvents учитывает удаленные планировщиком события.
+func AddPurgedEvents(count int64) {
+	purgedEvents.Add(float64(count))
+}
+
+// ObservePublish учитывает публикацию в очередь queue: количество опубликованных уведомлений
+// и ошибку публикации.
+func ObservePublish(queue string, published int, err error) {
+	queuePublished.WithLabelValues(queue).Add(float64(published))
+	if err != nil {
+		queuePublishErrors.WithLabelValues(queue).Inc()
+	}
+}
+
+// ObserveConsume учитывает обработку уведомления, полученного из очереди queue.
+func ObserveConsume(queue string, err error) {
+	queueConsumed.WithLabelValues(queue, status(err)).Inc()
+}
+
+// ObserveDelivery учитывает попытку доставки уведомления по каналу channel.
+func ObserveDelivery(channel string, err error) {
+	senderDeliveries.WithLabelValues(channel, status(err)).Inc()
+}
+
+func status(err error) string {
+	if err != nil {
+		return StatusFailure
+	}
+
+	return StatusSuccess
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func TestMetrics(t *testing.T) {
+	t.Run("http request", func(t *testing.T) {
+		ObserveHTTPRequest("/events/{ID}", http.MethodGet, http.StatusNotFound, time.Millisecond)
+		require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("/events/{ID}", "GET", "404")))
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		ObservePublish("test", 3, nil)
+		ObservePublish("test", 1, errTest)
+		require.Equal(t, 4.0, testutil.ToFloat64(queuePublished.WithLabelValues("test")))
+		require.Equal(t, 1.0, testutil.ToFloat64(queuePublishErrors.WithLabelValues("test")))
+	})
+
+	t.Run("consume", func(t *testing.T) {
+		ObserveConsume("test", nil)
+		ObserveConsume("test", errTest)
+		ObserveConsume("test", errTest)
+		require.Equal(t, 1.0, testutil.ToFloat64(queueConsumed.WithLabelValues("test", StatusSuccess)))
+		require.Equal(t, 2.0, testutil.ToFloat64(queueConsumed.WithLabelValues("test", StatusFailure)))
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		ObserveDelivery("log", nil)
+		recorder := httptest.NewRecorder()
+		Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		body, err := io.ReadAll(recorder.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), `calendar_sender_deliveries_total{channel="log",status="success"} 1`)
+		require.Contains(t, string(body), "go_goroutines")
+	})
+}
@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Форматы времени начала события и даты дайджеста, если они не заданы в каталоге.
+const (
+	defaultTimeFormat = "2006-01-02 15:04 MST"
+	defaultDateFormat = "2006-01-02"
+)
+
+// Сообщения каталога, из которых составляется относительное время начала события.
+const (
+	messageNow       = "now"
+	messageInMinutes = "inMinutes"
+	messageInHours   = "inHours"
+	messageInDays    = "inDays"
+)
+
+var (
+	errMessageNotFound  = errors.New("message not found in catalog")
+	errPluralFormMissed = errors.New(`plural message must have the "other" form`)
+)
+
+// catalog - сообщения на одном языке. Сообщение - строка формата fmt или набор форм множественного
+// числа ("one", "few", "many", "other") для сообщений, зависящих от числа.
+type catalog struct {
+	locale     string
+	TimeFormat string             `yaml:"timeFormat"`
+	DateFormat string             `yaml:"dateFormat"`
+	Messages   map[string]message `yaml:"messages"`
+}
+
+type message struct {
+	text  string
+	forms map[string]string
+}
+
+func (m *message) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&m.text)
+	}
+
+	if err := node.Decode(&m.forms); err != nil {
+		return err
+	}
+
+	if _, ok := m.forms["other"]; !ok {
+		return errPluralFormMissed
+	}
+
+	return nil
+}
+
+// loadCatalogs загружает каталоги сообщений из файлов <locale>.yaml каталога dir.
+func loadCatalogs(dir string) (map[string]*catalog, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs := make(map[string]*catalog, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		locale := normalizeLocale(strings.TrimSuffix(filepath.Base(file), ".yaml"))
+		c := &catalog{locale: locale}
+		if err = yaml.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", file, err)
+		}
+
+		if c.TimeFormat == "" {
+			c.TimeFormat = defaultTimeFormat
+		}
+
+		if c.DateFormat == "" {
+			c.DateFormat = defaultDateFormat
+		}
+
+		for _, key := range []string{messageNow, messageInMinutes, messageInHours, messageInDays} {
+			if _, ok := c.Messages[key]; !ok {
+				return nil, fmt.Errorf("catalog %s: %w: %q", file, errMessageNotFound, key)
+			}
+		}
+
+		catalogs[locale] = c
+	}
+
+	return catalogs, nil
+}
+
+// translate возвращает сообщение key, подставив в него аргументы args.
+func (c *catalog) translate(key string, args ...interface{}) (string, error) {
+	m, ok := c.Messages[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (%s)", errMessageNotFound, key, c.locale)
+	}
+
+	if m.forms != nil {
+		return "", fmt.Errorf("message %q (%s) is plural, use plural", key, c.locale)
+	}
+
+	return fmt.Sprintf(m.text, args...), nil
+}
+
+// plural возвращает форму сообщения key для числа n, подставив в нее n и аргументы args.
+func (c *catalog) plural(key string, n int, args ...interface{}) (string, error) {
+	m, ok := c.Messages[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (%s)", errMessageNotFound, key, c.locale)
+	}
+
+	if m.forms == nil {
+		return fmt.Sprintf(m.text, append([]interface{}{n}, args...)...), nil
+	}
+
+	form, ok := m.forms[pluralForm(c.locale, n)]
+	if !ok {
+		form = m.forms["other"]
+	}
+
+	return fmt.Sprintf(form, append([]interface{}{n}, args...)...), nil
+}
+
+// relative возвращает время до начала события, например "in 15 minutes". Время округляется
+// до минут, часов или дней, в зависимости от того, сколько осталось до начала. Для уже начавшегося
+// события возвращается сообщение "now".
+func (c *catalog) relative(startTime, now time.Time) (string, error) {
+	minutes := int(startTime.Sub(now).Round(time.Minute) / time.Minute)
+	switch {
+	case minutes <= 0:
+		return c.translate(messageNow)
+	case minutes < 60:
+		return c.plural(messageInMinutes, minutes)
+	case minutes < 24*60:
+		return c.plural(messageInHours, (minutes+30)/60)
+	default:
+		return c.plural(messageInDays, (minutes+12*60)/(24*60))
+	}
+}
+
+// pluralForm возвращает форму множественного числа для числа n на языке локали locale.
+func pluralForm(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch language(locale) {
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// language возвращает язык локали без подтегов, например "en" для "en-GB".
+func language(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+type Logger interface {
+	Info(msg ...interface{})
+}
+
+// Log записывает уведомления в журнал. Используется, если пользователь не выбрал другой канал.
+type Log struct {
+	logger    Logger
+	templates *Templates
+}
+
+func NewLog(logger Logger, templates *Templates) *Log {
+	return &Log{logger: logger, templates: templates}
+}
+
+func (l *Log) Notify(ctx context.Context, recipient Recipient, notification queue.Notification) error {
+	_ = context.WithoutCancel(ctx)
+	message, err := l.templates.Render(storage.ChannelLog, recipient, notification)
+	if err != nil {
+		return err
+	}
+
+	l.logger.Info(message.Text)
+	return nil
+}
@@ -0,0 +1,179 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// SMTP отправляет уведомления по электронной почте.
+type SMTP struct {
+	addr      string
+	host      string
+	from      string
+	auth      smtp.Auth
+	templates *Templates
+}
+
+func NewSMTP(cfg config.SMTPConf, templates *Templates) *SMTP {
+	s := &SMTP{
+		addr:      net.JoinHostPort(cfg.Host, cfg.Port),
+		host:      cfg.Host,
+		from:      cfg.From,
+		templates: templates,
+	}
+
+	if cfg.User != "" {
+		s.auth = smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
+	}
+
+	return s
+}
+
+// Notify отправляет письмо на адрес получателя. Сервер должен принять письмо до истечения контекста.
+func (s *SMTP) Notify(ctx context.Context, recipient Recipient, notification queue.Notification) error {
+	to, err := mail.ParseAddress(recipient.Address)
+	if err != nil {
+		return err
+	}
+
+	message, err := s.templates.Render(storage.ChannelEmail, recipient, notification)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.message(to.String(), notification.Key, message)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("smtp connection error: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return fmt.Errorf("smtp handshake error: %w", err)
+	}
+	defer client.Close()
+
+	if err = s.send(client, to.Address, data); err != nil {
+		return fmt.Errorf("smtp error: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTP) send(client *smtp.Client, to string, message []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if s.auth != nil {
+		if err := client.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(message); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// message возвращает письмо в формате RFC 5322 с текстовой и HTML-версиями уведомления.
+// Ключ дедупликации используется в Message-ID.
+func (s *SMTP) message(to, key string, message Message) ([]byte, error) {
+	var result bytes.Buffer
+	fmt.Fprintf(&result, "From: %s\r\n", s.from)
+	fmt.Fprintf(&result, "To: %s\r\n", to)
+	fmt.Fprintf(&result, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&result, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if key != "" {
+		fmt.Fprintf(&result, "Message-ID: <%s@%s>\r\n", key, s.host)
+	}
+	fmt.Fprintf(&result, "Content-Language: %s\r\n", message.Locale)
+	result.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		result.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		result.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&result, message.Text); err != nil {
+			return nil, err
+		}
+		return result.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&result)
+	fmt.Fprintf(&result, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err = writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
@@ -0,0 +1,176 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// receivedMail - письмо, принятое фейковым SMTP-сервером.
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP запускает SMTP-сервер, принимающий письма в канал, или отклоняющий получателей, если reject.
+func fakeSMTP(t *testing.T, reject bool) (host, port string, mails <-chan receivedMail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	result := make(chan receivedMail, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, reject, result)
+		}
+	}()
+
+	host, port, err = net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	return host, port, result
+}
+
+func serveSMTP(conn net.Conn, reject bool, result chan<- receivedMail) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost fake SMTP")
+
+	current := receivedMail{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.Fields(command + " ")[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			current.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			if reject {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			current.to = append(current.to, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			current.data = data.String()
+			result <- current
+			current = receivedMail{}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func testNotification() queue.Notification {
+	return queue.Notification{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    uuid.Must(uuid.NewV4()),
+		Title:     "Standup",
+		StartTime: storage.EventTime(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+		Key:       "event:1704189600:user",
+	}
+}
+
+// testTemplates загружает шаблоны уведомлений из каталога templates репозитория.
+func testTemplates(t *testing.T) *Templates {
+	t.Helper()
+	templates, err := LoadTemplates(config.TemplatesConf{Dir: "../../templates", DefaultLocale: "en"})
+	require.NoError(t, err)
+	return templates
+}
+
+func TestSMTP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	templates := testTemplates(t)
+	templates.now = func() time.Time { return time.Date(2024, time.January, 2, 9, 45, 0, 0, time.UTC) }
+
+	t.Run("send mail", func(t *testing.T) {
+		host, port, mails := fakeSMTP(t, false)
+		notifier := NewSMTP(config.SMTPConf{Host: host, Port: port, From: "calendar@example.com"}, templates)
+		notification := testNotification()
+		recipient := Recipient{Address: "Alice <alice@example.com>", Location: time.UTC}
+		require.NoError(t, notifier.Notify(ctx, recipient, notification))
+
+		received := <-mails
+		require.Equal(t, "calendar@example.com", received.from)
+		require.Equal(t, []string{"alice@example.com"}, received.to)
+		require.Contains(t, received.data, "Subject: Reminder: Standup\r\n")
+		require.Contains(t, received.data, "Message-ID: <event:1704189600:user@"+host+">\r\n")
+		require.Contains(t, received.data, "Content-Type: multipart/alternative; boundary=")
+
+		message, err := mail.ReadMessage(strings.NewReader(received.data))
+		require.NoError(t, err)
+		_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		parts := multipart.NewReader(message.Body, params["boundary"])
+
+		part, err := parts.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+		text, err := io.ReadAll(part)
+		require.NoError(t, err)
+		require.Contains(t, string(text),
+			`Your event "Standup" starts in 15 minutes, on Tuesday, January 2, 2024 10:00 UTC.`)
+
+		part, err = parts.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, "text/html; charset=utf-8", part.Header.Get("Content-Type"))
+		html, err := io.ReadAll(part)
+		require.NoError(t, err)
+		require.Contains(t, string(html), "Your event &#34;Standup&#34; starts in 15 minutes")
+	})
+
+	t.Run("rejected recipient", func(t *testing.T) {
+		host, port, _ := fakeSMTP(t, true)
+		notifier := NewSMTP(config.SMTPConf{Host: host, Port: port, From: "calendar@example.com"}, templates)
+		require.Error(t, notifier.Notify(ctx, Recipient{Address: "alice@example.com"}, testNotification()))
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		notifier := NewSMTP(config.SMTPConf{Host: "127.0.0.1", Port: "25", From: "calendar@example.com"}, templates)
+		require.Error(t, notifier.Notify(ctx, Recipient{Address: "not an address"}, testNotification()))
+	})
+}
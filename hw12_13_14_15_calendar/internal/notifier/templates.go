@@ -0,0 +1,394 @@
+// Package notifier содержит каналы доставки уведомлений о событиях и шаблоны уведомлений.
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// defaultLocale - язык уведомлений по умолчанию, если он не задан в конфигурации.
+const defaultLocale = "en"
+
+// Шаблоны уведомлений относительно каталога шаблонов. Шаблоны *.html разбираются html/template,
+// остальные - text/template.
+const (
+	EmailSubjectTemplate         = "email/subject.txt"
+	EmailTextTemplate            = "email/body.txt"
+	EmailHTMLTemplate            = "email/body.html"
+	WebhookTextTemplate          = "webhook/text.txt"
+	WebhookPayloadTemplate       = "webhook/payload.json"
+	LogTextTemplate              = "log/text.txt"
+	EmailDigestSubjectTemplate   = "email/digest_subject.txt"
+	EmailDigestTextTemplate      = "email/digest_body.txt"
+	EmailDigestHTMLTemplate      = "email/digest_body.html"
+	WebhookDigestTemplate        = "webhook/digest.txt"
+	WebhookDigestPayloadTemplate = "webhook/digest_payload.json"
+	LogDigestTemplate            = "log/digest.txt"
+)
+
+// agendaTimeFormat - формат времени начала и окончания событий дайджеста.
+const agendaTimeFormat = "15:04"
+
+// localesDir - подкаталог каталога шаблонов с каталогами сообщений <locale>.yaml.
+const localesDir = "locales"
+
+var (
+	errDefaultLocaleMissed = errors.New("catalog of default locale not found")
+	errInvalidPayload      = errors.New("payload template must render valid JSON")
+)
+
+type templateNames struct{ subject, text, html, payload string }
+
+// channelTemplates - шаблоны темы, текста, HTML-версии и тела запроса напоминания для каждого канала.
+var channelTemplates = map[storage.NotificationChannel]templateNames{
+	storage.ChannelLog:     {text: LogTextTemplate},
+	storage.ChannelEmail:   {subject: EmailSubjectTemplate, text: EmailTextTemplate, html: EmailHTMLTemplate},
+	storage.ChannelWebhook: {text: WebhookTextTemplate, payload: WebhookPayloadTemplate},
+}
+
+// digestTemplates - шаблоны темы, текста, HTML-версии и тела запроса ежедневного дайджеста для каждого канала.
+var digestTemplates = map[storage.NotificationChannel]templateNames{
+	storage.ChannelLog: {text: LogDigestTemplate},
+	storage.ChannelEmail: {
+		subject: EmailDigestSubjectTemplate, text: EmailDigestTextTemplate, html: EmailDigestHTMLTemplate,
+	},
+	storage.ChannelWebhook: {text: WebhookDigestTemplate, payload: WebhookDigestPayloadTemplate},
+}
+
+// Recipient - получатель уведомления.
+type Recipient struct {
+	Address  string         // Адрес e-mail или URL вебхука
+	Locale   string         // Язык уведомления, пустое значение - язык по умолчанию
+	Location *time.Location // Часовой пояс, в котором выводится время начала события
+}
+
+// Message - уведомление, подготовленное по шаблонам канала.
+type Message struct {
+	Locale  string // Язык, на котором подготовлено уведомление
+	Subject string
+	Text    string
+	HTML    string // HTML-версия уведомления, пустая, если у канала нет HTML-шаблона
+	Payload string // Тело запроса в формате JSON, пустое, если у канала нет шаблона тела
+}
+
+// TemplateData - данные, доступные в шаблонах уведомлений. Кроме них, в шаблонах доступны функции
+// каталога сообщений: {{t "key" args...}} и {{plural "key" n args...}}, а также {{json value}},
+// которая выводит значение в формате JSON. Date и Agenda заполняются только для дайджеста, Text -
+// только для шаблона тела запроса.
+type TemplateData struct {
+	EventID   string
+	UserID    string
+	Key       string
+	Title     string
+	Recurring bool
+	Start     time.Time // Время начала события в часовом поясе получателя
+	StartTime string    // Время начала события в формате timeFormat каталога сообщений
+	TimeZone  string    // Часовой пояс получателя
+	Relative  string    // Время до начала события, например "in 15 minutes"
+	Locale    string
+	Date      string        // Дата дайджеста в формате dateFormat каталога сообщений
+	Agenda    []AgendaEntry // События дайджеста в порядке начала
+	Text      string        // Текст уведомления по текстовому шаблону канала
+}
+
+// AgendaEntry - событие дайджеста в шаблонах уведомлений.
+type AgendaEntry struct {
+	EventID    string
+	Title      string
+	Recurring  bool
+	Start      time.Time // Время начала события в часовом поясе получателя
+	Finish     time.Time // Время окончания события в часовом поясе получателя
+	StartTime  string    // Время начала события в формате "15:04"
+	FinishTime string    // Время окончания события в формате "15:04"
+}
+
+// Templates - шаблоны уведомлений всех каналов, разобранные для каждого языка.
+type Templates struct {
+	defaultLocale string
+	locales       map[string]*localizedTemplates
+	now           func() time.Time
+}
+
+type localizedTemplates struct {
+	catalog *catalog
+	text    map[string]*template.Template
+	html    map[string]*htmltemplate.Template
+}
+
+// LoadTemplates загружает шаблоны уведомлений и каталоги сообщений из каталога cfg.Dir и проверяет,
+// что каждый шаблон выполняется с каждым каталогом сообщений.
+func LoadTemplates(cfg config.TemplatesConf) (*Templates, error) {
+	catalogs, err := loadCatalogs(filepath.Join(cfg.Dir, localesDir))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{
+		defaultLocale: normalizeLocale(cfg.DefaultLocale),
+		locales:       make(map[string]*localizedTemplates, len(catalogs)),
+		now:           time.Now,
+	}
+	if t.defaultLocale == "" {
+		t.defaultLocale = defaultLocale
+	}
+
+	if _, ok := catalogs[t.defaultLocale]; !ok {
+		return nil, fmt.Errorf("%w: %q in %s", errDefaultLocaleMissed, t.defaultLocale, cfg.Dir)
+	}
+
+	sources := map[string]string{}
+	for _, templates := range []map[storage.NotificationChannel]templateNames{channelTemplates, digestTemplates} {
+		for _, names := range templates {
+			for _, name := range []string{names.subject, names.text, names.html, names.payload} {
+				if name == "" {
+					continue
+				}
+
+				data, err := os.ReadFile(filepath.Join(cfg.Dir, filepath.FromSlash(name)))
+				if err != nil {
+					return nil, err
+				}
+				sources[name] = string(data)
+			}
+		}
+	}
+
+	for locale, c := range catalogs {
+		localized, err := parseTemplates(c, sources)
+		if err != nil {
+			return nil, err
+		}
+		t.locales[locale] = localized
+	}
+
+	if err = t.validate(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func parseTemplates(c *catalog, sources map[string]string) (*localizedTemplates, error) {
+	funcs := map[string]interface{}{
+		"t":      c.translate,
+		"plural": c.plural,
+		"json":   marshalJSON,
+	}
+
+	localized := &localizedTemplates{
+		catalog: c,
+		text:    make(map[string]*template.Template),
+		html:    make(map[string]*htmltemplate.Template),
+	}
+	for name, source := range sources {
+		if strings.HasSuffix(name, ".html") {
+			parsed, err := htmltemplate.New(name).Option("missingkey=error").Funcs(funcs).Parse(source)
+			if err != nil {
+				return nil, err
+			}
+			localized.html[name] = parsed
+			continue
+		}
+
+		parsed, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		localized.text[name] = parsed
+	}
+
+	return localized, nil
+}
+
+// validate выполняет шаблоны всех каналов с каждым каталогом сообщений на тестовых напоминании
+// и дайджесте, чтобы ошибки в шаблонах и пропущенные сообщения обнаруживались при запуске.
+func (t *Templates) validate() error {
+	now := t.now()
+	startTime := storage.EventTime(now.Add(15 * time.Minute))
+	reminder := queue.Notification{
+		Type:      storage.NotificationReminder,
+		ID:        uuid.Nil,
+		UserID:    uuid.Nil,
+		Title:     "Title",
+		StartTime: startTime,
+		Recurring: true,
+		Key:       "key",
+	}
+	digest := queue.Notification{
+		Type:      storage.NotificationDigest,
+		UserID:    uuid.Nil,
+		StartTime: storage.EventTime(now),
+		Key:       "key",
+		Agenda: []storage.AgendaItem{{
+			EventID:    uuid.Nil,
+			Title:      "Title",
+			StartTime:  startTime,
+			FinishTime: storage.EventTime(now.Add(time.Hour)),
+			Recurring:  true,
+		}},
+	}
+
+	for locale := range t.locales {
+		for channel := range channelTemplates {
+			recipient := Recipient{Locale: locale, Location: time.UTC}
+			for _, notification := range []queue.Notification{reminder, digest} {
+				if _, err := t.Render(channel, recipient, notification); err != nil {
+					return fmt.Errorf("locale %s: %w", locale, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Render готовит уведомление для канала channel на языке получателя. Если каталога сообщений
+// для языка получателя нет, используется каталог его основного языка, а затем - язык по умолчанию.
+// Дайджест готовится по отдельным шаблонам канала.
+func (t *Templates) Render(channel storage.NotificationChannel, recipient Recipient,
+	notification queue.Notification,
+) (Message, error) {
+	templates := channelTemplates
+	if notification.IsDigest() {
+		templates = digestTemplates
+	}
+
+	names, ok := templates[channel]
+	if !ok {
+		return Message{}, fmt.Errorf("%w %q", storage.ErrInvalidNotificationChannel, channel)
+	}
+
+	localized := t.localized(recipient.Locale)
+	data, err := localized.data(recipient, notification, t.now())
+	if err != nil {
+		return Message{}, err
+	}
+
+	message := Message{Locale: data.Locale}
+	if message.Subject, err = localized.executeText(names.subject, data); err != nil {
+		return Message{}, err
+	}
+
+	if message.Text, err = localized.executeText(names.text, data); err != nil {
+		return Message{}, err
+	}
+
+	if message.HTML, err = localized.executeHTML(names.html, data); err != nil {
+		return Message{}, err
+	}
+
+	data.Text = message.Text
+	if message.Payload, err = localized.executeText(names.payload, data); err != nil {
+		return Message{}, err
+	}
+
+	if names.payload != "" && !json.Valid([]byte(message.Payload)) {
+		return Message{}, fmt.Errorf("%w: %s", errInvalidPayload, names.payload)
+	}
+
+	return message, nil
+}
+
+func (t *Templates) localized(locale string) *localizedTemplates {
+	locale = normalizeLocale(locale)
+	if localized, ok := t.locales[locale]; ok {
+		return localized
+	}
+
+	if localized, ok := t.locales[language(locale)]; ok {
+		return localized
+	}
+
+	return t.locales[t.defaultLocale]
+}
+
+func (l *localizedTemplates) data(recipient Recipient, notification queue.Notification,
+	now time.Time,
+) (TemplateData, error) {
+	loc := recipient.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start := time.Time(notification.StartTime).In(loc)
+	relative, err := l.catalog.relative(start, now)
+	if err != nil {
+		return TemplateData{}, err
+	}
+
+	agenda := make([]AgendaEntry, 0, len(notification.Agenda))
+	for _, item := range notification.Agenda {
+		itemStart := time.Time(item.StartTime).In(loc)
+		itemFinish := time.Time(item.FinishTime).In(loc)
+		agenda = append(agenda, AgendaEntry{
+			EventID:    item.EventID.String(),
+			Title:      item.Title,
+			Recurring:  item.Recurring,
+			Start:      itemStart,
+			Finish:     itemFinish,
+			StartTime:  itemStart.Format(agendaTimeFormat),
+			FinishTime: itemFinish.Format(agendaTimeFormat),
+		})
+	}
+
+	return TemplateData{
+		EventID:   notification.ID.String(),
+		UserID:    notification.UserID.String(),
+		Key:       notification.Key,
+		Title:     notification.Title,
+		Recurring: notification.Recurring,
+		Start:     start,
+		StartTime: start.Format(l.catalog.TimeFormat),
+		TimeZone:  loc.String(),
+		Relative:  relative,
+		Locale:    l.catalog.locale,
+		Date:      start.Format(l.catalog.DateFormat),
+		Agenda:    agenda,
+	}, nil
+}
+
+func (l *localizedTemplates) executeText(name string, data TemplateData) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	var result bytes.Buffer
+	if err := l.text[name].Execute(&result, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
+
+// marshalJSON выводит значение value в формате JSON для шаблонов тела запроса.
+func marshalJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func (l *localizedTemplates) executeHTML(name string, data TemplateData) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	var result bytes.Buffer
+	if err := l.html[name].Execute(&result, data); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
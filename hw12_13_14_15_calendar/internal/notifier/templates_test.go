@@ -0,0 +1,240 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+func moscow(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := storage.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	return loc
+}
+
+// writeTemplates копирует шаблоны репозитория во временный каталог и заменяет файлы files.
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.Walk("../../templates", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		name, err := filepath.Rel("../../templates", path)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return writeFile(filepath.Join(dir, name), string(data))
+	})
+	require.NoError(t, err)
+
+	for name, data := range files {
+		require.NoError(t, writeFile(filepath.Join(dir, filepath.FromSlash(name)), data))
+	}
+
+	return dir
+}
+
+func writeFile(path, data string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(data), 0o600)
+}
+
+func TestTemplates(t *testing.T) {
+	templates := testTemplates(t)
+	now := time.Date(2024, time.January, 2, 9, 0, 0, 0, time.UTC)
+	templates.now = func() time.Time { return now }
+
+	t.Run("relative start time", func(t *testing.T) {
+		tests := []struct {
+			locale   string
+			before   time.Duration
+			expected string
+		}{
+			{locale: "en", before: 0, expected: "now"},
+			{locale: "en", before: -5 * time.Minute, expected: "now"},
+			{locale: "en", before: time.Minute, expected: "in 1 minute"},
+			{locale: "en", before: 14*time.Minute + 50*time.Second, expected: "in 15 minutes"},
+			{locale: "en", before: 90 * time.Minute, expected: "in 2 hours"},
+			{locale: "en", before: 36 * time.Hour, expected: "in 2 days"},
+			{locale: "ru", before: time.Minute, expected: "через 1 минуту"},
+			{locale: "ru", before: 3 * time.Minute, expected: "через 3 минуты"},
+			{locale: "ru", before: 11 * time.Minute, expected: "через 11 минут"},
+			{locale: "ru", before: 21 * time.Minute, expected: "через 21 минуту"},
+			{locale: "ru", before: 5 * time.Hour, expected: "через 5 часов"},
+			{locale: "ru", before: 48 * time.Hour, expected: "через 2 дня"},
+		}
+
+		for _, tc := range tests {
+			relative, err := templates.localized(tc.locale).catalog.relative(now.Add(tc.before), now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, relative, tc)
+		}
+	})
+
+	t.Run("start time in user zone", func(t *testing.T) {
+		notification := testNotification()
+		message, err := templates.Render(storage.ChannelLog, Recipient{Locale: "ru", Location: moscow(t)}, notification)
+		require.NoError(t, err)
+		require.Equal(t, "["+notification.UserID.String()+"] Событие «Standup» начнется через 1 час, "+
+			"02.01.2024 13:00 MSK.", message.Text)
+	})
+
+	t.Run("email subject and html", func(t *testing.T) {
+		notification := testNotification()
+		notification.Title = "<b>Standup</b>"
+		notification.Recurring = true
+		message, err := templates.Render(storage.ChannelEmail, Recipient{}, notification)
+		require.NoError(t, err)
+		require.Equal(t, "en", message.Locale)
+		require.Equal(t, "Reminder: <b>Standup</b>", message.Subject)
+		require.Contains(t, message.Text, "This is a recurring event.")
+		require.Contains(t, message.HTML, `<html lang="en">`)
+		require.Contains(t, message.HTML, "&lt;b&gt;Standup&lt;/b&gt;")
+		require.NotContains(t, message.HTML, "<b>Standup</b>")
+	})
+
+	t.Run("locale fallback", func(t *testing.T) {
+		for locale, expected := range map[string]string{"ru-RU": "ru", "RU": "ru", "de": "en", "": "en"} {
+			message, err := templates.Render(storage.ChannelWebhook, Recipient{Locale: locale}, testNotification())
+			require.NoError(t, err)
+			require.Equal(t, expected, message.Locale, locale)
+		}
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := templates.Render("sms", Recipient{}, testNotification())
+		require.ErrorIs(t, err, storage.ErrInvalidNotificationChannel)
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		notification := testDigest(t)
+		message, err := templates.Render(storage.ChannelLog, Recipient{Locale: "ru", Location: moscow(t)}, notification)
+		require.NoError(t, err)
+		require.Equal(t, "["+notification.UserID.String()+"] 2 события на 02.01.2024: 09:30 Standup; 13:00 Lunch",
+			message.Text)
+
+		message, err = templates.Render(storage.ChannelEmail, Recipient{Location: moscow(t)}, notification)
+		require.NoError(t, err)
+		require.Equal(t, "Agenda for Tuesday, January 2, 2024", message.Subject)
+		require.Contains(t, message.Text, "You have 2 events on Tuesday, January 2, 2024:")
+		require.Contains(t, message.Text, "09:30–09:45 Standup (recurring)")
+		require.Contains(t, message.HTML, "<li>13:00–14:00 Lunch</li>")
+	})
+}
+
+// testDigest возвращает дайджест за 2 января 2024 года по московскому времени.
+func testDigest(t *testing.T) queue.Notification {
+	t.Helper()
+	loc := moscow(t)
+	return queue.Notification{
+		Type:      storage.NotificationDigest,
+		UserID:    uuid.Must(uuid.NewV4()),
+		StartTime: storage.EventTime(time.Date(2024, time.January, 2, 0, 0, 0, 0, loc)),
+		Key:       "digest:user:2024-01-02",
+		Agenda: []storage.AgendaItem{
+			{
+				EventID:    uuid.Must(uuid.NewV4()),
+				Title:      "Standup",
+				StartTime:  storage.EventTime(time.Date(2024, time.January, 2, 9, 30, 0, 0, loc)),
+				FinishTime: storage.EventTime(time.Date(2024, time.January, 2, 9, 45, 0, 0, loc)),
+				Recurring:  true,
+			},
+			{
+				EventID:    uuid.Must(uuid.NewV4()),
+				Title:      "Lunch",
+				StartTime:  storage.EventTime(time.Date(2024, time.January, 2, 13, 0, 0, 0, loc)),
+				FinishTime: storage.EventTime(time.Date(2024, time.January, 2, 14, 0, 0, 0, loc)),
+			},
+		},
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	t.Run("default locale", func(t *testing.T) {
+		_, err := LoadTemplates(config.TemplatesConf{Dir: "../../templates", DefaultLocale: "de"})
+		require.ErrorIs(t, err, errDefaultLocaleMissed)
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		dir := writeTemplates(t, nil)
+		require.NoError(t, os.Remove(filepath.Join(dir, "email", "body.html")))
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("missing digest template", func(t *testing.T) {
+		dir := writeTemplates(t, nil)
+		require.NoError(t, os.Remove(filepath.Join(dir, filepath.FromSlash(WebhookDigestTemplate))))
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("digest message missing in catalog", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{LogDigestTemplate: `{{t "unknown"}}`})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, errMessageNotFound)
+	})
+
+	t.Run("template syntax error", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{LogTextTemplate: `{{t "reminder" .Title`})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{WebhookTextTemplate: `{{.Description}}`})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.Error(t, err)
+	})
+
+	t.Run("missing payload template", func(t *testing.T) {
+		dir := writeTemplates(t, nil)
+		require.NoError(t, os.Remove(filepath.Join(dir, filepath.FromSlash(WebhookDigestPayloadTemplate))))
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("payload is not JSON", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{WebhookPayloadTemplate: `{"text": {{.Text}}}`})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, errInvalidPayload)
+	})
+
+	t.Run("message missing in catalog", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{EmailSubjectTemplate: `{{t "unknown"}}`})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, errMessageNotFound)
+	})
+
+	t.Run("relative message missing in catalog", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{"locales/de.yaml": "messages:\n  now: jetzt\n"})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, errMessageNotFound)
+	})
+
+	t.Run("plural message without other form", func(t *testing.T) {
+		dir := writeTemplates(t, map[string]string{"locales/de.yaml": "messages:\n  inDays:\n    one: in %d Tag\n"})
+		_, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.ErrorIs(t, err, errPluralFormMissed)
+	})
+}
@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Заголовки запроса вебхука.
+const (
+	SignatureHeader = "X-Calendar-Signature"
+	TimestampHeader = "X-Calendar-Timestamp"
+	DeliveryHeader  = "X-Calendar-Delivery"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+var errForbiddenAddress = errors.New("webhook address is not allowed")
+
+// Webhook отправляет уведомления POST-запросом с телом в формате JSON по шаблону WebhookPayloadTemplate
+// или WebhookDigestPayloadTemplate. Время отправки в секундах Unix передается в заголовке TimestampHeader,
+// строка "<время>.<тело>" подписывается HMAC-SHA256, подпись передается в заголовке SignatureHeader
+// в виде "sha256=<hex>". Получатель должен отклонять запросы со старым временем, чтобы перехваченный
+// запрос нельзя было повторить.
+//
+// Адреса получателей задают пользователи, поэтому запросы к адресам внутренних сетей (loopback,
+// частные и link-local) отклоняются при соединении, а перенаправления не выполняются.
+type Webhook struct {
+	client       *http.Client
+	secret       []byte
+	templates    *Templates
+	now          func() time.Time
+	allowAddress func(ip net.IP) bool
+}
+
+// WebhookPayload - тело запроса вебхука по шаблонам тела из каталога шаблонов репозитория.
+type WebhookPayload struct {
+	Type      string              `json:"type"` // "reminder" или "digest"
+	Key       string              `json:"key,omitempty"`
+	EventID   string              `json:"eventId,omitempty"`
+	UserID    string              `json:"userId"`
+	Title     string              `json:"title,omitempty"`
+	StartTime time.Time           `json:"startTime"` // Для дайджеста - начало дня
+	Recurring bool                `json:"recurring"`
+	Locale    string              `json:"locale"`
+	Text      string              `json:"text"` // Текст по шаблону WebhookTextTemplate или WebhookDigestTemplate
+	Agenda    []WebhookAgendaItem `json:"agenda,omitempty"`
+}
+
+// WebhookAgendaItem - событие дайджеста в теле запроса вебхука.
+type WebhookAgendaItem struct {
+	EventID    string    `json:"eventId"`
+	Title      string    `json:"title"`
+	StartTime  time.Time `json:"startTime"`
+	FinishTime time.Time `json:"finishTime"`
+	Recurring  bool      `json:"recurring"`
+}
+
+func NewWebhook(cfg config.WebhookConf, templates *Templates) *Webhook {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	h := &Webhook{
+		secret:       []byte(cfg.Secret),
+		templates:    templates,
+		now:          time.Now,
+		allowAddress: storage.IsPublicAddress,
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: h.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	h.client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return h
+}
+
+// checkAddress запрещает соединение с адресом address, если он не разрешен allowAddress. Проверяется
+// адрес после разрешения имени, поэтому имя, указывающее на внутренний адрес, тоже отклоняется.
+func (h *Webhook) checkAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !h.allowAddress(ip) {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// Notify отправляет уведомление на URL получателя. Ответ со статусом не 2xx, в том числе
+// перенаправление, считается ошибкой.
+func (h *Webhook) Notify(ctx context.Context, recipient Recipient, notification queue.Notification) error {
+	message, err := h.templates.Render(storage.ChannelWebhook, recipient, notification)
+	if err != nil {
+		return err
+	}
+
+	body := []byte(message.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(h.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(h.secret, timestamp, body))
+	if notification.Key != "" {
+		req.Header.Set(DeliveryHeader, notification.Key)
+	}
+
+	response, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook error: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook error: unexpected status %s", response.Status)
+	}
+
+	return nil
+}
+
+// Sign возвращает подпись запроса вебхука с временем timestamp из заголовка TimestampHeader и телом body
+// для заголовка SignatureHeader.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+func TestWebhook(t *testing.T) {
+	ctx := context.Background()
+	secret := "s3cr3t"
+	templates := testTemplates(t)
+	templates.now = func() time.Time { return time.Date(2024, time.January, 2, 9, 0, 0, 0, time.UTC) }
+
+	// newWebhook возвращает вебхук, которому разрешены запросы к тестовым серверам на loopback.
+	newWebhook := func() *Webhook {
+		notifier := NewWebhook(config.WebhookConf{Secret: secret}, templates)
+		notifier.allowAddress = func(net.IP) bool { return true }
+		notifier.now = func() time.Time { return time.Unix(1704186000, 0) }
+		return notifier
+	}
+
+	t.Run("signed request", func(t *testing.T) {
+		var body []byte
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notification := testNotification()
+		recipient := Recipient{Address: server.URL + "/hook", Locale: "ru-RU", Location: moscow(t)}
+		require.NoError(t, newWebhook().Notify(ctx, recipient, notification))
+
+		require.Equal(t, "application/json", header.Get("Content-Type"))
+		require.Equal(t, "1704186000", header.Get(TimestampHeader))
+		require.Equal(t, Sign([]byte(secret), "1704186000", body), header.Get(SignatureHeader))
+		require.Equal(t, notification.Key, header.Get(DeliveryHeader))
+
+		payload := WebhookPayload{}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, "reminder", payload.Type)
+		require.Equal(t, notification.ID.String(), payload.EventID)
+		require.Equal(t, notification.UserID.String(), payload.UserID)
+		require.Equal(t, "Standup", payload.Title)
+		require.True(t, payload.StartTime.Equal(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)))
+		require.Equal(t, "ru", payload.Locale)
+		require.Equal(t, "Событие «Standup» начнется через 1 час, 02.01.2024 13:00 MSK.", payload.Text)
+	})
+
+	t.Run("digest payload", func(t *testing.T) {
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notification := testDigest(t)
+		recipient := Recipient{Address: server.URL, Location: moscow(t)}
+		require.NoError(t, newWebhook().Notify(ctx, recipient, notification))
+
+		payload := WebhookPayload{}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, "digest", payload.Type)
+		require.Empty(t, payload.EventID)
+		require.Len(t, payload.Agenda, 2)
+		require.Equal(t, notification.Agenda[0].EventID.String(), payload.Agenda[0].EventID)
+		require.True(t, payload.Agenda[1].StartTime.Equal(time.Time(notification.Agenda[1].StartTime)))
+		require.Equal(t, "You have 2 events on Tuesday, January 2, 2024: 09:30 Standup; 13:00 Lunch", payload.Text)
+	})
+
+	t.Run("custom payload template", func(t *testing.T) {
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		dir := writeTemplates(t, map[string]string{WebhookPayloadTemplate: `{"content": {{json .Text}}}`})
+		custom, err := LoadTemplates(config.TemplatesConf{Dir: dir})
+		require.NoError(t, err)
+		custom.now = templates.now
+		notifier := NewWebhook(config.WebhookConf{Secret: secret}, custom)
+		notifier.allowAddress = func(net.IP) bool { return true }
+
+		notification := testNotification()
+		notification.Title = `"Standup"`
+		require.NoError(t, notifier.Notify(ctx, Recipient{Address: server.URL, Location: time.UTC}, notification))
+		payload := map[string]string{}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, map[string]string{
+			"content": `Your event ""Standup"" starts in 1 hour, on Tuesday, January 2, 2024 10:00 UTC.`,
+		}, payload)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		require.Error(t, newWebhook().Notify(ctx, Recipient{Address: server.URL}, testNotification()))
+	})
+
+	t.Run("internal address", func(t *testing.T) {
+		requested := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = true
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notifier := NewWebhook(config.WebhookConf{Secret: secret}, templates)
+		for _, address := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+			err := notifier.Notify(ctx, Recipient{Address: address}, testNotification())
+			require.ErrorIs(t, err, errForbiddenAddress, address)
+		}
+		require.False(t, requested)
+
+		for _, address := range []string{"10.0.0.1", "192.168.1.1", "169.254.169.254", "::1", "fe80::1", "0.0.0.0"} {
+			require.False(t, storage.IsPublicAddress(net.ParseIP(address)), address)
+		}
+		require.True(t, storage.IsPublicAddress(net.ParseIP("93.184.216.34")))
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		requested := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = true
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+		}))
+		defer server.Close()
+
+		err := newWebhook().Notify(ctx, Recipient{Address: server.URL}, testNotification())
+		require.ErrorContains(t, err, "unexpected status 307")
+		require.False(t, requested)
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		body := []byte(`{"title":"Standup"}`)
+		require.Equal(t, Sign([]byte(secret), "1704186000", body), Sign([]byte(secret), "1704186000", body))
+		require.NotEqual(t, Sign([]byte(secret), "1704186000", body), Sign([]byte("other"), "1704186000", body))
+		require.NotEqual(t, Sign([]byte(secret), "1704186000", body), Sign([]byte(secret), "1704186001", body))
+		require.Regexp(t, "^sha256=[0-9a-f]{64}$", Sign([]byte(secret), "1704186000", body))
+	})
+}
@@ -0,0 +1,6 @@
+package queue
+
+import "errors"
+
+// ErrNotConnected возвращается проверкой соединения с очередью, если соединение не установлено или закрыто.
+var ErrNotConnected = errors.New("queue is not connected")
@@ -1,21 +1,36 @@
 package initstorage
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 	rmqqueue "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/rmq"
+	sqlqueue "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/sql"
+	initstorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
 )
 
+// ErrMemoryQueue - очередь в памяти не связывает отдельные процессы планировщика и рассыльщика.
+var ErrMemoryQueue = errors.New(`queue type "memory" works only within one process, use "rmq" or "sql"`)
+
+// New возвращает очередь типа cfg.Queue.Type, публикация и обработка уведомлений которой
+// учитываются в метриках. Очередь в памяти используется только в тестах и здесь не поддерживается.
 func New(cfg *config.Config) (app.Queue, error) {
+	var queue app.Queue
 	switch cfg.Queue.Type {
 	case "rmq":
 		rmqConf := cfg.Queue.RMQ
-		return rmqqueue.New(cfg, GetURI(rmqConf)), nil
+		queue = rmqqueue.New(cfg, GetURI(rmqConf))
+	case "sql":
+		queue = sqlqueue.New(cfg, initstorage.GetDsn(cfg.DB.SQL))
+	case "memory":
+		return nil, ErrMemoryQueue
 	default:
 		return nil, fmt.Errorf("unknown queue type: %q", cfg.Queue.Type)
 	}
+
+	return instrumentedQueue{Queue: queue, name: cfg.Queue.Type}, nil
 }
 
 func GetURI(rmq config.RMQConf) string {
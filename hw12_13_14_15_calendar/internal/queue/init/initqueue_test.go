@@ -0,0 +1,20 @@
+package initstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Queue.Type = "memory"
+	_, err := New(cfg)
+	require.ErrorIs(t, err, ErrMemoryQueue)
+
+	cfg.Queue.Type = "kafka"
+	_, err = New(cfg)
+	require.EqualError(t, err, `unknown queue type: "kafka"`)
+}
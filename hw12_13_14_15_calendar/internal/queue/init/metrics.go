@@ -0,0 +1,31 @@
+package initstorage
+
+import (
+	"context"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// instrumentedQueue учитывает в метриках опубликованные и обработанные уведомления очереди name.
+type instrumentedQueue struct {
+	app.Queue
+	name string
+}
+
+func (q instrumentedQueue) PublishNotifications(ctx context.Context,
+	messages []storage.OutboxMessage,
+) (published int, err error) {
+	published, err = q.Queue.PublishNotifications(ctx, messages)
+	metrics.ObservePublish(q.name, published, err)
+	return published, err
+}
+
+func (q instrumentedQueue) ReadAndProcessNotifications(ctx context.Context, fn app.CallbackFunc) error {
+	return q.Queue.ReadAndProcessNotifications(ctx, func(ctx context.Context, body []byte) error {
+		err := fn(ctx, body)
+		metrics.ObserveConsume(q.name, err)
+		return err
+	})
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Поля журнала очереди.
+const (
+	fieldKey        = "key"
+	fieldRetry      = "retry"
+	fieldMaxRetries = "max_retries"
+)
+
+// LogPublished пишет в журнал публикацию уведомления для сообщения outbox message с полями
+// запроса и трассировки из ctx.
+func LogPublished(ctx context.Context, message storage.OutboxMessage) {
+	notificationLogger(ctx, NewNotification(message)).Debug("notification published")
+}
+
+// LogFailed пишет в журнал ошибку err обработки уведомления body: повтор retry уведомления
+// или отказ от него, если повторы исчерпаны.
+func LogFailed(ctx context.Context, body []byte, err error, retry, maxRetries int) {
+	var notification Notification
+	_ = json.Unmarshal(body, &notification)
+	log := notificationLogger(ctx, notification).WithFields(logger.Fields{
+		fieldRetry:      retry,
+		fieldMaxRetries: maxRetries,
+	})
+
+	if retry > maxRetries {
+		log.Error("notification is dropped after retries: ", err)
+		return
+	}
+
+	log.Warn("notification processing failed, will retry: ", err)
+}
+
+// notificationLogger возвращает логгер с полями уведомления notification.
+func notificationLogger(ctx context.Context, notification Notification) *logger.Logger {
+	fields := logger.Fields{fieldKey: notification.Key}
+	if notification.ID != uuid.Nil {
+		fields[logger.FieldEventID] = notification.ID.String()
+	}
+	if notification.UserID != uuid.Nil {
+		fields[logger.FieldUserID] = notification.UserID.String()
+	}
+
+	return logger.FromContext(ctx).WithFields(fields)
+}
@@ -0,0 +1,232 @@
+package memoryqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// Параметры очереди по умолчанию.
+const (
+	defaultCapacity   = 1000
+	defaultMaxRetries = 3
+	defaultRetryDelay = 10 * time.Second
+)
+
+// Система и имя очереди в атрибутах span обработки.
+const (
+	messagingSystem = "memory"
+	destinationName = "notifications"
+)
+
+var ErrClosed = errors.New("queue is closed")
+
+// message - сообщение очереди, контекст трассировки его публикации и количество выполненных повторов
+// его обработки.
+type message struct {
+	body        []byte
+	traceParent string
+	retries     int
+}
+
+// Queue - очередь уведомлений в памяти процесса на основе буферизованного канала. Сообщения
+// получает один из получателей в том же процессе; необработанное сообщение возвращается в очередь
+// с экспоненциально растущей задержкой. Очередь не связывает отдельные процессы планировщика
+// и рассыльщика и предназначена для тестов.
+type Queue struct {
+	messages   chan message
+	maxRetries int
+	retryDelay time.Duration
+	closed     chan struct{}
+	closeOnce  sync.Once
+
+	mu      sync.Mutex
+	retries map[*time.Timer]struct{} // Таймеры отложенных повторов
+}
+
+func (q *Queue) Connect() error {
+	return nil
+}
+
+// Close закрывает очередь: публикация и чтение сообщений завершаются ошибкой ErrClosed,
+// отложенные повторы отбрасываются с предупреждением в журнале.
+func (q *Queue) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+		if dropped := q.stopRetries(); dropped > 0 {
+			logDroppedRetries(dropped)
+		}
+	})
+	return nil
+}
+
+// stopRetries останавливает таймеры отложенных повторов и возвращает количество отброшенных повторов.
+func (q *Queue) stopRetries() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped := 0
+	for timer := range q.retries {
+		if timer.Stop() {
+			dropped++
+		}
+	}
+	q.retries = nil
+	return dropped
+}
+
+// logDroppedRetries пишет в журнал предупреждение об отброшенных при закрытии очереди повторах.
+func logDroppedRetries(dropped int) {
+	logger.FromContext(context.Background()).WithFields(logger.Fields{"dropped_retries": dropped}).
+		Warn("memory queue is closed, pending retries are dropped")
+}
+
+// Ping возвращает ErrClosed, если очередь закрыта.
+func (q *Queue) Ping(_ context.Context) error {
+	select {
+	case <-q.closed:
+		return ErrClosed
+	default:
+		return nil
+	}
+}
+
+// PublishNotifications ставит уведомления в очередь по порядку. Если очередь заполнена,
+// ждет освобождения места. Возвращает количество поставленных в очередь уведомлений.
+func (q *Queue) PublishNotifications(ctx context.Context,
+	messages []storage.OutboxMessage,
+) (published int, err error) {
+	for _, m := range messages {
+		var body []byte
+		if body, err = json.Marshal(queue.NewNotification(m)); err != nil {
+			return published, err
+		}
+
+		if err = q.push(ctx, message{body: body, traceParent: queue.TraceParent(ctx, m)}); err != nil {
+			return published, err
+		}
+		queue.LogPublished(tracing.ContextWithTraceParent(ctx, m.TraceParent), m)
+		published++
+	}
+
+	return published, nil
+}
+
+func (q *Queue) push(ctx context.Context, m message) error {
+	select {
+	case <-q.closed:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case q.messages <- m:
+		return nil
+	case <-q.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadAndProcessNotifications обрабатывает уведомления функцией fn, пока не отменен контекст.
+// Если fn вернула ошибку, сообщение возвращается в очередь после задержки, сообщение,
+// исчерпавшее повторы, отбрасывается. Возвращает управление после обработки текущего сообщения.
+func (q *Queue) ReadAndProcessNotifications(ctx context.Context, fn app.CallbackFunc) error {
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.closed:
+			return ErrClosed
+		case m := <-q.messages:
+			q.process(ctx, m, fn)
+		}
+	}
+}
+
+// process обрабатывает сообщение, продолжая трассировку его публикации, и, если обработка не удалась,
+// откладывает его повтор.
+func (q *Queue) process(ctx context.Context, m message, fn app.CallbackFunc) {
+	ctx, span := queue.StartProcessSpan(ctx, messagingSystem, destinationName, m.traceParent)
+	defer span.End()
+
+	err := fn(ctx, m.body)
+	tracing.SetError(span, err)
+	if err == nil {
+		return
+	}
+
+	m.retries++
+	queue.LogFailed(ctx, m.body, err, m.retries, q.maxRetries)
+	if m.retries > q.maxRetries {
+		return
+	}
+
+	q.retry(m)
+}
+
+// retry возвращает сообщение в очередь после задержки. Если очередь закрыта, повтор отбрасывается.
+func (q *Queue) retry(m message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.retries == nil {
+		logDroppedRetries(1)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(q.backoff(m.retries), func() {
+		q.mu.Lock()
+		delete(q.retries, timer)
+		q.mu.Unlock()
+
+		_ = q.push(context.Background(), m)
+	})
+	q.retries[timer] = struct{}{}
+}
+
+// backoff возвращает задержку перед повтором retry: RetryDelay, удваиваемая с каждым повтором.
+func (q *Queue) backoff(retry int) time.Duration {
+	return q.retryDelay << (retry - 1)
+}
+
+func New(config *config.Config) *Queue {
+	memoryConf := config.Queue.Memory
+	queue := &Queue{
+		maxRetries: memoryConf.MaxRetries,
+		retryDelay: memoryConf.RetryDelay,
+		closed:     make(chan struct{}),
+		retries:    make(map[*time.Timer]struct{}),
+	}
+
+	capacity := memoryConf.Capacity
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	queue.messages = make(chan message, capacity)
+
+	if queue.maxRetries == 0 {
+		queue.maxRetries = defaultMaxRetries
+	}
+
+	if queue.retryDelay <= 0 {
+		queue.retryDelay = defaultRetryDelay
+	}
+
+	return queue
+}
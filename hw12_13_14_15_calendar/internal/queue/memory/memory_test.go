@@ -0,0 +1,73 @@
+package memoryqueue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/queuetest"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+func TestQueue(t *testing.T) {
+	queuetest.Run(t, func(_ *testing.T) app.Queue {
+		cfg := &config.Config{}
+		cfg.Queue.Memory.MaxRetries = queuetest.MaxRetries
+		cfg.Queue.Memory.RetryDelay = queuetest.RetryDelay
+		return New(cfg)
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		q := New(&config.Config{})
+		require.Equal(t, defaultCapacity, cap(q.messages))
+		require.Equal(t, defaultMaxRetries, q.maxRetries)
+		require.Equal(t, defaultRetryDelay, q.retryDelay)
+		require.Equal(t, 40*time.Second, q.backoff(3))
+	})
+
+	t.Run("closed queue", func(t *testing.T) {
+		q := New(&config.Config{})
+		require.NoError(t, q.Close())
+		require.NoError(t, q.Close())
+
+		_, err := q.PublishNotifications(context.Background(), []storage.OutboxMessage{{Key: "key"}})
+		require.ErrorIs(t, err, ErrClosed)
+		require.ErrorIs(t, q.ReadAndProcessNotifications(context.Background(), nil), ErrClosed)
+	})
+	t.Run("close drops pending retries", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "queue.log")
+		_, err := logger.NewFromConfig(config.LoggerConf{Level: "warn", Output: file})
+		require.NoError(t, err)
+		t.Cleanup(func() { logger.New("info") })
+
+		cfg := &config.Config{}
+		cfg.Queue.Memory.RetryDelay = time.Hour
+		q := New(cfg)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, err = q.PublishNotifications(ctx, []storage.OutboxMessage{{Key: "key"}})
+		require.NoError(t, err)
+		require.NoError(t, q.ReadAndProcessNotifications(ctx, func(context.Context, []byte) error {
+			cancel()
+			return errors.New("failed")
+		}))
+		require.Len(t, q.retries, 1)
+
+		require.NoError(t, q.Close())
+		require.Nil(t, q.retries)
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "dropped_retries=1")
+		require.Contains(t, string(content), "pending retries are dropped")
+	})
+}
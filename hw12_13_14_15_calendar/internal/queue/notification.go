@@ -10,35 +10,81 @@ import (
 )
 
 type Notification struct {
-	ID        uuid.UUID         // Уникальный идентификатор события
-	UserID    uuid.UUID         // ID пользователя, владельца события
-	Title     string            // Короткий текст
-	StartTime storage.EventTime // Дата и время начала события
+	Type       storage.NotificationType    // Вид уведомления, пустое значение - напоминание
+	ID         uuid.UUID                   // Уникальный идентификатор события, для дайджеста не используется
+	ReminderID uuid.UUID                   // ID напоминания, опционально
+	UserID     uuid.UUID                   // ID пользователя, получателя уведомления
+	Channel    storage.NotificationChannel // Канал доставки напоминания, пустое значение - канал получателя
+	Title      string                      // Короткий текст
+	StartTime  storage.EventTime           // Дата и время начала события (повторения события), для дайджеста - дня
+	Recurring  bool                        // Признак повторяющегося события
+	Key        string                      // Ключ дедупликации: одно и то же уведомление может быть доставлено повторно
+	Agenda     []storage.AgendaItem        // События дайджеста
+}
+
+// NewNotification возвращает уведомление для сообщения outbox.
+func NewNotification(message storage.OutboxMessage) Notification {
+	return Notification{
+		Type:       message.Type,
+		ID:         message.EventID,
+		ReminderID: message.ReminderID,
+		UserID:     message.UserID,
+		Channel:    message.Channel,
+		Title:      message.Title,
+		StartTime:  message.StartTime,
+		Recurring:  message.Recurring,
+		Key:        message.Key,
+		Agenda:     message.Agenda,
+	}
+}
+
+// IsDigest возвращает true для ежедневного дайджеста событий.
+func (e Notification) IsDigest() bool {
+	return e.Type == storage.NotificationDigest
 }
 
 func (e Notification) MarshalJSON() ([]byte, error) {
 	var tmp struct {
-		ID        string
-		UserID    string
-		Title     string
-		StartTime string
+		Type       string `json:",omitempty"`
+		ID         string
+		ReminderID string `json:",omitempty"`
+		UserID     string
+		Channel    string `json:",omitempty"`
+		Title      string
+		StartTime  string
+		Recurring  bool
+		Key        string               `json:",omitempty"`
+		Agenda     []storage.AgendaItem `json:",omitempty"`
 	}
 
+	tmp.Type = string(e.Type)
 	tmp.ID = e.ID.String()
+	if e.ReminderID != uuid.Nil {
+		tmp.ReminderID = e.ReminderID.String()
+	}
 	tmp.UserID = e.UserID.String()
+	tmp.Channel = string(e.Channel)
 	tmp.Title = e.Title
-	tmp.StartTime = time.Time(e.StartTime).Format(time.DateTime)
+	tmp.StartTime = time.Time(e.StartTime).Format(time.RFC3339)
+	tmp.Recurring = e.Recurring
+	tmp.Key = e.Key
+	tmp.Agenda = e.Agenda
 	json, err := json.Marshal(tmp)
 	return json, err
 }
 
 func (e *Notification) UnmarshalJSON(data []byte) (err error) {
-	var startTime time.Time
 	var tmp struct {
-		ID        string
-		UserID    string
-		Title     string
-		StartTime string
+		Type       string
+		ID         string
+		ReminderID string
+		UserID     string
+		Channel    string
+		Title      string
+		StartTime  string
+		Recurring  bool
+		Key        string `json:",omitempty"`
+		Agenda     []storage.AgendaItem
 	}
 	if err = json.Unmarshal(data, &tmp); err != nil {
 		return err
@@ -49,21 +95,24 @@ func (e *Notification) UnmarshalJSON(data []byte) (err error) {
 		return err
 	}
 
-	e.UserID, err = uuid.FromString(tmp.UserID)
-	if err != nil {
-		return err
-	}
-
-	e.Title = tmp.Title
-	startTime, err = time.Parse(time.DateTime, tmp.StartTime)
-	if err != nil {
-		return err
+	if tmp.ReminderID != "" {
+		if e.ReminderID, err = uuid.FromString(tmp.ReminderID); err != nil {
+			return err
+		}
 	}
 
-	e.StartTime = storage.EventTime(startTime)
+	e.UserID, err = uuid.FromString(tmp.UserID)
 	if err != nil {
 		return err
 	}
 
+	e.Type = storage.NotificationType(tmp.Type)
+	e.Channel = storage.NotificationChannel(tmp.Channel)
+	e.Agenda = tmp.Agenda
+	e.Title = tmp.Title
+	e.Recurring = tmp.Recurring
+	e.Key = tmp.Key
+	// Время без смещения приходит от предыдущих версий планировщика и считается временем UTC.
+	e.StartTime, err = storage.ParseEventTime(tmp.StartTime, time.UTC)
 	return err
 }
@@ -0,0 +1,309 @@
+// Package queuetest содержит общие тесты, которые должна проходить каждая реализация app.Queue.
+package queuetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
+)
+
+// Параметры повторной обработки, с которыми NewQueue должна создавать очередь.
+const (
+	MaxRetries = 2
+	RetryDelay = 10 * time.Millisecond
+)
+
+// Время ожидания обработки и время, в течение которого проверяется отсутствие лишних доставок.
+const (
+	waitTimeout  = 10 * time.Second
+	waitInterval = 5 * time.Millisecond
+	quietPeriod  = 300 * time.Millisecond
+)
+
+var errProcess = errors.New("processing failed")
+
+// NewQueue возвращает новую пустую очередь с параметрами MaxRetries и RetryDelay. Очереди, созданные
+// для разных тестов, не должны обмениваться сообщениями.
+type NewQueue func(t *testing.T) app.Queue
+
+// Run проверяет очередь, созданную newQueue: порядок доставки, повторную доставку необработанных
+// сообщений, продолжение трассировки публикации при обработке и остановку получателя.
+func Run(t *testing.T, newQueue NewQueue) {
+	t.Helper()
+
+	t.Run("ordering", func(t *testing.T) {
+		q := connect(t, newQueue)
+		messages := newMessages(10)
+		publish(t, q, messages[:5])
+		publish(t, q, messages[5:])
+
+		c := startConsumer(t, q, nil)
+		defer c.stop(t)
+
+		c.waitProcessed(t, len(messages))
+		require.Equal(t, keys(messages), c.processed())
+		c.requireQuiet(t, len(messages))
+	})
+
+	t.Run("redelivery", func(t *testing.T) {
+		q := connect(t, newQueue)
+		c := startConsumer(t, q, func(_ string, attempt int) error {
+			if attempt == 1 {
+				return errProcess
+			}
+			return nil
+		})
+		defer c.stop(t)
+
+		messages := newMessages(1)
+		publish(t, q, messages)
+		c.waitProcessed(t, 1)
+		require.Equal(t, 2, c.attempts(messages[0].Key))
+		c.requireAttempts(t, messages[0].Key, 2)
+	})
+
+	t.Run("retries are exhausted", func(t *testing.T) {
+		q := connect(t, newQueue)
+		c := startConsumer(t, q, func(_ string, _ int) error {
+			return errProcess
+		})
+		defer c.stop(t)
+
+		messages := newMessages(1)
+		publish(t, q, messages)
+		require.Eventually(t, func() bool {
+			return c.attempts(messages[0].Key) == MaxRetries+1
+		}, waitTimeout, waitInterval)
+
+		c.requireAttempts(t, messages[0].Key, MaxRetries+1)
+		require.Empty(t, c.processed())
+	})
+
+	t.Run("processing continues the publish trace", func(t *testing.T) {
+		tracingtest.NewRecorder(t)
+		q := connect(t, newQueue)
+		c := startConsumer(t, q, func(_ string, attempt int) error {
+			if attempt == 1 {
+				return errProcess
+			}
+			return nil
+		})
+		defer c.stop(t)
+
+		messages := newMessages(1)
+		messages[0].TraceParent = tracingtest.TraceParent
+		publish(t, q, messages)
+		c.waitProcessed(t, 1)
+
+		spans := c.spanContexts()
+		require.Len(t, spans, 2)
+		for _, span := range spans {
+			require.Equal(t, tracingtest.TraceID, span.TraceID().String())
+			require.NotEqual(t, tracingtest.SpanID, span.SpanID().String())
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		q := connect(t, newQueue)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		first := startConsumer(t, q, func(_ string, attempt int) error {
+			if attempt == 1 {
+				close(started)
+				<-release
+			}
+			return nil
+		})
+
+		inFlight := newMessages(1)
+		publish(t, q, inFlight)
+		<-started
+
+		// Получатель останавливается только после обработки текущего сообщения.
+		first.cancel()
+		select {
+		case <-first.done:
+			require.Fail(t, "consumer stopped before processing finished")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		first.stop(t)
+		require.Equal(t, keys(inFlight), first.processed())
+
+		// Остановленный получатель не получает сообщений, и они достаются следующему.
+		pending := newMessages(3)
+		publish(t, q, pending)
+		first.requireQuiet(t, 1)
+
+		second := startConsumer(t, q, nil)
+		defer second.stop(t)
+		second.waitProcessed(t, len(pending))
+		require.Equal(t, keys(pending), second.processed())
+	})
+}
+
+func connect(t *testing.T, newQueue NewQueue) app.Queue {
+	t.Helper()
+	q := newQueue(t)
+	require.NoError(t, q.Connect())
+	t.Cleanup(func() {
+		q.Close()
+	})
+	return q
+}
+
+func publish(t *testing.T, q app.Queue, messages []storage.OutboxMessage) {
+	t.Helper()
+	published, err := q.PublishNotifications(context.Background(), messages)
+	require.NoError(t, err)
+	require.Equal(t, len(messages), published)
+}
+
+func newMessages(count int) []storage.OutboxMessage {
+	messages := make([]storage.OutboxMessage, 0, count)
+	prefix := uuid.Must(uuid.NewV4()).String()
+	for i := 0; i < count; i++ {
+		messages = append(messages, storage.OutboxMessage{
+			Type:      storage.NotificationReminder,
+			EventID:   uuid.Must(uuid.NewV4()),
+			UserID:    uuid.Must(uuid.NewV4()),
+			Title:     fmt.Sprintf("Event %d", i),
+			StartTime: storage.EventTime(time.Date(2024, time.January, 2, 10, i, 0, 0, time.UTC)),
+			Key:       fmt.Sprintf("%s:%d", prefix, i),
+		})
+	}
+
+	return messages
+}
+
+func keys(messages []storage.OutboxMessage) []string {
+	result := make([]string, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, message.Key)
+	}
+
+	return result
+}
+
+// consumer - получатель, записывающий попытки обработки, их контекст трассировки и обработанные
+// уведомления. Функция fail вызывается на каждой попытке, и если она вернула ошибку, попытка считается
+// неудачной.
+type consumer struct {
+	fail   func(key string, attempt int) error
+	cancel context.CancelFunc
+	done   chan error
+
+	mu    sync.Mutex
+	tries map[string]int
+	keys  []string
+	spans []trace.SpanContext
+}
+
+func startConsumer(t *testing.T, q app.Queue, fail func(key string, attempt int) error) *consumer {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &consumer{
+		fail:   fail,
+		cancel: cancel,
+		done:   make(chan error, 1),
+		tries:  make(map[string]int),
+	}
+
+	go func() {
+		c.done <- q.ReadAndProcessNotifications(ctx, c.process)
+	}()
+
+	return c
+}
+
+func (c *consumer) process(ctx context.Context, body []byte) error {
+	var notification queue.Notification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.spans = append(c.spans, trace.SpanContextFromContext(ctx))
+	c.tries[notification.Key]++
+	attempt := c.tries[notification.Key]
+	c.mu.Unlock()
+
+	if c.fail != nil {
+		if err := c.fail(notification.Key, attempt); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = append(c.keys, notification.Key)
+	return nil
+}
+
+// stop останавливает получателя и ждет завершения ReadAndProcessNotifications.
+func (c *consumer) stop(t *testing.T) {
+	t.Helper()
+	c.cancel()
+	select {
+	case err := <-c.done:
+		require.NoError(t, err)
+		c.done <- err
+	case <-time.After(waitTimeout):
+		require.Fail(t, "consumer did not stop")
+	}
+}
+
+func (c *consumer) processed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.keys...)
+}
+
+// spanContexts возвращает контексты трассировки всех попыток обработки по порядку.
+func (c *consumer) spanContexts() []trace.SpanContext {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]trace.SpanContext(nil), c.spans...)
+}
+
+func (c *consumer) attempts(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tries[key]
+}
+
+func (c *consumer) waitProcessed(t *testing.T, count int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return len(c.processed()) >= count
+	}, waitTimeout, waitInterval)
+}
+
+// requireAttempts проверяет, что уведомление key больше не доставляется после attempts попыток.
+func (c *consumer) requireAttempts(t *testing.T, key string, attempts int) {
+	t.Helper()
+	require.Never(t, func() bool {
+		return c.attempts(key) > attempts
+	}, quietPeriod, waitInterval)
+}
+
+// requireQuiet проверяет, что получатель не обработал больше count уведомлений.
+func (c *consumer) requireQuiet(t *testing.T, count int) {
+	t.Helper()
+	require.Never(t, func() bool {
+		return len(c.processed()) > count
+	}, quietPeriod, waitInterval)
+}
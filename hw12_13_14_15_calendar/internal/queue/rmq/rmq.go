@@ -3,23 +3,42 @@ package rmqqueue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
+// Параметры повторной обработки по умолчанию.
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 10 * time.Second
+)
+
+// retriesHeader - заголовок с номером повтора обработки сообщения.
+const retriesHeader = "x-retries"
+
+// prefetchCount ограничивает количество неподтвержденных сообщений у получателя.
+const prefetchCount = 10
+
 type Queue struct {
-	config config.Config
-	uri    string
-	conn   *amqp.Connection
+	config     config.Config
+	uri        string
+	conn       *amqp.Connection
+	maxRetries int
+	retryDelay time.Duration
 }
 
-var rmqqueue amqp.Queue
+var errPublishNacked = errors.New("publishing was not confirmed by broker")
 
 func (q *Queue) Connect() error {
 	var err error
@@ -34,100 +53,213 @@ func (q *Queue) Close() error {
 	return q.conn.Close()
 }
 
+// Ping проверяет, что соединение с брокером, открытое Connect, не закрыто.
+func (q *Queue) Ping(_ context.Context) error {
+	if q.conn == nil || q.conn.IsClosed() {
+		return queue.ErrNotConnected
+	}
+
+	return nil
+}
+
+// PublishNotifications публикует уведомления по порядку и ждет подтверждения каждого от брокера.
+// Возвращает количество подтвержденных уведомлений.
 func (q *Queue) PublishNotifications(ctx context.Context,
-	events []storage.Event,
-) (eventsOut []storage.Event, err error) {
+	messages []storage.OutboxMessage,
+) (published int, err error) {
 	connection, err := amqp.Dial(q.uri)
-	queueName := q.config.Queue.RMQ.Name
 	if err != nil {
-		return nil, fmt.Errorf("connection error: %w", err)
+		return 0, fmt.Errorf("connection error: %w", err)
 	}
 	defer connection.Close()
 
+	channel, err := q.channel(connection)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		if err = q.publish(ctx, channel, message); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// channel открывает канал в режиме подтверждения публикаций и объявляет очереди.
+func (q *Queue) channel(connection *amqp.Connection) (*amqp.Channel, error) {
 	channel, err := connection.Channel()
 	if err != nil {
 		return nil, fmt.Errorf("channel error: %w", err)
 	}
 
-	if rmqqueue, err = channel.QueueDeclare(
-		queueName, // name
-		false,     // durable
+	if err = channel.Confirm(false); err != nil {
+		return nil, fmt.Errorf("confirm mode error: %w", err)
+	}
+
+	if err = q.declare(channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// declare объявляет устойчивую очередь уведомлений, очереди отложенных повторов
+// и, если он задан, exchange для сообщений, исчерпавших повторы. Аргументы очередей не зависят
+// от настроек: повторное объявление очереди с другими аргументами брокер отклоняет с ошибкой
+// PRECONDITION_FAILED, поэтому задержка повтора задается TTL сообщения, а исчерпавшие повторы
+// сообщения публикуются в DeadLetterExchange явно.
+func (q *Queue) declare(channel *amqp.Channel) error {
+	rmqConf := q.config.Queue.RMQ
+	if rmqConf.DeadLetterExchange != "" {
+		if err := channel.ExchangeDeclare(
+			rmqConf.DeadLetterExchange, // name
+			amqp.ExchangeFanout,        // kind
+			true,                       // durable
+			false,                      // auto-delete
+			false,                      // internal
+			false,                      // noWait
+			nil,                        // arguments
+		); err != nil {
+			return fmt.Errorf("exchange declaration error: %w", err)
+		}
+
+		if err := q.declareQueue(channel, deadLetterQueue(rmqConf.Name), nil); err != nil {
+			return err
+		}
+
+		err := channel.QueueBind(deadLetterQueue(rmqConf.Name), "", rmqConf.DeadLetterExchange, false, nil)
+		if err != nil {
+			return fmt.Errorf("queue binding error: %w", err)
+		}
+	}
+
+	if err := q.declareQueue(channel, rmqConf.Name, nil); err != nil {
+		return err
+	}
+
+	// Сообщение ждет в очереди повтора, пока не истечет его TTL, и возвращается в основную очередь.
+	for retry := 1; retry <= q.maxRetries; retry++ {
+		err := q.declareQueue(channel, retryQueue(rmqConf.Name, retry), amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": rmqConf.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) declareQueue(channel *amqp.Channel, name string, arguments amqp.Table) error {
+	if _, err := channel.QueueDeclare(
+		name,      // name
+		true,      // durable
 		false,     // auto-delete
 		false,     // exclusive
 		false,     // noWait
-		nil,       // arguments
+		arguments, // arguments
 	); err != nil {
-		return nil, fmt.Errorf("queue declaration error: %w", err)
+		return fmt.Errorf("queue declaration error: %w", err)
 	}
 
-	for i, event := range events {
-		body, err := json.Marshal(GetNotificationFromEvent(event))
-		if err != nil {
-			return nil, err
-		}
-		if err = channel.PublishWithContext(
-			ctx,
-			"",            // publish to an exchange
-			rmqqueue.Name, // routing to 0 or more queues
-			// Mandatory flag tells the server how to react if a message cannot be routed to a queue.
-			// Specifically, if mandatory is set and after running the bindings
-			// the message was placed on zero queues then the message is returned to the sender (with a basic.return).
-			// If mandatory had not been set under the same circumstances the server would silently drop the message.
-			false,
-			// immediate.
-			// If there is at least one consumer connected to my queue that can take delivery of a message
-			// right this moment, deliver this message to them immediately.
-			// If there are no consumers connected then there's no point in having my message consumed later
-			// and they'll never see it. They snooze, they lose.
-			false,
-			amqp.Publishing{
-				Headers:         amqp.Table{},
-				ContentType:     "text/plain",
-				ContentEncoding: "",
-				Body:            body,
-				DeliveryMode:    amqp.Transient, // 1=non-persistent, 2=persistent
-			},
-		); err != nil {
-			return nil, fmt.Errorf("exchange publishing error: %w", err)
-		}
-		events[i].NotificationSent = true
+	return nil
+}
+
+// publish публикует уведомление для сообщения outbox, передавая контекст трассировки в заголовках.
+func (q *Queue) publish(ctx context.Context, channel *amqp.Channel, message storage.OutboxMessage) (err error) {
+	name := q.config.Queue.RMQ.Name
+	ctx, span := startPublishSpan(ctx, name, message)
+	defer func() {
+		tracing.SetError(span, err)
+		span.End()
+	}()
+
+	notification := queue.NewNotification(message)
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	headers := amqp.Table{}
+	tracing.Inject(ctx, headerCarrier(headers))
+	err = publishConfirmed(ctx, channel, "", name, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     "text/plain",
+		ContentEncoding: "",
+		MessageId:       notification.Key,
+		Body:            body,
+		DeliveryMode:    amqp.Persistent, // 1=non-persistent, 2=persistent
+	})
+	if err != nil {
+		return err
 	}
-	return events, nil
+
+	queue.LogPublished(ctx, message)
+	return nil
 }
 
-func GetNotificationFromEvent(event storage.Event) queue.Notification {
-	notification := &queue.Notification{
-		ID:        event.ID,
-		UserID:    event.UserID,
-		Title:     event.Title,
-		StartTime: event.StartTime,
+// publishConfirmed публикует сообщение в exchange с ключом routingKey и ждет подтверждения от брокера.
+// Пустой exchange направляет сообщение в очередь routingKey.
+func publishConfirmed(ctx context.Context, channel *amqp.Channel, exchange, routingKey string,
+	publishing amqp.Publishing,
+) error {
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,   // publish to an exchange
+		routingKey, // routing to 0 or more queues
+		// Mandatory flag tells the server how to react if a message cannot be routed to a queue.
+		// Specifically, if mandatory is set and after running the bindings
+		// the message was placed on zero queues then the message is returned to the sender (with a basic.return).
+		// If mandatory had not been set under the same circumstances the server would silently drop the message.
+		false,
+		// immediate.
+		// If there is at least one consumer connected to my queue that can take delivery of a message
+		// right this moment, deliver this message to them immediately.
+		// If there are no consumers connected then there's no point in having my message consumed later
+		// and they'll never see it. They snooze, they lose.
+		false,
+		publishing,
+	)
+	if err != nil {
+		return fmt.Errorf("exchange publishing error: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("publishing confirmation error: %w", err)
+	}
+
+	if !acked {
+		return errPublishNacked
 	}
-	return *notification
+
+	return nil
 }
 
+// ReadAndProcessNotifications обрабатывает уведомления функцией fn и подтверждает их вручную.
+// Если fn вернула ошибку, сообщение откладывается в очередь повтора с экспоненциально растущей
+// задержкой. Сообщение, исчерпавшее повторы, публикуется в exchange DeadLetterExchange, а если
+// он не задан, отбрасывается.
+// После отмены контекста дожидается обработки текущего сообщения; неподтвержденные сообщения
+// возвращаются в очередь при закрытии канала.
 func (q *Queue) ReadAndProcessNotifications(ctx context.Context, fn app.CallbackFunc) error {
-	queueName := q.config.Queue.RMQ.Name
-	channel, err := q.conn.Channel()
+	channel, err := q.channel(q.conn)
 	if err != nil {
-		return fmt.Errorf("channel error: %w", err)
+		return err
 	}
 
-	if rmqqueue, err = channel.QueueDeclare(
-		queueName, // name
-		false,     // durable
-		false,     // auto-delete
-		false,     // exclusive
-		false,     // noWait
-		nil,       // arguments
-	); err != nil {
-		return fmt.Errorf("queue declaration error: %w", err)
+	if err = channel.Qos(prefetchCount, 0, false); err != nil {
+		return fmt.Errorf("qos error: %w", err)
 	}
 
 	notifications, err := channel.ConsumeWithContext(
 		ctx,
-		queueName,
+		q.config.Queue.RMQ.Name,
 		"",
-		true,
+		false, // autoAck
 		false,
 		false,
 		false,
@@ -137,19 +269,134 @@ func (q *Queue) ReadAndProcessNotifications(ctx context.Context, fn app.Callback
 		return fmt.Errorf("failed open channel: %w", err)
 	}
 
+	// Канал notifications закрывается, когда отмена контекста останавливает получателя.
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for notification := range notifications {
-			fn(ctx, notification.Body)
+			q.process(ctx, channel, notification, fn)
 		}
 	}()
 
 	<-ctx.Done()
-	return nil
+	<-done
+	return channel.Close()
+}
+
+// process обрабатывает сообщение и подтверждает, откладывает или отклоняет его. Если отложить
+// сообщение не удалось, оно возвращается в очередь не раньше чем через RetryDelay, чтобы недоступный
+// брокер не получал то же сообщение снова и снова. Обработка продолжает трассировку из заголовков
+// сообщения; при повторе заголовки сохраняются, поэтому повторы попадают в ту же трассировку.
+func (q *Queue) process(ctx context.Context, channel *amqp.Channel, delivery amqp.Delivery,
+	fn app.CallbackFunc,
+) {
+	ctx, span := startProcessSpan(ctx, q.config.Queue.RMQ.Name, delivery)
+	defer span.End()
+
+	err := fn(ctx, delivery.Body)
+	tracing.SetError(span, err)
+	if err == nil {
+		_ = delivery.Ack(false)
+		return
+	}
+
+	retry := retries(delivery.Headers) + 1
+	queue.LogFailed(ctx, delivery.Body, err, retry, q.maxRetries)
+	if retry > q.maxRetries && q.config.Queue.RMQ.DeadLetterExchange == "" {
+		_ = delivery.Nack(false, false)
+		return
+	}
+
+	exchange, routingKey, publishing := q.forward(delivery, retry)
+	if err = publishConfirmed(ctx, channel, exchange, routingKey, publishing); err != nil {
+		logger.FromContext(ctx).Error("failed to postpone notification, returning it to the queue in ",
+			q.retryDelay, ": ", err)
+		q.requeueLater(ctx, delivery)
+		return
+	}
+
+	_ = delivery.Ack(false)
+}
+
+// forward возвращает exchange, ключ и сообщение для повтора retry: очередь повтора, в которой сообщение
+// ждет задержку повтора, или DeadLetterExchange, если повторы исчерпаны.
+func (q *Queue) forward(delivery amqp.Delivery, retry int) (string, string, amqp.Publishing) {
+	headers := amqp.Table{}
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+	publishing := amqp.Publishing{
+		Headers:      headers,
+		ContentType:  delivery.ContentType,
+		MessageId:    delivery.MessageId,
+		Body:         delivery.Body,
+		DeliveryMode: amqp.Persistent,
+	}
+
+	if retry > q.maxRetries {
+		return q.config.Queue.RMQ.DeadLetterExchange, "", publishing
+	}
+
+	headers[retriesHeader] = int32(retry)
+	publishing.Expiration = strconv.FormatInt(q.backoff(retry).Milliseconds(), 10)
+	return "", retryQueue(q.config.Queue.RMQ.Name, retry), publishing
+}
+
+// requeueLater возвращает сообщение в очередь через RetryDelay или сразу после отмены контекста.
+// Пока получатель ждет, он не обрабатывает другие сообщения.
+func (q *Queue) requeueLater(ctx context.Context, delivery amqp.Delivery) {
+	timer := time.NewTimer(q.retryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	_ = delivery.Nack(false, true)
+}
+
+// backoff возвращает задержку перед повтором retry: RetryDelay, удваиваемая с каждым повтором.
+func (q *Queue) backoff(retry int) time.Duration {
+	return q.retryDelay << (retry - 1)
+}
+
+// retries возвращает количество уже выполненных повторов обработки сообщения.
+func retries(headers amqp.Table) int {
+	switch value := headers[retriesHeader].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	default:
+		return 0
+	}
+}
+
+func retryQueue(name string, retry int) string {
+	return fmt.Sprintf("%s.retry.%d", name, retry)
+}
+
+func deadLetterQueue(name string) string {
+	return name + ".dead"
 }
 
 func New(config *config.Config, uri string) *Queue {
-	return &Queue{
-		config: *config,
-		uri:    uri,
+	rmqConf := config.Queue.RMQ
+	queue := &Queue{
+		config:     *config,
+		uri:        uri,
+		maxRetries: rmqConf.MaxRetries,
+		retryDelay: rmqConf.RetryDelay,
+	}
+
+	if queue.maxRetries == 0 {
+		queue.maxRetries = defaultMaxRetries
 	}
+
+	if queue.retryDelay <= 0 {
+		queue.retryDelay = defaultRetryDelay
+	}
+
+	return queue
 }
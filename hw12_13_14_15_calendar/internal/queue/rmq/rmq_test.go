@@ -0,0 +1,88 @@
+package rmqqueue
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/queuetest"
+)
+
+// testURIEnv - переменная окружения с адресом RabbitMQ. Если она не задана, тесты очереди пропускаются.
+const testURIEnv = "CALENDAR_TEST_AMQP_URI"
+
+func TestQueue(t *testing.T) {
+	uri := os.Getenv(testURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", testURIEnv)
+	}
+
+	queuetest.Run(t, func(_ *testing.T) app.Queue {
+		cfg := &config.Config{}
+		cfg.Queue.RMQ.Name = "test." + uuid.Must(uuid.NewV4()).String()
+		cfg.Queue.RMQ.MaxRetries = queuetest.MaxRetries
+		cfg.Queue.RMQ.RetryDelay = queuetest.RetryDelay
+		return New(cfg, uri)
+	})
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		q := New(&config.Config{}, "")
+		require.Equal(t, defaultMaxRetries, q.maxRetries)
+		require.Equal(t, defaultRetryDelay, q.retryDelay)
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Queue.RMQ.MaxRetries = 4
+		cfg.Queue.RMQ.RetryDelay = 5 * time.Second
+		q := New(cfg, "")
+		require.Equal(t, 4, q.maxRetries)
+		require.Equal(t, 5*time.Second, q.backoff(1))
+		require.Equal(t, 10*time.Second, q.backoff(2))
+		require.Equal(t, 40*time.Second, q.backoff(4))
+	})
+
+	t.Run("retries header", func(t *testing.T) {
+		require.Equal(t, 0, retries(nil))
+		require.Equal(t, 0, retries(amqp.Table{retriesHeader: "1"}))
+		require.Equal(t, 2, retries(amqp.Table{retriesHeader: int32(2)}))
+		require.Equal(t, 3, retries(amqp.Table{retriesHeader: int64(3)}))
+	})
+
+	t.Run("forward", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Queue.RMQ.Name = "notifications"
+		cfg.Queue.RMQ.MaxRetries = 2
+		cfg.Queue.RMQ.RetryDelay = 5 * time.Second
+		cfg.Queue.RMQ.DeadLetterExchange = "notifications.dlx"
+		q := New(cfg, "")
+		delivery := amqp.Delivery{Headers: amqp.Table{"traceparent": "00-1"}, MessageId: "key", Body: []byte("{}")}
+
+		exchange, routingKey, publishing := q.forward(delivery, 2)
+		require.Equal(t, "", exchange)
+		require.Equal(t, "notifications.retry.2", routingKey)
+		require.Equal(t, "10000", publishing.Expiration)
+		require.Equal(t, int32(2), publishing.Headers[retriesHeader])
+		require.Equal(t, "00-1", publishing.Headers["traceparent"])
+		require.Nil(t, delivery.Headers[retriesHeader])
+
+		exchange, routingKey, publishing = q.forward(delivery, 3)
+		require.Equal(t, "notifications.dlx", exchange)
+		require.Equal(t, "", routingKey)
+		require.Empty(t, publishing.Expiration)
+		require.Equal(t, []byte("{}"), publishing.Body)
+	})
+
+	t.Run("queue names", func(t *testing.T) {
+		require.Equal(t, "notifications.retry.2", retryQueue("notifications", 2))
+		require.Equal(t, "notifications.dead", deadLetterQueue("notifications"))
+	})
+}
@@ -0,0 +1,72 @@
+package rmqqueue
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+const messagingSystem = "rabbitmq"
+
+// headerCarrier читает и записывает контекст трассировки в заголовках сообщения AMQP.
+type headerCarrier amqp.Table
+
+func (c headerCarrier) Get(key string) string {
+	switch value := c[key].(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// startPublishSpan начинает span публикации сообщения outbox в очередь name. Если в сообщении сохранен
+// контекст трассировки запроса, изменившего событие, span продолжает эту трассировку и ссылается
+// на span из ctx (задание планировщика), иначе span продолжает трассировку из ctx.
+func startPublishSpan(ctx context.Context, name string, message storage.OutboxMessage) (context.Context, trace.Span) {
+	options := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystem(messagingSystem), semconv.MessagingDestinationName(name),
+			semconv.MessagingOperationPublish, semconv.MessagingMessageID(message.Key)),
+	}
+
+	if message.TraceParent != "" {
+		options = append(options, trace.WithLinks(trace.LinkFromContext(ctx)))
+		ctx = tracing.ContextWithTraceParent(ctx, message.TraceParent)
+	}
+
+	return tracing.Tracer().Start(ctx, name+" publish", options...)
+}
+
+// startProcessSpan начинает span обработки сообщения из очереди name, продолжая трассировку
+// из заголовков сообщения.
+func startProcessSpan(ctx context.Context, name string, delivery amqp.Delivery) (context.Context, trace.Span) {
+	ctx = tracing.Extract(ctx, headerCarrier(delivery.Headers))
+	return tracing.Tracer().Start(ctx, name+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingSystem(messagingSystem), semconv.MessagingDestinationName(name),
+			semconv.MessagingOperationProcess, semconv.MessagingMessageID(delivery.MessageId)))
+}
@@ -0,0 +1,67 @@
+package rmqqueue
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
+)
+
+func TestTracing(t *testing.T) {
+	t.Run("header carrier", func(t *testing.T) {
+		headers := amqp.Table{retriesHeader: int32(1), "binary": []byte("value")}
+		carrier := headerCarrier(headers)
+		carrier.Set("traceparent", tracingtest.TraceParent)
+		require.Equal(t, tracingtest.TraceParent, headers["traceparent"])
+		require.Equal(t, tracingtest.TraceParent, carrier.Get("traceparent"))
+		require.Equal(t, "value", carrier.Get("binary"))
+		require.Equal(t, "1", carrier.Get(retriesHeader))
+		require.Equal(t, "", carrier.Get("missing"))
+		require.ElementsMatch(t, []string{retriesHeader, "binary", "traceparent"}, carrier.Keys())
+	})
+
+	t.Run("publish continues the event trace and links the job", func(t *testing.T) {
+		recorder := tracingtest.NewRecorder(t)
+		ctx, job := tracing.Tracer().Start(context.Background(), "job notify")
+		message := storage.OutboxMessage{Key: "key", TraceParent: tracingtest.TraceParent}
+
+		ctx, span := startPublishSpan(ctx, "notifications", message)
+		headers := amqp.Table{}
+		tracing.Inject(ctx, headerCarrier(headers))
+		span.End()
+		job.End()
+
+		published := tracingtest.Span(t, recorder, "notifications publish")
+		require.Equal(t, trace.SpanKindProducer, published.SpanKind())
+		require.Equal(t, tracingtest.TraceID, published.SpanContext().TraceID().String())
+		require.Equal(t, tracingtest.SpanID, published.Parent().SpanID().String())
+		require.Len(t, published.Links(), 1)
+		require.Equal(t, job.SpanContext().SpanID(), published.Links()[0].SpanContext.SpanID())
+
+		_, processed := startProcessSpan(context.Background(), "notifications", amqp.Delivery{Headers: headers})
+		processed.End()
+		consumed := tracingtest.Span(t, recorder, "notifications process")
+		require.Equal(t, trace.SpanKindConsumer, consumed.SpanKind())
+		require.Equal(t, published.SpanContext().TraceID(), consumed.SpanContext().TraceID())
+		require.Equal(t, published.SpanContext().SpanID(), consumed.Parent().SpanID())
+	})
+
+	t.Run("publish without event trace continues the job trace", func(t *testing.T) {
+		recorder := tracingtest.NewRecorder(t)
+		ctx, job := tracing.Tracer().Start(context.Background(), "job digest")
+		_, span := startPublishSpan(ctx, "notifications", storage.OutboxMessage{Key: "digest"})
+		span.End()
+		job.End()
+
+		published := tracingtest.Span(t, recorder, "notifications publish")
+		require.Equal(t, job.SpanContext().TraceID(), published.SpanContext().TraceID())
+		require.Equal(t, job.SpanContext().SpanID(), published.Parent().SpanID())
+		require.Empty(t, published.Links())
+	})
+}
@@ -0,0 +1,296 @@
+package sqlqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/stdlib" // no lint
+	"github.com/jackc/pgx/v4"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// Параметры очереди по умолчанию.
+const (
+	defaultName         = "notifications"
+	defaultMaxRetries   = 3
+	defaultRetryDelay   = 10 * time.Second
+	defaultPollInterval = 5 * time.Second
+)
+
+// notifyChannel - канал LISTEN/NOTIFY, в который при публикации передается имя очереди.
+const notifyChannel = "notification_queue"
+
+// messagingSystem - система очереди в атрибутах span обработки.
+const messagingSystem = "postgresql"
+
+// Queue - очередь уведомлений в таблице PostgreSQL notification_queue. Получатель забирает сообщение
+// в транзакции (FOR UPDATE SKIP LOCKED) и удаляет его после обработки, поэтому несколько получателей
+// не обрабатывают одно сообщение одновременно, а сообщение получателя, завершившегося во время
+// обработки, достается другому. Вместе с сообщением сохраняется контекст трассировки, и обработка
+// продолжает трассировку, в которой сообщение опубликовано.
+type Queue struct {
+	dsn          string
+	name         string
+	maxRetries   int
+	retryDelay   time.Duration
+	pollInterval time.Duration
+	db           *sqlx.DB
+}
+
+func (q *Queue) Connect() error {
+	var err error
+	q.db, err = sqlx.Open("pgx", q.dsn)
+	if err != nil {
+		return fmt.Errorf("connection error: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) Close() error {
+	return q.db.Close()
+}
+
+// Ping проверяет соединение с PostgreSQL.
+func (q *Queue) Ping(ctx context.Context) error {
+	if q.db == nil {
+		return queue.ErrNotConnected
+	}
+
+	return q.db.PingContext(ctx)
+}
+
+// PublishNotifications ставит уведомления в очередь в одной транзакции и будит получателей
+// уведомлением PostgreSQL. Возвращает количество поставленных в очередь уведомлений.
+func (q *Queue) PublishNotifications(ctx context.Context,
+	messages []storage.OutboxMessage,
+) (published int, err error) {
+	tx, err := q.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, message := range messages {
+		var body []byte
+		if body, err = json.Marshal(queue.NewNotification(message)); err != nil {
+			return 0, err
+		}
+
+		query := `insert into notification_queue(queue, body, trace_parent) values($1, $2, $3)`
+		_, err = tx.ExecContext(ctx, query, q.name, string(body), queue.TraceParent(ctx, message))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Уведомление доставляется слушателям только после фиксации транзакции.
+	if _, err = tx.ExecContext(ctx, "select pg_notify($1, $2)", notifyChannel, q.name); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		queue.LogPublished(tracing.ContextWithTraceParent(ctx, message.TraceParent), message)
+	}
+
+	return len(messages), nil
+}
+
+// ReadAndProcessNotifications обрабатывает уведомления функцией fn, пока не отменен контекст.
+// Очередь проверяется при получении уведомления PostgreSQL и каждые PollInterval. Если fn вернула
+// ошибку, сообщение откладывается с экспоненциально растущей задержкой, сообщение, исчерпавшее
+// повторы, помечается dead_at. После отмены контекста дожидается обработки текущего сообщения.
+func (q *Queue) ReadAndProcessNotifications(ctx context.Context, fn app.CallbackFunc) error {
+	if err := q.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("connection error: %w", err)
+	}
+
+	wakeup := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.listen(ctx, wakeup)
+	}()
+	defer wg.Wait()
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Ошибки базы данных считаются временными: очередь проверяется повторно по таймеру.
+		for ctx.Err() == nil {
+			processed, err := q.processNext(ctx, fn)
+			if err != nil || !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// listen будит получателя через канал wakeup при публикации в очередь. Если соединение разорвано,
+// listen подключается повторно через PollInterval.
+func (q *Queue) listen(ctx context.Context, wakeup chan<- struct{}) {
+	for ctx.Err() == nil {
+		if err := q.waitNotifications(ctx, wakeup); err == nil {
+			return
+		}
+
+		timer := time.NewTimer(q.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *Queue) waitNotifications(ctx context.Context, wakeup chan<- struct{}) error {
+	conn, err := pgx.Connect(ctx, q.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "listen "+notifyChannel); err != nil {
+		return err
+	}
+
+	// Сообщения, опубликованные до подписки, забираются сразу.
+	wake(wakeup)
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if notification.Payload == q.name {
+			wake(wakeup)
+		}
+	}
+}
+
+func wake(wakeup chan<- struct{}) {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// processNext забирает самое раннее доступное сообщение и обрабатывает его функцией fn в той же
+// транзакции. Возвращает false, если доступных сообщений нет. Транзакция не зависит от отмены ctx,
+// чтобы результат обработки, начатой до остановки получателя, был сохранен. Обработка продолжает
+// трассировку, сохраненную при публикации, в том числе при повторах.
+func (q *Queue) processNext(ctx context.Context, fn app.CallbackFunc) (processed bool, err error) {
+	txCtx := context.WithoutCancel(ctx)
+	tx, err := q.db.BeginTxx(txCtx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var (
+		id          int64
+		body        string
+		retries     int
+		traceParent string
+	)
+	query := `select id, body, retries, trace_parent from notification_queue
+	          where queue = $1 and dead_at is null and available_at <= now()
+	          order by id
+	          limit 1
+	          for update skip locked`
+	err = tx.QueryRowxContext(txCtx, query, q.name).Scan(&id, &body, &retries, &traceParent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	ctx, span := queue.StartProcessSpan(ctx, messagingSystem, q.name, traceParent)
+	defer span.End()
+
+	retry := retries + 1
+	processErr := fn(ctx, []byte(body))
+	tracing.SetError(span, processErr)
+	if processErr != nil {
+		queue.LogFailed(ctx, []byte(body), processErr, retry, q.maxRetries)
+	}
+
+	switch {
+	case processErr == nil:
+		_, err = tx.ExecContext(txCtx, "delete from notification_queue where id = $1", id)
+	case retry > q.maxRetries:
+		query = `update notification_queue set retries = $2, dead_at = now() where id = $1`
+		_, err = tx.ExecContext(txCtx, query, id, retry)
+	default:
+		query = `update notification_queue
+		         set retries = $2, available_at = now() + interval '1 millisecond' * $3
+		         where id = $1`
+		_, err = tx.ExecContext(txCtx, query, id, retry, q.backoff(retry).Milliseconds())
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// backoff возвращает задержку перед повтором retry: RetryDelay, удваиваемая с каждым повтором.
+func (q *Queue) backoff(retry int) time.Duration {
+	return q.retryDelay << (retry - 1)
+}
+
+func New(config *config.Config, dsn string) *Queue {
+	sqlConf := config.Queue.SQL
+	queue := &Queue{
+		dsn:          dsn,
+		name:         sqlConf.Name,
+		maxRetries:   sqlConf.MaxRetries,
+		retryDelay:   sqlConf.RetryDelay,
+		pollInterval: sqlConf.PollInterval,
+	}
+
+	if queue.name == "" {
+		queue.name = defaultName
+	}
+
+	if queue.maxRetries == 0 {
+		queue.maxRetries = defaultMaxRetries
+	}
+
+	if queue.retryDelay <= 0 {
+		queue.retryDelay = defaultRetryDelay
+	}
+
+	if queue.pollInterval <= 0 {
+		queue.pollInterval = defaultPollInterval
+	}
+
+	return queue
+}
@@ -0,0 +1,43 @@
+package sqlqueue
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/queuetest"
+)
+
+// testDSNEnv - переменная окружения с адресом PostgreSQL с примененными миграциями.
+// Если она не задана, тесты очереди пропускаются.
+const testDSNEnv = "CALENDAR_TEST_DSN"
+
+func TestQueue(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	queuetest.Run(t, func(_ *testing.T) app.Queue {
+		cfg := &config.Config{}
+		cfg.Queue.SQL.Name = "test_" + uuid.Must(uuid.NewV4()).String()
+		cfg.Queue.SQL.MaxRetries = queuetest.MaxRetries
+		cfg.Queue.SQL.RetryDelay = queuetest.RetryDelay
+		cfg.Queue.SQL.PollInterval = 20 * time.Millisecond
+		return New(cfg, dsn)
+	})
+}
+
+func TestNew(t *testing.T) {
+	q := New(&config.Config{}, "")
+	require.Equal(t, defaultName, q.name)
+	require.Equal(t, defaultMaxRetries, q.maxRetries)
+	require.Equal(t, defaultRetryDelay, q.retryDelay)
+	require.Equal(t, defaultPollInterval, q.pollInterval)
+	require.Equal(t, 20*time.Second, q.backoff(2))
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// TraceParent возвращает контекст трассировки, который сохраняется вместе с уведомлением для сообщения
+// outbox message: контекст запроса, изменившего событие, если он сохранен в сообщении, иначе контекст
+// из ctx (задание планировщика).
+func TraceParent(ctx context.Context, message storage.OutboxMessage) string {
+	if message.TraceParent != "" {
+		return message.TraceParent
+	}
+
+	return tracing.TraceParent(ctx)
+}
+
+// StartProcessSpan начинает span обработки уведомления из очереди name системы system, продолжая
+// трассировку traceParent, сохраненную при публикации.
+func StartProcessSpan(ctx context.Context, system, name, traceParent string) (context.Context, trace.Span) {
+	ctx = tracing.ContextWithTraceParent(ctx, traceParent)
+	return tracing.Tracer().Start(ctx, name+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingSystem(system), semconv.MessagingDestinationName(name),
+			semconv.MessagingOperationProcess))
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+)
+
+// AdminServer - HTTP-сервер администрирования планировщика. GET /jobs возвращает состояние
+// всех заданий, GET /jobs/{name} - состояние одного задания, GET /leader - является ли экземпляр ведущим,
+// GET /metrics - метрики Prometheus, GET /healthz и GET /readyz - проверки живости и готовности.
+type AdminServer struct {
+	logger    Logger
+	scheduler *Scheduler
+	server    *http.Server
+}
+
+// LeaderStatus - ответ GET /leader.
+type LeaderStatus struct {
+	Leader bool `json:"leader"`
+}
+
+func NewAdminServer(logger Logger, scheduler *Scheduler, cfg config.ServerConf) *AdminServer {
+	a := &AdminServer{
+		logger:    logger,
+		scheduler: scheduler,
+	}
+
+	a.server = &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           a.routes(),
+		ReadHeaderTimeout: time.Second * 5,
+	}
+
+	return a
+}
+
+// routes возвращает маршрутизатор со всеми обработчиками сервера.
+func (a *AdminServer) routes() *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/jobs", a.listJobsHandler).Methods("GET")
+	router.HandleFunc("/jobs/{name}", a.getJobHandler).Methods("GET")
+	router.HandleFunc("/leader", a.leaderHandler).Methods("GET")
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	router.Handle(health.LivenessPath, health.LivenessHandler()).Methods("GET")
+	router.Handle(health.ReadinessPath, health.ReadinessHandler(a.scheduler.readinessChecks()...)).Methods("GET")
+	return router
+}
+
+// Start принимает запросы, пока сервер не остановлен методом Stop.
+func (a *AdminServer) Start() error {
+	err := a.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (a *AdminServer) Stop(ctx context.Context) error {
+	return a.server.Shutdown(ctx)
+}
+
+// List jobs handler.
+func (a *AdminServer) listJobsHandler(w http.ResponseWriter, _ *http.Request) {
+	a.writeJSON(http.StatusOK, a.scheduler.Jobs(), w)
+}
+
+// Get job handler.
+func (a *AdminServer) getJobHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	for _, status := range a.scheduler.Jobs() {
+		if status.Name == name {
+			a.writeJSON(http.StatusOK, status, w)
+			return
+		}
+	}
+
+	a.writeJSON(http.StatusNotFound, map[string]string{"message": "job not found"}, w)
+}
+
+// Leader handler.
+func (a *AdminServer) leaderHandler(w http.ResponseWriter, _ *http.Request) {
+	a.writeJSON(http.StatusOK, LeaderStatus{Leader: a.scheduler.IsLeader()}, w)
+}
+
+func (a *AdminServer) writeJSON(status int, v interface{}, w http.ResponseWriter) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		a.logger.Error(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(res); err != nil {
+		a.logger.Error(err)
+	}
+}
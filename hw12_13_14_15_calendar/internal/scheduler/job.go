@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+// Параметры заданий по умолчанию.
+const (
+	defaultJobSchedule = "@every 1m"
+	defaultJobTimeout  = time.Minute
+)
+
+// Результаты выполнения задания.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeTimeout = "timeout"
+)
+
+// Имена заданий планировщика.
+const (
+	JobPurge  = "purge"
+	JobNotify = "notify"
+	JobDigest = "digest"
+)
+
+// errJobRunning возвращается, если предыдущий запуск задания еще не завершился.
+var errJobRunning = errors.New("previous run is still in progress")
+
+// JobFunc выполняет задание и возвращает количество обработанных объектов.
+type JobFunc func(ctx context.Context) (processed int, err error)
+
+// JobStatus - состояние задания и результат его последнего запуска.
+type JobStatus struct {
+	Name          string     `json:"name"`
+	Schedule      string     `json:"schedule"`
+	Running       bool       `json:"running"`
+	NextRun       *time.Time `json:"nextRun,omitempty"`
+	LastStart     *time.Time `json:"lastStart,omitempty"`
+	LastFinish    *time.Time `json:"lastFinish,omitempty"`
+	LastDuration  string     `json:"lastDuration,omitempty"`
+	LastOutcome   string     `json:"lastOutcome,omitempty"` // OutcomeSuccess, OutcomeFailure или OutcomeTimeout
+	LastError     string     `json:"lastError,omitempty"`
+	LastProcessed int        `json:"lastProcessed"`
+	Runs          int        `json:"runs"`     // Количество завершенных запусков
+	Failures      int        `json:"failures"` // Количество запусков, завершившихся ошибкой или таймаутом
+	Skipped       int        `json:"skipped"`  // Количество запусков, пропущенных из-за незавершенного предыдущего
+}
+
+// job - задание планировщика. Запуски одного задания не пересекаются: запуск, наступивший
+// до завершения предыдущего, пропускается.
+type job struct {
+	name     string
+	schedule cron.Schedule
+	jitter   time.Duration
+	timeout  time.Duration
+	run      JobFunc
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+func newJob(name string, cfg config.JobConf, run JobFunc) (*job, error) {
+	spec := cfg.Schedule
+	if spec == "" {
+		spec = defaultJobSchedule
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: invalid schedule %q: %w", name, spec, err)
+	}
+
+	if cfg.Jitter < 0 {
+		return nil, fmt.Errorf("job %s: jitter must not be negative", name)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultJobTimeout
+	}
+
+	return &job{
+		name:     name,
+		schedule: schedule,
+		jitter:   cfg.Jitter,
+		timeout:  timeout,
+		run:      run,
+		status:   JobStatus{Name: name, Schedule: spec},
+	}, nil
+}
+
+// next возвращает время следующего запуска после now с учетом случайной задержки.
+func (j *job) next(now time.Time) time.Time {
+	next := j.schedule.Next(now)
+	if j.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(j.jitter)))) //nolint:gosec
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.NextRun = &next
+	return next
+}
+
+// start отмечает начало запуска. Возвращает errJobRunning, если предыдущий запуск не завершился.
+func (j *job) start(now time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.status.Running {
+		j.status.Skipped++
+		return errJobRunning
+	}
+
+	j.status.Running = true
+	j.status.LastStart = &now
+	return nil
+}
+
+// finish записывает результат запуска, начатого в start.
+func (j *job) finish(start, now time.Time, processed int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status.Running = false
+	j.status.LastFinish = &now
+	j.status.LastDuration = now.Sub(start).String()
+	j.status.LastProcessed = processed
+	j.status.Runs++
+	j.status.LastOutcome = OutcomeSuccess
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastOutcome = OutcomeFailure
+		if errors.Is(err, context.DeadlineExceeded) {
+			j.status.LastOutcome = OutcomeTimeout
+		}
+		j.status.LastError = err.Error()
+	}
+}
+
+// Status возвращает копию состояния задания.
+func (j *job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
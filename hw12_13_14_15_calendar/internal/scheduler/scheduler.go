@@ -2,19 +2,48 @@ package scheduler
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gofrs/uuid"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
+// errElectionNotRunning - состояние выбора ведущего до запуска Start и после его завершения.
+var errElectionNotRunning = errors.New("leader election is not running")
+
+// relayBatchSize - количество сообщений outbox, публикуемых за одну транзакцию.
+const relayBatchSize = 100
+
+// Параметры выбора ведущего экземпляра по умолчанию.
+const (
+	defaultLeaderLock          = "calendar_scheduler"
+	defaultLeaderRetryInterval = 5 * time.Second
+	unlockTimeout              = 3 * time.Second
+)
+
+// Scheduler запускает задания по расписанию cron. Каждое задание планируется независимо,
+// выполняется с таймаутом и не запускается повторно, пока не завершился предыдущий запуск.
+// Если запущено несколько экземпляров планировщика, задания выполняет только ведущий - экземпляр,
+// захвативший блокировку в хранилище.
 type Scheduler struct {
 	purgeIntervalDays int
 	logger            Logger
 	app               Application
 	queue             QueueApplication
+	jobs              []*job
+	runs              sync.WaitGroup
+	now               func() time.Time
+	lockName          string
+	retryInterval     time.Duration
+	leader            atomic.Bool
+	electionMu        sync.Mutex
+	electionErr       error // Ошибка последней попытки захватить блокировку, кроме storage.ErrLocked
 }
 
 type Logger interface {
@@ -26,70 +55,281 @@ type Logger interface {
 }
 
 type Application interface {
-	SelectEventsToNotify(ctx context.Context) ([]storage.Event, error)
+	Ping(ctx context.Context) error
+	EnqueueNotifications(ctx context.Context) (enqueued int, err error)
+	EnqueueDigests(ctx context.Context, now time.Time) (enqueued int, err error)
+	RelayNotifications(ctx context.Context, limit int, publish storage.PublishFunc) (published int, err error)
 	PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error)
-	UpdateEvent(ctx context.Context, ID, userID uuid.UUID, title, description string, startTime,
-		finishTime storage.EventTime, notifyBefore int, notificationSent bool) error
+	TryLock(ctx context.Context, name string) (storage.Lock, error)
 }
 
 type QueueApplication interface {
-	PublishNotifications(ctx context.Context, events []storage.Event) (eventsOut []storage.Event, err error)
+	Ping(ctx context.Context) error
+	PublishNotifications(ctx context.Context, messages []storage.OutboxMessage) (published int, err error)
 }
 
-func New(logger Logger, app Application, queue QueueApplication, cfg *config.Config) *Scheduler {
-	return &Scheduler{
+// New возвращает планировщик заданий purge, notify и digest. Возвращает ошибку, если расписание
+// задания задано неверно.
+func New(logger Logger, app Application, queue QueueApplication, cfg *config.Config) (*Scheduler, error) {
+	s := &Scheduler{
 		purgeIntervalDays: cfg.Scheduler.PurgeIntervalDays,
 		logger:            logger,
 		app:               app,
 		queue:             queue,
+		now:               time.Now,
+		lockName:          cfg.Scheduler.Leader.Lock,
+		retryInterval:     cfg.Scheduler.Leader.RetryInterval,
+		electionErr:       errElectionNotRunning,
+	}
+
+	if s.lockName == "" {
+		s.lockName = defaultLeaderLock
+	}
+
+	if s.retryInterval <= 0 {
+		s.retryInterval = defaultLeaderRetryInterval
 	}
+
+	jobs := cfg.Scheduler.Jobs
+	for _, j := range []struct {
+		name string
+		cfg  config.JobConf
+		run  JobFunc
+	}{
+		{name: JobPurge, cfg: jobs.Purge, run: s.purgeEvents},
+		{name: JobNotify, cfg: jobs.Notify, run: s.selectEventsToNotify},
+		{name: JobDigest, cfg: jobs.Digest, run: s.enqueueDigests},
+	} {
+		if err := s.addJob(j.name, j.cfg, j.run); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
 }
 
+func (s *Scheduler) addJob(name string, cfg config.JobConf, run JobFunc) error {
+	j, err := newJob(name, cfg, run)
+	if err != nil {
+		return err
+	}
+
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+// Start пытается стать ведущим экземпляром и, пока удерживает блокировку, запускает задания
+// по расписанию. Если блокировка потеряна, выполняющиеся задания отменяются и Start снова пытается
+// ее захватить. Start блокируется до отмены контекста и дожидается завершения выполняющихся заданий.
 func (s *Scheduler) Start(ctx context.Context) error {
-	ticker := time.NewTicker(time.Minute)
-	stop := make(chan bool)
+	defer s.setElectionErr(errElectionNotRunning)
+
+	for {
+		lock, err := s.app.TryLock(ctx, s.lockName)
+		switch {
+		case err == nil:
+			s.setElectionErr(nil)
+			s.lead(ctx, lock)
+		case errors.Is(err, storage.ErrLocked):
+			s.setElectionErr(nil)
+			s.logger.Debug("scheduler is a follower: " + err.Error())
+		default:
+			s.setElectionErr(err)
+			s.logger.Error("failed to acquire scheduler leadership: " + err.Error())
+		}
+
+		timer := time.NewTimer(s.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// IsLeader сообщает, является ли экземпляр ведущим.
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+func (s *Scheduler) setElectionErr(err error) {
+	s.electionMu.Lock()
+	defer s.electionMu.Unlock()
+	s.electionErr = err
+}
+
+// checkElection возвращает ошибку, если выбор ведущего не запущен или последняя попытка захватить
+// блокировку завершилась ошибкой. Ведомый экземпляр, блокировку которого удерживает ведущий, готов.
+func (s *Scheduler) checkElection(_ context.Context) error {
+	s.electionMu.Lock()
+	defer s.electionMu.Unlock()
+	return s.electionErr
+}
+
+// readinessChecks возвращает проверки готовности планировщика: доступность хранилища и очереди
+// и состояние выбора ведущего.
+func (s *Scheduler) readinessChecks() []health.Check {
+	return []health.Check{
+		{Name: "storage", Check: s.app.Ping},
+		{Name: "queue", Check: s.queue.Ping},
+		{Name: "leadership", Check: s.checkElection},
+	}
+}
+
+// lead запускает задания, пока не отменен контекст или не потеряна блокировка lock,
+// затем дожидается завершения заданий и освобождает блокировку.
+func (s *Scheduler) lead(ctx context.Context, lock storage.Lock) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	go func() {
-		defer func() { stop <- true }()
-		for {
-			select {
-			case <-ticker.C:
-				s.purgeEvents(ctx)
-				s.selectEventsToNotify(ctx)
-			case <-stop:
-				return
-			}
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-ctx.Done():
 		}
 	}()
 
-	<-ctx.Done()
-	fmt.Println(ctx.Err())
-	ticker.Stop()
-	stop <- true
-	<-stop
-	return nil
+	s.leader.Store(true)
+	s.logger.Info("scheduler became the leader")
+
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.schedule(ctx, j)
+		}(j)
+	}
+
+	wg.Wait()
+	s.runs.Wait()
+	s.leader.Store(false)
+
+	select {
+	case <-lock.Lost():
+		s.logger.Warn("scheduler lost the leadership")
+	default:
+	}
+
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), unlockTimeout)
+	defer unlockCancel()
+	if err := lock.Unlock(unlockCtx); err != nil {
+		s.logger.Error("failed to release scheduler leadership: " + err.Error())
+	}
 }
 
-func (s *Scheduler) purgeEvents(ctx context.Context) {
-	purgedEvents, err := s.app.PurgeEvents(ctx, s.purgeIntervalDays)
-	if err != nil {
-		s.logger.Error(err)
-		return
+// Jobs возвращает состояние всех заданий.
+func (s *Scheduler) Jobs() []JobStatus {
+	result := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		result = append(result, j.Status())
 	}
 
-	s.logger.Infof("purge events: %v events purged", purgedEvents)
+	return result
 }
 
-func (s *Scheduler) selectEventsToNotify(ctx context.Context) {
-	events, err := s.app.SelectEventsToNotify(ctx)
+// schedule запускает задание в наступившее по расписанию время, пока не отменен контекст.
+// Задание выполняется в отдельной горутине, чтобы долгий запуск не сдвигал расписание.
+func (s *Scheduler) schedule(ctx context.Context, j *job) {
+	for {
+		timer := time.NewTimer(j.next(s.now()).Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runs.Add(1)
+			go func() {
+				defer s.runs.Done()
+				s.runJob(ctx, j)
+			}()
+		}
+	}
+}
+
+// runJob выполняет задание с таймаутом в отдельной трассировке и записывает результат. Если предыдущий запуск задания
+// еще не завершился, запуск пропускается.
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	start := s.now()
+	if err := j.start(start); err != nil {
+		s.logger.Warn("job " + j.name + " skipped: " + err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	ctx, span := tracing.Tracer().Start(ctx, "job "+j.name)
+	defer span.End()
+
+	processed, err := j.run(ctx)
+	if err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = ctx.Err()
+	}
+	finish := s.now()
+	j.finish(start, finish, processed, err)
+	tracing.SetError(span, err)
+
+	status := j.Status()
+	metrics.ObserveJobRun(j.name, status.LastOutcome, finish.Sub(start))
 	if err != nil {
-		s.logger.Error(err)
+		s.logger.Error("job " + j.name + " " + status.LastOutcome + ": " + err.Error())
 		return
 	}
 
-	s.logger.Infof("select events to notify: %v events selected", len(events))
-	_, err = s.queue.PublishNotifications(ctx, events)
+	s.logger.Infof("job %s finished in %s: %v processed", j.name, status.LastDuration, processed)
+}
+
+func (s *Scheduler) purgeEvents(ctx context.Context) (int, error) {
+	purgedEvents, err := s.app.PurgeEvents(ctx, s.purgeIntervalDays)
 	if err != nil {
-		s.logger.Error(err)
+		return 0, err
 	}
+
+	metrics.AddPurgedEvents(purgedEvents)
+	s.logger.Infof("purge events: %v events purged", purgedEvents)
+	return int(purgedEvents), nil
+}
+
+// enqueueDigests ставит в outbox ежедневные дайджесты пользователям, у которых наступило время
+// дайджеста, и публикует outbox в очередь. Дайджесты остальных пользователей публикуются и при ошибках
+// отдельных пользователей, ошибки записываются в журнал как ошибка запуска задания.
+func (s *Scheduler) enqueueDigests(ctx context.Context) (int, error) {
+	enqueued, err := s.app.EnqueueDigests(ctx, s.now())
+	s.logger.Infof("enqueue digests: %v digests enqueued", enqueued)
+
+	_, relayErr := s.relayNotifications(ctx)
+	return enqueued, errors.Join(err, relayErr)
+}
+
+// selectEventsToNotify ставит уведомления о наступающих событиях в outbox и публикует outbox в очередь.
+// Если публикация не удалась, уведомления остаются в outbox до следующего запуска.
+func (s *Scheduler) selectEventsToNotify(ctx context.Context) (int, error) {
+	enqueued, err := s.app.EnqueueNotifications(ctx)
+	if err == nil {
+		s.logger.Infof("select events to notify: %v notifications enqueued", enqueued)
+	}
+
+	_, relayErr := s.relayNotifications(ctx)
+	return enqueued, errors.Join(err, relayErr)
+}
+
+func (s *Scheduler) relayNotifications(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := s.app.RelayNotifications(ctx, relayBatchSize, s.queue.PublishNotifications)
+		total += published
+		if err != nil {
+			return total, err
+		}
+
+		if published < relayBatchSize {
+			break
+		}
+	}
+
+	s.logger.Infof("relay notifications: %v notifications published", total)
+	return total, nil
 }
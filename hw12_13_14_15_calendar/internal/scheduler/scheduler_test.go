@@ -0,0 +1,551 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/memory"
+	sqlstorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/sql"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
+)
+
+// testDSNEnv - переменная окружения с адресом PostgreSQL с примененными миграциями.
+// Если она не задана, тесты SQL-хранилища пропускаются.
+const testDSNEnv = "CALENDAR_TEST_DSN"
+
+var (
+	errPublish = errors.New("publish error")
+	errStorage = errors.New("storage is unavailable")
+)
+
+// fakeApp считает вызовы заданий планировщика. Если задана ошибка pingErr, хранилище недоступно:
+// проверка соединения и захват блокировки завершаются этой ошибкой.
+type fakeApp struct {
+	purged    atomic.Int64
+	relayErr  error
+	pingErr   error
+	enqueued  int
+	relayRuns atomic.Int64
+
+	mu     sync.Mutex
+	lock   *fakeLock
+	locked int
+}
+
+// fakeLock - блокировка, которую тест может потерять вызовом Unlock.
+type fakeLock struct {
+	once sync.Once
+	lost chan struct{}
+}
+
+func (l *fakeLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *fakeLock) Unlock(_ context.Context) error {
+	l.once.Do(func() { close(l.lost) })
+	return nil
+}
+
+func (a *fakeApp) Ping(_ context.Context) error {
+	return a.pingErr
+}
+
+func (a *fakeApp) TryLock(_ context.Context, _ string) (storage.Lock, error) {
+	if a.pingErr != nil {
+		return nil, a.pingErr
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lock = &fakeLock{lost: make(chan struct{})}
+	a.locked++
+	return a.lock, nil
+}
+
+// acquired возвращает последнюю выданную блокировку и количество захватов.
+func (a *fakeApp) acquired() (*fakeLock, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lock, a.locked
+}
+
+func (a *fakeApp) EnqueueNotifications(_ context.Context) (int, error) {
+	return a.enqueued, nil
+}
+
+func (a *fakeApp) EnqueueDigests(_ context.Context, _ time.Time) (int, error) {
+	return a.enqueued, nil
+}
+
+func (a *fakeApp) RelayNotifications(ctx context.Context, _ int, publish storage.PublishFunc) (int, error) {
+	a.relayRuns.Add(1)
+	if a.relayErr != nil {
+		return 0, a.relayErr
+	}
+
+	return publish(ctx, nil)
+}
+
+func (a *fakeApp) PurgeEvents(_ context.Context, _ int) (int64, error) {
+	return a.purged.Add(1), nil
+}
+
+type fakeQueue struct{}
+
+func (fakeQueue) Ping(_ context.Context) error {
+	return nil
+}
+
+func (fakeQueue) PublishNotifications(_ context.Context, messages []storage.OutboxMessage) (int, error) {
+	return len(messages), nil
+}
+
+// everySchedule - расписание с интервалом меньше секунды, которого не допускает cron.
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+func newScheduler(t *testing.T, app Application, cfg *config.Config) *Scheduler {
+	t.Helper()
+	s, err := New(logger.New("error"), app, fakeQueue{}, cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func TestNew(t *testing.T) {
+	t.Run("default schedule", func(t *testing.T) {
+		s := newScheduler(t, &fakeApp{}, &config.Config{})
+		jobs := s.Jobs()
+		require.Len(t, jobs, 3)
+		for _, status := range jobs {
+			require.Equal(t, defaultJobSchedule, status.Schedule)
+			require.Nil(t, status.LastStart)
+		}
+		require.Equal(t, defaultJobTimeout, s.jobs[0].timeout)
+	})
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Scheduler.Jobs.Digest.Schedule = "every morning"
+		_, err := New(logger.New("error"), &fakeApp{}, fakeQueue{}, cfg)
+		require.ErrorContains(t, err, "job digest")
+	})
+
+	t.Run("negative jitter", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Scheduler.Jobs.Purge.Jitter = -time.Second
+		_, err := New(logger.New("error"), &fakeApp{}, fakeQueue{}, cfg)
+		require.Error(t, err)
+	})
+
+	t.Run("next run with jitter", func(t *testing.T) {
+		j, err := newJob("test", config.JobConf{Schedule: "0 8 * * *", Jitter: time.Minute}, nil)
+		require.NoError(t, err)
+
+		now := time.Date(2024, time.January, 2, 7, 0, 0, 0, time.Local)
+		for i := 0; i < 10; i++ {
+			next := j.next(now)
+			require.False(t, next.Before(time.Date(2024, time.January, 2, 8, 0, 0, 0, time.Local)))
+			require.True(t, next.Before(time.Date(2024, time.January, 2, 8, 1, 0, 0, time.Local)))
+			require.Equal(t, next, *j.Status().NextRun)
+		}
+	})
+}
+
+func TestRunJob(t *testing.T) {
+	ctx := context.Background()
+	s := newScheduler(t, &fakeApp{}, &config.Config{})
+
+	t.Run("overlapping run is skipped", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		j, err := newJob("slow", config.JobConf{}, func(_ context.Context) (int, error) {
+			close(started)
+			<-release
+			return 7, nil
+		})
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.runJob(ctx, j)
+		}()
+
+		<-started
+		require.True(t, j.Status().Running)
+		s.runJob(ctx, j)
+		close(release)
+		<-done
+
+		status := j.Status()
+		require.False(t, status.Running)
+		require.Equal(t, 1, status.Runs)
+		require.Equal(t, 1, status.Skipped)
+		require.Equal(t, OutcomeSuccess, status.LastOutcome)
+		require.Equal(t, 7, status.LastProcessed)
+		require.NotNil(t, status.LastFinish)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		for name, run := range map[string]JobFunc{
+			"job returns context error": func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+			"job ignores context": func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				return 1, nil
+			},
+		} {
+			j, err := newJob("timeout", config.JobConf{Timeout: 10 * time.Millisecond}, run)
+			require.NoError(t, err)
+
+			s.runJob(ctx, j)
+			status := j.Status()
+			require.Equal(t, OutcomeTimeout, status.LastOutcome, name)
+			require.Equal(t, 1, status.Failures, name)
+			require.NotEmpty(t, status.LastError, name)
+		}
+	})
+
+	t.Run("failure is reset by success", func(t *testing.T) {
+		fail := true
+		j, err := newJob("flaky", config.JobConf{}, func(_ context.Context) (int, error) {
+			if fail {
+				return 0, errPublish
+			}
+			return 1, nil
+		})
+		require.NoError(t, err)
+
+		s.runJob(ctx, j)
+		require.Equal(t, OutcomeFailure, j.Status().LastOutcome)
+		require.Equal(t, errPublish.Error(), j.Status().LastError)
+
+		fail = false
+		s.runJob(ctx, j)
+		status := j.Status()
+		require.Equal(t, OutcomeSuccess, status.LastOutcome)
+		require.Empty(t, status.LastError)
+		require.Equal(t, 2, status.Runs)
+		require.Equal(t, 1, status.Failures)
+	})
+
+	t.Run("run is traced", func(t *testing.T) {
+		recorder := tracingtest.NewRecorder(t)
+		var jobSpan trace.SpanContext
+		j, err := newJob("traced", config.JobConf{}, func(ctx context.Context) (int, error) {
+			jobSpan = trace.SpanContextFromContext(ctx)
+			return 0, errPublish
+		})
+		require.NoError(t, err)
+
+		s.runJob(ctx, j)
+		span := tracingtest.Span(t, recorder, "job traced")
+		require.Equal(t, jobSpan, span.SpanContext())
+		require.False(t, span.Parent().IsValid())
+		require.Equal(t, codes.Error, span.Status().Code)
+		require.Equal(t, errPublish.Error(), span.Status().Description)
+	})
+}
+
+func TestJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("notify job reports relay error", func(t *testing.T) {
+		app := &fakeApp{enqueued: 2, relayErr: errPublish}
+		s := newScheduler(t, app, &config.Config{})
+		enqueued, err := s.selectEventsToNotify(ctx)
+		require.ErrorIs(t, err, errPublish)
+		require.Equal(t, 2, enqueued)
+
+		enqueued, err = s.enqueueDigests(ctx)
+		require.ErrorIs(t, err, errPublish)
+		require.Equal(t, 2, enqueued)
+		require.Equal(t, int64(2), app.relayRuns.Load())
+	})
+}
+
+func TestStart(t *testing.T) {
+	app := &fakeApp{}
+	s := newScheduler(t, app, &config.Config{})
+	for _, j := range s.jobs {
+		j.schedule = everySchedule(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		for _, status := range s.Jobs() {
+			if status.Runs < 2 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 5*time.Millisecond)
+
+	require.True(t, s.IsLeader())
+	cancel()
+	require.NoError(t, <-done)
+	require.Positive(t, app.purged.Load())
+	require.False(t, s.IsLeader())
+	for _, status := range s.Jobs() {
+		require.False(t, status.Running, status.Name)
+	}
+
+	lock, _ := app.acquired()
+	require.Eventually(t, func() bool {
+		select {
+		case <-lock.Lost():
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+}
+
+// startScheduler запускает планировщик с заданиями, которые выполняются каждые 5ms и только
+// считают запуски, и возвращает функцию его остановки.
+func startScheduler(t *testing.T, app Application, cfg *config.Config) (*Scheduler, func()) {
+	t.Helper()
+	s := newScheduler(t, app, cfg)
+	for _, j := range s.jobs {
+		j.schedule = everySchedule(5 * time.Millisecond)
+		j.run = func(_ context.Context) (int, error) {
+			return 0, nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Start(ctx)
+	}()
+
+	return s, func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+// runs возвращает общее количество запусков заданий планировщика.
+func runs(s *Scheduler) int {
+	total := 0
+	for _, status := range s.Jobs() {
+		total += status.Runs
+	}
+
+	return total
+}
+
+func TestLeaderElection(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scheduler.Leader.Lock = "calendar_scheduler_test"
+	cfg.Scheduler.Leader.RetryInterval = 10 * time.Millisecond
+
+	// Каждый экземпляр планировщика работает со своим приложением, как реплики планировщика.
+	test := func(t *testing.T, firstApp, secondApp Application) {
+		t.Helper()
+		first, stopFirst := startScheduler(t, firstApp, cfg)
+		require.Eventually(t, func() bool {
+			return first.IsLeader() && runs(first) > 0
+		}, 5*time.Second, 5*time.Millisecond)
+
+		second, stopSecond := startScheduler(t, secondApp, cfg)
+		defer stopSecond()
+
+		require.Never(t, second.IsLeader, 100*time.Millisecond, 10*time.Millisecond)
+		require.Zero(t, runs(second))
+		require.True(t, first.IsLeader())
+
+		stopFirst()
+		require.False(t, first.IsLeader())
+		firstRuns := runs(first)
+
+		require.Eventually(t, func() bool {
+			return second.IsLeader() && runs(second) > 0
+		}, 5*time.Second, 5*time.Millisecond)
+		require.Equal(t, firstRuns, runs(first))
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		memoryStorage := memorystorage.New()
+		test(t, app.New(memoryStorage), app.New(memoryStorage))
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		dsn := os.Getenv(testDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", testDSNEnv)
+		}
+
+		firstStorage := sqlstorage.New(&config.Config{}, dsn)
+		require.NoError(t, firstStorage.Connect())
+		defer firstStorage.Close()
+
+		secondStorage := sqlstorage.New(&config.Config{}, dsn)
+		require.NoError(t, secondStorage.Connect())
+		defer secondStorage.Close()
+
+		test(t, app.New(firstStorage), app.New(secondStorage))
+	})
+
+	t.Run("lost lock is re-acquired", func(t *testing.T) {
+		calendar := &fakeApp{}
+		s, stop := startScheduler(t, calendar, cfg)
+		defer stop()
+
+		require.Eventually(t, s.IsLeader, 5*time.Second, 5*time.Millisecond)
+		lock, locked := calendar.acquired()
+		require.Equal(t, 1, locked)
+		require.NoError(t, lock.Unlock(context.Background()))
+
+		require.Eventually(t, func() bool {
+			_, locked := calendar.acquired()
+			return locked == 2 && s.IsLeader()
+		}, 5*time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestReadiness(t *testing.T) {
+	check := func(t *testing.T, s *Scheduler) health.Report {
+		t.Helper()
+		return health.Run(context.Background(), s.readinessChecks()...)
+	}
+
+	t.Run("leader election is not running", func(t *testing.T) {
+		report := check(t, newScheduler(t, &fakeApp{}, &config.Config{}))
+		require.Equal(t, health.StatusNotReady, report.Status)
+		require.Equal(t, errElectionNotRunning.Error(), report.Checks["leadership"])
+		require.Equal(t, "ok", report.Checks["storage"])
+		require.Equal(t, "ok", report.Checks["queue"])
+	})
+
+	t.Run("leader and follower are ready", func(t *testing.T) {
+		memory := memorystorage.New()
+		leader, stopLeader := startScheduler(t, app.New(memory), &config.Config{})
+		defer stopLeader()
+		require.Eventually(t, leader.IsLeader, time.Second, 5*time.Millisecond)
+
+		follower, stopFollower := startScheduler(t, app.New(memory), &config.Config{})
+		require.Eventually(t, func() bool {
+			return follower.checkElection(context.Background()) == nil
+		}, time.Second, 5*time.Millisecond)
+		require.False(t, follower.IsLeader())
+		require.Equal(t, health.StatusOK, check(t, leader).Status)
+		require.Equal(t, health.StatusOK, check(t, follower).Status)
+
+		stopFollower()
+		require.ErrorIs(t, follower.checkElection(context.Background()), errElectionNotRunning)
+	})
+
+	t.Run("storage is unavailable", func(t *testing.T) {
+		s, stop := startScheduler(t, &fakeApp{pingErr: errStorage}, &config.Config{})
+		defer stop()
+		require.Eventually(t, func() bool {
+			return errors.Is(s.checkElection(context.Background()), errStorage)
+		}, time.Second, 5*time.Millisecond)
+
+		report := check(t, s)
+		require.Equal(t, health.StatusNotReady, report.Status)
+		require.Equal(t, errStorage.Error(), report.Checks["storage"])
+		require.Equal(t, errStorage.Error(), report.Checks["leadership"])
+	})
+}
+
+func TestAdminServer(t *testing.T) {
+	s := newScheduler(t, &fakeApp{}, &config.Config{})
+	s.runJob(context.Background(), s.jobs[0])
+
+	server := httptest.NewServer(NewAdminServer(logger.New("error"), s, config.ServerConf{}).routes())
+	defer server.Close()
+
+	get := func(t *testing.T, path string, v interface{}) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		if v != nil {
+			require.NoError(t, json.NewDecoder(response.Body).Decode(v))
+		}
+		return response.StatusCode
+	}
+
+	t.Run("list jobs", func(t *testing.T) {
+		var jobs []JobStatus
+		require.Equal(t, http.StatusOK, get(t, "/jobs", &jobs))
+		require.Len(t, jobs, 3)
+		require.Equal(t, JobPurge, jobs[0].Name)
+		require.Equal(t, OutcomeSuccess, jobs[0].LastOutcome)
+		require.Equal(t, 1, jobs[0].Runs)
+	})
+
+	t.Run("get job", func(t *testing.T) {
+		var job JobStatus
+		require.Equal(t, http.StatusOK, get(t, "/jobs/"+JobDigest, &job))
+		require.Equal(t, JobDigest, job.Name)
+		require.Zero(t, job.Runs)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, get(t, "/jobs/unknown", nil))
+	})
+
+	t.Run("leader", func(t *testing.T) {
+		var leader LeaderStatus
+		require.Equal(t, http.StatusOK, get(t, "/leader", &leader))
+		require.False(t, leader.Leader)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		var report health.Report
+		require.Equal(t, http.StatusOK, get(t, "/healthz", &report))
+		require.Equal(t, health.StatusOK, report.Status)
+
+		require.Equal(t, http.StatusServiceUnavailable, get(t, "/readyz", &report))
+		require.Equal(t, health.StatusNotReady, report.Status)
+		require.Equal(t, errElectionNotRunning.Error(), report.Checks["leadership"])
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/metrics", nil)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Contains(t, string(body), `calendar_scheduler_job_runs_total{job="purge",outcome="success"}`)
+		require.Contains(t, string(body), "calendar_scheduler_purged_events_total")
+	})
+}
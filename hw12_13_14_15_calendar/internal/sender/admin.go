@@ -0,0 +1,61 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+)
+
+// AdminServer - HTTP-сервер администрирования рассыльщика. GET /metrics возвращает метрики Prometheus,
+// GET /healthz и GET /readyz - проверки живости и готовности.
+type AdminServer struct {
+	logger Logger
+	sender *Sender
+	server *http.Server
+}
+
+func NewAdminServer(logger Logger, sender *Sender, cfg config.ServerConf) *AdminServer {
+	a := &AdminServer{
+		logger: logger,
+		sender: sender,
+	}
+
+	a.server = &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           a.routes(),
+		ReadHeaderTimeout: time.Second * 5,
+	}
+
+	return a
+}
+
+// routes возвращает маршрутизатор со всеми обработчиками сервера.
+func (a *AdminServer) routes() *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	router.Handle(health.LivenessPath, health.LivenessHandler()).Methods("GET")
+	router.Handle(health.ReadinessPath, health.ReadinessHandler(a.sender.readinessChecks()...)).Methods("GET")
+	return router
+}
+
+// Start принимает запросы, пока сервер не остановлен методом Stop.
+func (a *AdminServer) Start() error {
+	err := a.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (a *AdminServer) Stop(ctx context.Context) error {
+	return a.server.Shutdown(ctx)
+}
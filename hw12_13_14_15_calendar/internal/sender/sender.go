@@ -3,19 +3,27 @@ package sender
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/notifier"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
 type Sender struct {
-	logger Logger
-	app    Application
-	queue  QueueApplication
+	logger    Logger
+	app       Application
+	queue     QueueApplication
+	notifiers map[storage.NotificationChannel]Notifier
 }
 
 type Logger interface {
@@ -27,21 +35,36 @@ type Logger interface {
 }
 
 type Application interface {
-	PatchEvent(ctx context.Context, id uuid.UUID, userID *uuid.UUID, title, description *string, startTime,
-		finishTime *storage.EventTime, notifyBefore *int, notificationSent *bool) error
+	Ping(ctx context.Context) error
+	GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error)
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (storage.NotificationSettings, error)
+	IsNotificationDelivered(ctx context.Context, key string) (bool, error)
+	MarkNotificationDelivered(ctx context.Context, key string) (first bool, err error)
+}
+
+// Notifier доставляет уведомление получателю recipient.
+type Notifier interface {
+	Notify(ctx context.Context, recipient notifier.Recipient, notification queue.Notification) error
 }
 
 type QueueApplication interface {
 	Connect() error
 	Close() error
+	Ping(ctx context.Context) error
 	ReadAndProcessNotifications(ctx context.Context, fn app.CallbackFunc) error
 }
 
-func New(logger Logger, app Application, queue QueueApplication) *Sender {
+var errChannelNotConfigured = errors.New("notification channel is not configured")
+
+// New создает рассыльщика, доставляющего уведомления по каналам notifiers.
+func New(logger Logger, app Application, queue QueueApplication,
+	notifiers map[storage.NotificationChannel]Notifier,
+) *Sender {
 	return &Sender{
-		logger: logger,
-		app:    app,
-		queue:  queue,
+		logger:    logger,
+		app:       app,
+		queue:     queue,
+		notifiers: notifiers,
 	}
 }
 
@@ -65,22 +88,118 @@ func (s *Sender) Start(ctx context.Context) error {
 	return nil
 }
 
-func (s *Sender) SendNotification(ctx context.Context, body []byte) {
+// readinessChecks возвращает проверки готовности рассыльщика: доступность хранилища и соединение с очередью.
+func (s *Sender) readinessChecks() []health.Check {
+	return []health.Check{
+		{Name: "storage", Check: s.app.Ping},
+		{Name: "queue", Check: s.queue.Ping},
+	}
+}
+
+// SendNotification доставляет уведомление по каналу, выбранному получателем, на его языке и со временем
+// начала события в его часовом поясе. Канал напоминания используется вместо канала получателя, если
+// для него не нужен адрес (log) или он совпадает с каналом получателя. Очередь доставляет
+// уведомления хотя бы один раз, поэтому уже доставленное уведомление с тем же ключом дедупликации
+// пропускается. Если канал не настроен, уведомление доставляется в журнал с предупреждением.
+// При ошибке доставки уведомление будет обработано повторно.
+func (s *Sender) SendNotification(ctx context.Context, body []byte) error {
 	notification := &queue.Notification{}
 	err := json.Unmarshal(body, notification)
 	if err != nil {
 		s.logger.Error("unmarshal body error: %w", err)
+		return err
 	}
 
-	title := notification.Title
-	startTime := time.Time(notification.StartTime).Format(time.DateTime)
+	if notification.Key != "" {
+		delivered, err := s.app.IsNotificationDelivered(ctx, notification.Key)
+		if err != nil {
+			s.logger.Error(err)
+			return err
+		}
 
-	s.logger.Infof("Dear user, pls be reminded on event '%v' at %v", title, startTime)
+		if delivered {
+			s.logger.Debug("duplicate notification skipped: " + notification.Key)
+			return nil
+		}
+	}
 
-	notificationSent := true
-	err = s.app.PatchEvent(ctx, notification.ID, nil, nil, nil, nil, nil, nil, &notificationSent)
+	settings, err := s.app.GetNotificationSettings(ctx, notification.UserID)
 	if err != nil {
 		s.logger.Error(err)
-		return
+		return err
 	}
+
+	channelName := s.channel(*notification, settings)
+	channel, ok := s.notifiers[channelName]
+	if !ok {
+		// Канал получателя не настроен у этого рассыльщика: уведомление не должно теряться
+		// в повторах, поэтому оно доставляется в журнал.
+		channel, ok = s.notifiers[storage.ChannelLog]
+		if !ok {
+			err = fmt.Errorf("%w: %q", errChannelNotConfigured, channelName)
+			s.logger.Error(err)
+			return err
+		}
+
+		s.logger.Warn(fmt.Sprintf("notification channel %q is not configured, delivering %s to log instead",
+			channelName, notification.Key))
+		channelName = storage.ChannelLog
+	}
+
+	userSettings, err := s.app.GetUserSettings(ctx, notification.UserID)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+
+	recipient := notifier.Recipient{
+		Address:  settings.Address,
+		Locale:   settings.Locale,
+		Location: userSettings.Location(),
+	}
+	err = s.notify(ctx, channel, channelName, recipient, *notification)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+
+	// Уведомление уже доставлено, поэтому ошибка отметки не должна приводить к повторной доставке.
+	if notification.Key != "" {
+		if _, err = s.app.MarkNotificationDelivered(ctx, notification.Key); err != nil {
+			s.logger.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// notify доставляет уведомление по каналу channel, записывая доставку в трассировку и метрики.
+func (s *Sender) notify(ctx context.Context, channel Notifier, channelName storage.NotificationChannel,
+	recipient notifier.Recipient, notification queue.Notification,
+) error {
+	ctx, span := tracing.Tracer().Start(ctx, "deliver "+string(channelName),
+		trace.WithAttributes(attribute.String("notification.channel", string(channelName)),
+			attribute.String("notification.key", notification.Key)))
+	defer span.End()
+
+	err := channel.Notify(ctx, recipient, notification)
+	tracing.SetError(span, err)
+	metrics.ObserveDelivery(string(channelName), err)
+	return err
+}
+
+// channel выбирает канал доставки уведомления. Адрес в настройках получателя относится только к его каналу,
+// поэтому канал напоминания, которому нужен другой адрес, заменяется каналом получателя.
+func (s *Sender) channel(notification queue.Notification,
+	settings storage.NotificationSettings,
+) storage.NotificationChannel {
+	if notification.Channel != storage.ChannelLog && notification.Channel != settings.Channel {
+		return settings.Channel
+	}
+
+	if _, ok := s.notifiers[notification.Channel]; !ok {
+		return settings.Channel
+	}
+
+	return notification.Channel
 }
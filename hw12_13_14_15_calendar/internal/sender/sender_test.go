@@ -0,0 +1,242 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/notifier"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue"
+	memoryqueue "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/queue/memory"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
+)
+
+var errNotify = errors.New("notify failed")
+
+// fakeNotifier запоминает получателей уведомлений и возвращает ошибку, пока fail равен true.
+type fakeNotifier struct {
+	fail       bool
+	recipients []notifier.Recipient
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, recipient notifier.Recipient, _ queue.Notification) error {
+	if n.fail {
+		return errNotify
+	}
+
+	n.recipients = append(n.recipients, recipient)
+	return nil
+}
+
+func TestSendNotification(t *testing.T) {
+	ctx := context.Background()
+	calendar := app.New(memorystorage.New())
+	userID := uuid.Must(uuid.NewV4())
+	email := &fakeNotifier{}
+	logNotifier := &fakeNotifier{}
+	s := New(logger.New("error"), calendar, nil, map[storage.NotificationChannel]Notifier{
+		storage.ChannelLog:   logNotifier,
+		storage.ChannelEmail: email,
+	})
+
+	body := func(t *testing.T, key string, channel storage.NotificationChannel) []byte {
+		t.Helper()
+		data, err := json.Marshal(queue.Notification{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    userID,
+			Channel:   channel,
+			Title:     "Standup",
+			StartTime: storage.EventTime(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+			Key:       key,
+		})
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("default channel", func(t *testing.T) {
+		require.NoError(t, s.SendNotification(ctx, body(t, "", "")))
+		require.Len(t, logNotifier.recipients, 1)
+		require.Empty(t, email.recipients)
+	})
+
+	t.Run("user channel", func(t *testing.T) {
+		err := calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelEmail, "alice@example.com", "ru", "")
+		require.NoError(t, err)
+		require.NoError(t, calendar.UpdateUserSettings(ctx, userID, "Europe/Moscow"))
+
+		require.NoError(t, s.SendNotification(ctx, body(t, "", "")))
+		require.Len(t, email.recipients, 1)
+		require.Equal(t, "alice@example.com", email.recipients[0].Address)
+		require.Equal(t, "ru", email.recipients[0].Locale)
+		require.Equal(t, "Europe/Moscow", email.recipients[0].Location.String())
+	})
+
+	t.Run("reminder channel", func(t *testing.T) {
+		require.NoError(t, s.SendNotification(ctx, body(t, "", storage.ChannelLog)))
+		require.Len(t, logNotifier.recipients, 2)
+
+		// У вебхука нет адреса в настройках получателя, поэтому используется его канал.
+		require.NoError(t, s.SendNotification(ctx, body(t, "", storage.ChannelWebhook)))
+		require.Len(t, email.recipients, 2)
+	})
+
+	t.Run("failed delivery is retried", func(t *testing.T) {
+		email.fail = true
+		require.ErrorIs(t, s.SendNotification(ctx, body(t, "", "")), errNotify)
+		email.fail = false
+	})
+
+	t.Run("channel is not configured", func(t *testing.T) {
+		err := calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelWebhook, "https://example.com/hook", "", "")
+		require.NoError(t, err)
+		require.NoError(t, s.SendNotification(ctx, body(t, "", "")))
+		require.Len(t, logNotifier.recipients, 3)
+		require.Equal(t, "https://example.com/hook", logNotifier.recipients[2].Address)
+
+		err = calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelEmail, "alice@example.com", "", "")
+		require.NoError(t, err)
+
+		webhookOnly := New(logger.New("error"), calendar, nil, map[storage.NotificationChannel]Notifier{
+			storage.ChannelWebhook: email,
+		})
+		require.ErrorIs(t, webhookOnly.SendNotification(ctx, body(t, "", "")), errChannelNotConfigured)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		require.Error(t, s.SendNotification(ctx, []byte("{")))
+	})
+}
+
+func TestSendNotificationDeduplication(t *testing.T) {
+	ctx := context.Background()
+	calendar := app.New(memorystorage.New())
+	userID := uuid.Must(uuid.NewV4())
+
+	webhook := &fakeNotifier{}
+	s := New(logger.New("error"), calendar, nil, map[storage.NotificationChannel]Notifier{
+		storage.ChannelWebhook: webhook,
+	})
+	err := calendar.UpdateNotificationSettings(ctx, userID, storage.ChannelWebhook, "https://example.com/hook", "", "")
+	require.NoError(t, err)
+
+	startTime := time.Now().Add(5 * time.Minute)
+	err = calendar.CreateEvent(ctx, userID, "Standup", "", storage.EventTime(startTime),
+		storage.EventTime(startTime.Add(time.Hour)), []storage.Reminder{{Offset: 15}}, storage.Recurrence{}, "UTC",
+		false, nil)
+	require.NoError(t, err)
+	_, err = calendar.EnqueueNotifications(ctx)
+	require.NoError(t, err)
+
+	var messages []storage.OutboxMessage
+	_, err = calendar.RelayNotifications(ctx, 10, func(_ context.Context, batch []storage.OutboxMessage) (int, error) {
+		messages = batch
+		return len(batch), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	body, err := json.Marshal(queue.NewNotification(messages[0]))
+	require.NoError(t, err)
+	require.NoError(t, s.SendNotification(ctx, body))
+	require.NoError(t, s.SendNotification(ctx, body))
+	require.Len(t, webhook.recipients, 1)
+}
+
+func TestSendNotificationTracing(t *testing.T) {
+	recorder := tracingtest.NewRecorder(t)
+	notifiers := map[storage.NotificationChannel]Notifier{storage.ChannelLog: &fakeNotifier{}}
+	s := New(logger.New("error"), app.New(memorystorage.New()), nil, notifiers)
+	body, err := json.Marshal(queue.Notification{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    uuid.Must(uuid.NewV4()),
+		Title:     "Standup",
+		StartTime: storage.EventTime(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+
+	ctx := tracing.ContextWithTraceParent(context.Background(), tracingtest.TraceParent)
+	require.NoError(t, s.SendNotification(ctx, body))
+	span := tracingtest.Span(t, recorder, "deliver log")
+	require.Equal(t, tracingtest.TraceID, span.SpanContext().TraceID().String())
+	require.Equal(t, tracingtest.SpanID, span.Parent().SpanID().String())
+	require.Equal(t, codes.Unset, span.Status().Code)
+
+	notifiers[storage.ChannelLog] = &fakeNotifier{fail: true}
+	require.ErrorIs(t, s.SendNotification(ctx, body), errNotify)
+	failed := recorder.Ended()[len(recorder.Ended())-1]
+	require.Equal(t, "deliver log", failed.Name())
+	require.Equal(t, codes.Error, failed.Status().Code)
+}
+
+func TestAdminServer(t *testing.T) {
+	ctx := context.Background()
+	notificationQueue := memoryqueue.New(&config.Config{})
+	require.NoError(t, notificationQueue.Connect())
+	s := New(logger.New("error"), app.New(memorystorage.New()), notificationQueue,
+		map[storage.NotificationChannel]Notifier{storage.ChannelLog: &fakeNotifier{fail: true}})
+
+	body, err := json.Marshal(queue.Notification{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    uuid.Must(uuid.NewV4()),
+		Title:     "Standup",
+		StartTime: storage.EventTime(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, s.SendNotification(ctx, body), errNotify)
+
+	server := httptest.NewServer(NewAdminServer(logger.New("error"), s, config.ServerConf{}).routes())
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (int, []byte) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, body
+	}
+
+	t.Run("metrics", func(t *testing.T) {
+		status, metrics := get(t, "/metrics")
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, string(metrics), `calendar_sender_deliveries_total{channel="log",status="failure"}`)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		status, body := get(t, "/healthz")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"status":"ok"}`, string(body))
+
+		status, body = get(t, "/readyz")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"status":"ok","checks":{"storage":"ok","queue":"ok"}}`, string(body))
+
+		require.NoError(t, notificationQueue.Close())
+		status, body = get(t, "/readyz")
+		require.Equal(t, http.StatusServiceUnavailable, status)
+		var report health.Report
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.Equal(t, health.StatusNotReady, report.Status)
+		require.Equal(t, "ok", report.Checks["storage"])
+		require.Equal(t, memoryqueue.ErrClosed.Error(), report.Checks["queue"])
+	})
+}
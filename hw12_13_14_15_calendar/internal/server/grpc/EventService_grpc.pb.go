@@ -19,12 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	EventService_Create_FullMethodName            = "/event.EventService/Create"
-	EventService_Update_FullMethodName            = "/event.EventService/Update"
-	EventService_Delete_FullMethodName            = "/event.EventService/Delete"
-	EventService_ListEventsByDay_FullMethodName   = "/event.EventService/ListEventsByDay"
-	EventService_ListEventsByWeek_FullMethodName  = "/event.EventService/ListEventsByWeek"
-	EventService_ListEventsByMonth_FullMethodName = "/event.EventService/ListEventsByMonth"
+	EventService_Create_FullMethodName                     = "/event.EventService/Create"
+	EventService_Update_FullMethodName                     = "/event.EventService/Update"
+	EventService_Get_FullMethodName                        = "/event.EventService/Get"
+	EventService_Patch_FullMethodName                      = "/event.EventService/Patch"
+	EventService_Delete_FullMethodName                     = "/event.EventService/Delete"
+	EventService_ListEventsByDay_FullMethodName            = "/event.EventService/ListEventsByDay"
+	EventService_ListEventsByWeek_FullMethodName           = "/event.EventService/ListEventsByWeek"
+	EventService_ListEventsByMonth_FullMethodName          = "/event.EventService/ListEventsByMonth"
+	EventService_ListEvents_FullMethodName                 = "/event.EventService/ListEvents"
+	EventService_Search_FullMethodName                     = "/event.EventService/Search"
+	EventService_ExportEvents_FullMethodName               = "/event.EventService/ExportEvents"
+	EventService_ImportEvents_FullMethodName               = "/event.EventService/ImportEvents"
+	EventService_GetUserSettings_FullMethodName            = "/event.EventService/GetUserSettings"
+	EventService_UpdateUserSettings_FullMethodName         = "/event.EventService/UpdateUserSettings"
+	EventService_GetNotificationSettings_FullMethodName    = "/event.EventService/GetNotificationSettings"
+	EventService_UpdateNotificationSettings_FullMethodName = "/event.EventService/UpdateNotificationSettings"
+	EventService_FindFreeSlots_FullMethodName              = "/event.EventService/FindFreeSlots"
+	EventService_RespondToInvitation_FullMethodName        = "/event.EventService/RespondToInvitation"
+	EventService_SnoozeReminder_FullMethodName             = "/event.EventService/SnoozeReminder"
 )
 
 // EventServiceClient is the client API for EventService service.
@@ -33,10 +46,23 @@ const (
 type EventServiceClient interface {
 	Create(ctx context.Context, in *Event, opts ...grpc.CallOption) (*EventResponse, error)
 	Update(ctx context.Context, in *EventWithID, opts ...grpc.CallOption) (*EventResponse, error)
+	Get(ctx context.Context, in *EventID, opts ...grpc.CallOption) (*EventWithID, error)
+	Patch(ctx context.Context, in *PatchEventRequest, opts ...grpc.CallOption) (*EventWithID, error)
 	Delete(ctx context.Context, in *EventID, opts ...grpc.CallOption) (*EventResponse, error)
 	ListEventsByDay(ctx context.Context, in *EventsListRequest, opts ...grpc.CallOption) (*EventsListResponse, error)
 	ListEventsByWeek(ctx context.Context, in *EventsListRequest, opts ...grpc.CallOption) (*EventsListResponse, error)
 	ListEventsByMonth(ctx context.Context, in *EventsListRequest, opts ...grpc.CallOption) (*EventsListResponse, error)
+	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*EventsListResponse, error)
+	ExportEvents(ctx context.Context, in *ExportEventsRequest, opts ...grpc.CallOption) (*ExportEventsResponse, error)
+	ImportEvents(ctx context.Context, in *ImportEventsRequest, opts ...grpc.CallOption) (*ImportEventsResponse, error)
+	GetUserSettings(ctx context.Context, in *UserSettingsRequest, opts ...grpc.CallOption) (*UserSettings, error)
+	UpdateUserSettings(ctx context.Context, in *UserSettings, opts ...grpc.CallOption) (*EventResponse, error)
+	GetNotificationSettings(ctx context.Context, in *UserSettingsRequest, opts ...grpc.CallOption) (*NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, in *NotificationSettings, opts ...grpc.CallOption) (*EventResponse, error)
+	FindFreeSlots(ctx context.Context, in *FreeSlotsRequest, opts ...grpc.CallOption) (*FreeSlotsResponse, error)
+	RespondToInvitation(ctx context.Context, in *InvitationResponse, opts ...grpc.CallOption) (*EventResponse, error)
+	SnoozeReminder(ctx context.Context, in *SnoozeReminderRequest, opts ...grpc.CallOption) (*EventResponse, error)
 }
 
 type eventServiceClient struct {
@@ -65,6 +91,24 @@ func (c *eventServiceClient) Update(ctx context.Context, in *EventWithID, opts .
 	return out, nil
 }
 
+func (c *eventServiceClient) Get(ctx context.Context, in *EventID, opts ...grpc.CallOption) (*EventWithID, error) {
+	out := new(EventWithID)
+	err := c.cc.Invoke(ctx, EventService_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) Patch(ctx context.Context, in *PatchEventRequest, opts ...grpc.CallOption) (*EventWithID, error) {
+	out := new(EventWithID)
+	err := c.cc.Invoke(ctx, EventService_Patch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eventServiceClient) Delete(ctx context.Context, in *EventID, opts ...grpc.CallOption) (*EventResponse, error) {
 	out := new(EventResponse)
 	err := c.cc.Invoke(ctx, EventService_Delete_FullMethodName, in, out, opts...)
@@ -101,16 +145,128 @@ func (c *eventServiceClient) ListEventsByMonth(ctx context.Context, in *EventsLi
 	return out, nil
 }
 
+func (c *eventServiceClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_ListEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*EventsListResponse, error) {
+	out := new(EventsListResponse)
+	err := c.cc.Invoke(ctx, EventService_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ExportEvents(ctx context.Context, in *ExportEventsRequest, opts ...grpc.CallOption) (*ExportEventsResponse, error) {
+	out := new(ExportEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_ExportEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ImportEvents(ctx context.Context, in *ImportEventsRequest, opts ...grpc.CallOption) (*ImportEventsResponse, error) {
+	out := new(ImportEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_ImportEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) GetUserSettings(ctx context.Context, in *UserSettingsRequest, opts ...grpc.CallOption) (*UserSettings, error) {
+	out := new(UserSettings)
+	err := c.cc.Invoke(ctx, EventService_GetUserSettings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpdateUserSettings(ctx context.Context, in *UserSettings, opts ...grpc.CallOption) (*EventResponse, error) {
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, EventService_UpdateUserSettings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) GetNotificationSettings(ctx context.Context, in *UserSettingsRequest, opts ...grpc.CallOption) (*NotificationSettings, error) {
+	out := new(NotificationSettings)
+	err := c.cc.Invoke(ctx, EventService_GetNotificationSettings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpdateNotificationSettings(ctx context.Context, in *NotificationSettings, opts ...grpc.CallOption) (*EventResponse, error) {
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, EventService_UpdateNotificationSettings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) FindFreeSlots(ctx context.Context, in *FreeSlotsRequest, opts ...grpc.CallOption) (*FreeSlotsResponse, error) {
+	out := new(FreeSlotsResponse)
+	err := c.cc.Invoke(ctx, EventService_FindFreeSlots_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) RespondToInvitation(ctx context.Context, in *InvitationResponse, opts ...grpc.CallOption) (*EventResponse, error) {
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, EventService_RespondToInvitation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) SnoozeReminder(ctx context.Context, in *SnoozeReminderRequest, opts ...grpc.CallOption) (*EventResponse, error) {
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, EventService_SnoozeReminder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventServiceServer is the server API for EventService service.
 // All implementations must embed UnimplementedEventServiceServer
 // for forward compatibility
 type EventServiceServer interface {
 	Create(context.Context, *Event) (*EventResponse, error)
 	Update(context.Context, *EventWithID) (*EventResponse, error)
+	Get(context.Context, *EventID) (*EventWithID, error)
+	Patch(context.Context, *PatchEventRequest) (*EventWithID, error)
 	Delete(context.Context, *EventID) (*EventResponse, error)
 	ListEventsByDay(context.Context, *EventsListRequest) (*EventsListResponse, error)
 	ListEventsByWeek(context.Context, *EventsListRequest) (*EventsListResponse, error)
 	ListEventsByMonth(context.Context, *EventsListRequest) (*EventsListResponse, error)
+	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	Search(context.Context, *SearchRequest) (*EventsListResponse, error)
+	ExportEvents(context.Context, *ExportEventsRequest) (*ExportEventsResponse, error)
+	ImportEvents(context.Context, *ImportEventsRequest) (*ImportEventsResponse, error)
+	GetUserSettings(context.Context, *UserSettingsRequest) (*UserSettings, error)
+	UpdateUserSettings(context.Context, *UserSettings) (*EventResponse, error)
+	GetNotificationSettings(context.Context, *UserSettingsRequest) (*NotificationSettings, error)
+	UpdateNotificationSettings(context.Context, *NotificationSettings) (*EventResponse, error)
+	FindFreeSlots(context.Context, *FreeSlotsRequest) (*FreeSlotsResponse, error)
+	RespondToInvitation(context.Context, *InvitationResponse) (*EventResponse, error)
+	SnoozeReminder(context.Context, *SnoozeReminderRequest) (*EventResponse, error)
 	mustEmbedUnimplementedEventServiceServer()
 }
 
@@ -124,6 +280,12 @@ func (UnimplementedEventServiceServer) Create(context.Context, *Event) (*EventRe
 func (UnimplementedEventServiceServer) Update(context.Context, *EventWithID) (*EventResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedEventServiceServer) Get(context.Context, *EventID) (*EventWithID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedEventServiceServer) Patch(context.Context, *PatchEventRequest) (*EventWithID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Patch not implemented")
+}
 func (UnimplementedEventServiceServer) Delete(context.Context, *EventID) (*EventResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
@@ -136,6 +298,39 @@ func (UnimplementedEventServiceServer) ListEventsByWeek(context.Context, *Events
 func (UnimplementedEventServiceServer) ListEventsByMonth(context.Context, *EventsListRequest) (*EventsListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEventsByMonth not implemented")
 }
+func (UnimplementedEventServiceServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
+}
+func (UnimplementedEventServiceServer) Search(context.Context, *SearchRequest) (*EventsListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedEventServiceServer) ExportEvents(context.Context, *ExportEventsRequest) (*ExportEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportEvents not implemented")
+}
+func (UnimplementedEventServiceServer) ImportEvents(context.Context, *ImportEventsRequest) (*ImportEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportEvents not implemented")
+}
+func (UnimplementedEventServiceServer) GetUserSettings(context.Context, *UserSettingsRequest) (*UserSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserSettings not implemented")
+}
+func (UnimplementedEventServiceServer) UpdateUserSettings(context.Context, *UserSettings) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUserSettings not implemented")
+}
+func (UnimplementedEventServiceServer) GetNotificationSettings(context.Context, *UserSettingsRequest) (*NotificationSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationSettings not implemented")
+}
+func (UnimplementedEventServiceServer) UpdateNotificationSettings(context.Context, *NotificationSettings) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationSettings not implemented")
+}
+func (UnimplementedEventServiceServer) FindFreeSlots(context.Context, *FreeSlotsRequest) (*FreeSlotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindFreeSlots not implemented")
+}
+func (UnimplementedEventServiceServer) RespondToInvitation(context.Context, *InvitationResponse) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondToInvitation not implemented")
+}
+func (UnimplementedEventServiceServer) SnoozeReminder(context.Context, *SnoozeReminderRequest) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnoozeReminder not implemented")
+}
 func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
 
 // UnsafeEventServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -185,6 +380,42 @@ func _EventService_Update_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).Get(ctx, req.(*EventID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_Patch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).Patch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_Patch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).Patch(ctx, req.(*PatchEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EventService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EventID)
 	if err := dec(in); err != nil {
@@ -257,6 +488,204 @@ func _EventService_ListEventsByMonth_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ListEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ListEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ListEvents(ctx, req.(*ListEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ExportEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ExportEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ExportEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ExportEvents(ctx, req.(*ExportEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ImportEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ImportEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ImportEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ImportEvents(ctx, req.(*ImportEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_GetUserSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetUserSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetUserSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetUserSettings(ctx, req.(*UserSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpdateUserSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserSettings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpdateUserSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UpdateUserSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpdateUserSettings(ctx, req.(*UserSettings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_GetNotificationSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetNotificationSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetNotificationSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetNotificationSettings(ctx, req.(*UserSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpdateNotificationSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationSettings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpdateNotificationSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UpdateNotificationSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpdateNotificationSettings(ctx, req.(*NotificationSettings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_FindFreeSlots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreeSlotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).FindFreeSlots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_FindFreeSlots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).FindFreeSlots(ctx, req.(*FreeSlotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_RespondToInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvitationResponse)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).RespondToInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_RespondToInvitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).RespondToInvitation(ctx, req.(*InvitationResponse))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_SnoozeReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnoozeReminderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).SnoozeReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_SnoozeReminder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).SnoozeReminder(ctx, req.(*SnoozeReminderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -272,6 +701,14 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _EventService_Update_Handler,
 		},
+		{
+			MethodName: "Get",
+			Handler:    _EventService_Get_Handler,
+		},
+		{
+			MethodName: "Patch",
+			Handler:    _EventService_Patch_Handler,
+		},
 		{
 			MethodName: "Delete",
 			Handler:    _EventService_Delete_Handler,
@@ -288,6 +725,50 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListEventsByMonth",
 			Handler:    _EventService_ListEventsByMonth_Handler,
 		},
+		{
+			MethodName: "ListEvents",
+			Handler:    _EventService_ListEvents_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _EventService_Search_Handler,
+		},
+		{
+			MethodName: "ExportEvents",
+			Handler:    _EventService_ExportEvents_Handler,
+		},
+		{
+			MethodName: "ImportEvents",
+			Handler:    _EventService_ImportEvents_Handler,
+		},
+		{
+			MethodName: "GetUserSettings",
+			Handler:    _EventService_GetUserSettings_Handler,
+		},
+		{
+			MethodName: "UpdateUserSettings",
+			Handler:    _EventService_UpdateUserSettings_Handler,
+		},
+		{
+			MethodName: "GetNotificationSettings",
+			Handler:    _EventService_GetNotificationSettings_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationSettings",
+			Handler:    _EventService_UpdateNotificationSettings_Handler,
+		},
+		{
+			MethodName: "FindFreeSlots",
+			Handler:    _EventService_FindFreeSlots_Handler,
+		},
+		{
+			MethodName: "RespondToInvitation",
+			Handler:    _EventService_RespondToInvitation_Handler,
+		},
+		{
+			MethodName: "SnoozeReminder",
+			Handler:    _EventService_SnoozeReminder_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/EventService.proto",
@@ -0,0 +1,52 @@
+package internalgrpc
+
+import (
+	"context"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthServicePrefix - префикс методов службы здоровья. Пробы Kubernetes и grpc-health-probe
+// вызывают их без учетных данных, поэтому аутентификация для них не требуется.
+var healthServicePrefix = "/" + healthpb.Health_ServiceDesc.ServiceName + "/"
+
+// Параметры проверки хранилища для службы grpc.health.v1.Health.
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 3 * time.Second
+)
+
+// watchHealth проверяет хранилище каждые healthCheckInterval и обновляет состояние сервера и службы
+// EventService в службе здоровья, пока не отменен контекст. После отмены контекста все службы
+// переводятся в состояние NOT_SERVING.
+func (s *GRPCServer) watchHealth(ctx context.Context, server *grpchealth.Server) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.updateHealth(ctx, server)
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateHealth устанавливает состояние SERVING, если хранилище доступно, и NOT_SERVING - если нет.
+func (s *GRPCServer) updateHealth(ctx context.Context, server *grpchealth.Server) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := s.app.Ping(ctx); err != nil {
+		s.logger.Warn("storage is unavailable: " + err.Error())
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	server.SetServingStatus("", status)
+	server.SetServingStatus(EventService_ServiceDesc.ServiceName, status)
+}
@@ -2,18 +2,143 @@ package internalgrpc
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
+// tracingInterceptor начинает span запроса, продолжая трассировку из метаданных traceparent и baggage.
+// Ошибки сервера (Internal, Unknown, Unavailable и т.п.) отмечаются в span как ошибки.
+func (s *GRPCServer) tracingInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = tracing.Extract(ctx, metadataCarrier(md))
+	ctx, span := tracing.Tracer().Start(ctx, strings.TrimPrefix(info.FullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC))
+	defer span.End()
+
+	i, err := handler(ctx, request)
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if isServerError(code) {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+
+	return i, err
+}
+
+// isServerError возвращает true для кодов, означающих ошибку сервера, а не запроса.
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable,
+		codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// metadataCarrier читает и записывает контекст трассировки в метаданных GRPC.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// loggingInterceptor пишет запрос в журнал и учитывает его в метриках. ID запроса берется из метаданных
+// x-request-id или создается, передается в контексте и возвращается в заголовках ответа.
 func (s *GRPCServer) loggingInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(logger.RequestIDHeader)) > 0 {
+		requestID = md.Get(logger.RequestIDHeader)[0]
+	}
+	requestID = logger.NewRequestID(requestID)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDHeader, requestID))
+	ctx = logger.ContextWithRequestID(ctx, requestID)
+	if eventID := requestEventID(request); eventID != "" {
+		logger.AddField(ctx, logger.FieldEventID, eventID)
+	}
+
 	start := time.Now()
 	i, err := handler(ctx, request)
 	duration := time.Since(start)
-	s.logger.LogGRPCRequest(ctx, info, duration, status.Code(err).String())
+	code := status.Code(err).String()
+	s.logger.LogGRPCRequest(ctx, info, duration, code)
+	metrics.ObserveGRPCRequest(info.FullMethod, code, duration)
 	return i, err
 }
+
+// requestEventID возвращает ID события из запроса request или пустую строку, если в запросе его нет.
+func requestEventID(request interface{}) string {
+	switch request := request.(type) {
+	case interface{ GetEventId() string }:
+		return request.GetEventId()
+	case interface{ GetId() string }:
+		return request.GetId()
+	default:
+		return ""
+	}
+}
+
+// authInterceptor аутентифицирует запрос по метаданным authorization и помещает пользователя в контекст.
+// Методы службы здоровья вызываются без аутентификации.
+func (s *GRPCServer) authInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if s.auth == nil || strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		return handler(ctx, request)
+	}
+
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		authorization = md.Get("authorization")[0]
+	}
+
+	scheme, credentials, err := auth.ParseAuthorization(authorization)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	userID, err := s.auth.Authenticate(ctx, scheme, credentials)
+	if err != nil {
+		s.logger.Debug("authentication failed: ", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	logger.AddField(ctx, logger.FieldUserID, userID.String())
+	return handler(auth.WithUserID(ctx, userID), request)
+}
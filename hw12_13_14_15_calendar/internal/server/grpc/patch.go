@@ -0,0 +1,105 @@
+package internalgrpc
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+var errInvalidUpdateMask = errors.New("invalid update mask")
+
+// eventPatch возвращает изменения события event по полям message, перечисленным в mask.
+// Время без смещения относится к часовому поясу из запроса, а если он не изменяется - к часовому поясу события.
+func eventPatch(message *Event, mask *fieldmaskpb.FieldMask, event storage.Event) (storage.EventPatch, error) {
+	patch := storage.EventPatch{}
+	if len(mask.GetPaths()) == 0 {
+		return patch, fmt.Errorf("%w: no paths", errInvalidUpdateMask)
+	}
+
+	paths := make(map[string]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "title", "description", "start_time", "finish_time", "reminders", "rrule", "ex_dates",
+			"time_zone", "transparent", "attendees":
+			paths[path] = true
+		default:
+			return patch, fmt.Errorf("%w: unknown path %q", errInvalidUpdateMask, path)
+		}
+	}
+
+	loc := event.Location()
+	if paths["time_zone"] {
+		timeZone := message.GetTimeZone()
+		var err error
+		if loc, err = storage.LoadLocation(timeZone); err != nil {
+			return patch, err
+		}
+		patch.TimeZone = &timeZone
+	}
+
+	if paths["title"] {
+		title := message.GetTitle()
+		patch.Title = &title
+	}
+
+	if paths["description"] {
+		description := message.GetDescription()
+		patch.Description = &description
+	}
+
+	if paths["reminders"] {
+		reminders := parseReminders(message)
+		patch.Reminders = &reminders
+	}
+
+	if paths["transparent"] {
+		transparent := message.GetTransparent()
+		patch.Transparent = &transparent
+	}
+
+	if paths["start_time"] {
+		startTime, err := storage.ParseEventTime(message.GetStartTime(), loc)
+		if err != nil {
+			return patch, err
+		}
+		patch.StartTime = &startTime
+	}
+
+	if paths["finish_time"] {
+		finishTime, err := storage.ParseEventTime(message.GetFinishTime(), loc)
+		if err != nil {
+			return patch, err
+		}
+		patch.FinishTime = &finishTime
+	}
+
+	if paths["rrule"] || paths["ex_dates"] {
+		recurrence := event.Recurrence
+		if paths["rrule"] {
+			recurrence.RRule = message.GetRrule()
+		}
+
+		if paths["ex_dates"] {
+			var err error
+			if recurrence.ExDates, err = storage.ParseEventTimes(message.GetExDates(), loc); err != nil {
+				return patch, err
+			}
+		}
+		patch.Recurrence = &recurrence
+	}
+
+	if paths["attendees"] {
+		userIDs, err := parseAttendees(message)
+		if err != nil {
+			return patch, err
+		}
+
+		attendees := storage.NewAttendees(event.UserID, userIDs, event.Attendees)
+		patch.Attendees = &attendees
+	}
+
+	return patch, nil
+}
@@ -4,13 +4,23 @@ package internalgrpc
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 )
 
@@ -19,6 +29,7 @@ type GRPCServer struct {
 	port   string
 	logger Logger
 	app    Application
+	auth   auth.Authenticator
 	server *grpc.Server
 }
 
@@ -31,31 +42,66 @@ type Logger interface {
 }
 
 type Application interface {
+	Ping(ctx context.Context) error
 	CreateEvent(ctx context.Context, userID uuid.UUID, title, description string, startTime,
-		finishTime storage.EventTime, notifyBefore int) error
+		finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence, timeZone string,
+		transparent bool, attendees []uuid.UUID) error
 	ListEventsByDate(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
 	ListEventsByWeek(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
 	ListEventsByMonth(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
-	UpdateEvent(ctx context.Context, ID, userID uuid.UUID, title, description string, startTime,
-		finishTime storage.EventTime, notifyBefore int, notificationSent bool) error
-	DeleteEvent(ctx context.Context, ID uuid.UUID) error
+	ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error)
+	SearchEvents(ctx context.Context, userID uuid.UUID, text string, limit int) ([]storage.Event, error)
+	UpdateEvent(ctx context.Context, ID, userID uuid.UUID, version int64, title, description string, startTime,
+		finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence,
+		timeZone string, transparent bool, attendees []uuid.UUID) error
+	RespondToInvitation(ctx context.Context, eventID, userID uuid.UUID, status storage.AttendeeStatus) error
+	GetEvent(ctx context.Context, id, userID uuid.UUID) (storage.Event, error)
+	PatchEvent(ctx context.Context, id, userID uuid.UUID, version int64, patch storage.EventPatch) error
+	DeleteEvent(ctx context.Context, id, userID uuid.UUID, version int64) error
+	SnoozeReminder(ctx context.Context, eventID, reminderID, userID uuid.UUID, duration time.Duration) error
+	ExportEvents(ctx context.Context, userID uuid.UUID, startDate, finishDate storage.EventDate, w io.Writer) error
+	ImportEvents(ctx context.Context, userID uuid.UUID, r io.Reader) (*app.ImportReport, error)
+	GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error)
+	UpdateUserSettings(ctx context.Context, userID uuid.UUID, timeZone string) error
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (storage.NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, userID uuid.UUID, channel storage.NotificationChannel,
+		address, locale, digestTime string) error
+	Location(ctx context.Context, userID uuid.UUID, timeZone string) (*time.Location, error)
+	FindFreeSlots(ctx context.Context, userIDs []uuid.UUID, period app.Interval, duration time.Duration,
+		workingHours app.WorkingHours) ([]app.Interval, error)
 }
 
 type listEventsFunc func(context.Context, uuid.UUID, storage.EventDate) ([]storage.Event, error)
 
-func NewGRPCServer(logger Logger, app Application, cfg *config.Config) *GRPCServer {
+// NewGRPCServer создает GRPC сервер. Если authenticator равен nil, аутентификация отключена
+// и пользователь берется из тела запроса.
+func NewGRPCServer(logger Logger, app Application, authenticator auth.Authenticator,
+	cfg *config.Config,
+) *GRPCServer {
 	return &GRPCServer{
 		host:   cfg.GRPCServer.Host,
 		port:   cfg.GRPCServer.Port,
 		logger: logger,
 		app:    app,
+		auth:   authenticator,
 	}
 }
 
+// newServer возвращает GRPC-сервер со службами EventService и grpc.health.v1.Health. Состояние службы
+// здоровья обновляется, пока не отменен контекст.
+func (s *GRPCServer) newServer(ctx context.Context) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(s.tracingInterceptor, s.loggingInterceptor, s.authInterceptor))
+	RegisterEventServiceServer(server, s)
+
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go s.watchHealth(ctx, healthServer)
+	return server
+}
+
 func (s *GRPCServer) Start(ctx context.Context) error {
-	server := grpc.NewServer(grpc.UnaryInterceptor(s.loggingInterceptor))
+	server := s.newServer(ctx)
 	s.server = server
-	RegisterEventServiceServer(server, s)
 
 	addr := net.JoinHostPort(s.host, s.port)
 
@@ -83,36 +129,33 @@ func (s *GRPCServer) Stop() {
 }
 
 func (s *GRPCServer) Create(ctx context.Context, event *Event) (*EventResponse, error) {
-	userID, err := uuid.FromString(event.GetUserId())
+	userID, err := s.userID(ctx, event.GetUserId())
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	startTimeParsed, err := time.Parse(time.DateTime, event.GetStartTime())
+	startTime, finishTime, recurrence, err := s.parseEventTimes(ctx, userID, event)
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	startTime := storage.EventTime(startTimeParsed)
-	finishTimeParsed, err := time.Parse(time.DateTime, event.GetFinishTime())
+	attendees, err := parseAttendees(event)
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	finishTime := storage.EventTime(finishTimeParsed)
-
 	err = s.app.CreateEvent(ctx, userID, event.GetTitle(), event.GetDescription(), startTime, finishTime,
-		int(event.GetNotifyBefore()))
+		parseReminders(event), recurrence, event.GetTimeZone(), event.GetTransparent(), attendees)
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
-		}, err
+		}, statusError(err)
 	}
 
 	return &EventResponse{
@@ -128,36 +171,34 @@ func (s *GRPCServer) Update(ctx context.Context, event *EventWithID) (*EventResp
 		}, err
 	}
 
-	userID, err := uuid.FromString(event.GetEvent().UserId)
+	userID, err := s.userID(ctx, event.GetEvent().GetUserId())
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	startTimeParsed, err := time.Parse(time.DateTime, event.GetEvent().StartTime)
+	startTime, finishTime, recurrence, err := s.parseEventTimes(ctx, userID, event.GetEvent())
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	startTime := storage.EventTime(startTimeParsed)
-	finishTimeParsed, err := time.Parse(time.DateTime, event.GetEvent().FinishTime)
+	attendees, err := parseAttendees(event.GetEvent())
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
-	finishTime := storage.EventTime(finishTimeParsed)
-
-	err = s.app.UpdateEvent(ctx, id, userID, event.GetEvent().Title, event.GetEvent().Description, startTime,
-		finishTime, int(event.GetEvent().NotifyBefore), false)
+	err = s.app.UpdateEvent(ctx, id, userID, event.GetEvent().GetVersion(), event.GetEvent().GetTitle(),
+		event.GetEvent().GetDescription(), startTime, finishTime, parseReminders(event.GetEvent()), recurrence,
+		event.GetEvent().GetTimeZone(), event.GetEvent().GetTransparent(), attendees)
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
-		}, err
+		}, statusError(err)
 	}
 
 	return &EventResponse{
@@ -165,6 +206,58 @@ func (s *GRPCServer) Update(ctx context.Context, event *EventWithID) (*EventResp
 	}, nil
 }
 
+func (s *GRPCServer) Get(ctx context.Context, request *EventID) (*EventWithID, error) {
+	id, err := uuid.FromString(request.GetId())
+	if err != nil {
+		return &EventWithID{}, err
+	}
+
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &EventWithID{}, err
+	}
+
+	event, err := s.app.GetEvent(ctx, id, userID)
+	if err != nil {
+		return &EventWithID{}, statusError(err)
+	}
+
+	return eventWithID(event), nil
+}
+
+func (s *GRPCServer) Patch(ctx context.Context, request *PatchEventRequest) (*EventWithID, error) {
+	id, err := uuid.FromString(request.GetId())
+	if err != nil {
+		return &EventWithID{}, err
+	}
+
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &EventWithID{}, err
+	}
+
+	event, err := s.app.GetEvent(ctx, id, userID)
+	if err != nil {
+		return &EventWithID{}, statusError(err)
+	}
+
+	patch, err := eventPatch(request.GetEvent(), request.GetUpdateMask(), event)
+	if err != nil {
+		return &EventWithID{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err = s.app.PatchEvent(ctx, id, userID, request.GetExpectedVersion(), patch); err != nil {
+		return &EventWithID{}, statusError(err)
+	}
+
+	event, err = s.app.GetEvent(ctx, id, userID)
+	if err != nil {
+		return &EventWithID{}, statusError(err)
+	}
+
+	return eventWithID(event), nil
+}
+
 func (s *GRPCServer) Delete(ctx context.Context, event *EventID) (*EventResponse, error) {
 	id, err := uuid.FromString(event.GetId())
 	if err != nil {
@@ -173,13 +266,82 @@ func (s *GRPCServer) Delete(ctx context.Context, event *EventID) (*EventResponse
 		}, err
 	}
 
-	err = s.app.DeleteEvent(ctx, id)
+	userID, err := s.userID(ctx, event.GetUserId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	err = s.app.DeleteEvent(ctx, id, userID, event.GetExpectedVersion())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, statusError(err)
+	}
+
+	return &EventResponse{
+		Result: 1,
+	}, nil
+}
+
+func (s *GRPCServer) RespondToInvitation(ctx context.Context, request *InvitationResponse) (*EventResponse, error) {
+	eventID, err := uuid.FromString(request.GetEventId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	err = s.app.RespondToInvitation(ctx, eventID, userID, storage.AttendeeStatus(request.GetStatus()))
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, statusError(err)
+	}
+
+	return &EventResponse{
+		Result: 1,
+	}, nil
+}
+
+func (s *GRPCServer) SnoozeReminder(ctx context.Context, request *SnoozeReminderRequest) (*EventResponse, error) {
+	eventID, err := uuid.FromString(request.GetEventId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	reminderID, err := uuid.FromString(request.GetReminderId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	userID, err := s.userID(ctx, request.GetUserId())
 	if err != nil {
 		return &EventResponse{
 			Result: 0,
 		}, err
 	}
 
+	duration := time.Duration(request.GetMinutes()) * time.Minute
+	err = s.app.SnoozeReminder(ctx, eventID, reminderID, userID, duration)
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, statusError(err)
+	}
+
 	return &EventResponse{
 		Result: 1,
 	}, nil
@@ -197,10 +359,109 @@ func (s *GRPCServer) ListEventsByMonth(ctx context.Context, request *EventsListR
 	return s.listEventsUntyped(ctx, s.app.ListEventsByMonth, request)
 }
 
+func (s *GRPCServer) ListEvents(ctx context.Context, request *ListEventsRequest) (*ListEventsResponse, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &ListEventsResponse{}, err
+	}
+
+	query, err := s.eventQuery(ctx, userID, request)
+	if err != nil {
+		return &ListEventsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.app.ListEvents(ctx, query)
+	if err != nil {
+		return &ListEventsResponse{}, statusError(err)
+	}
+
+	response := &ListEventsResponse{Events: make([]*EventWithID, 0, len(page.Events))}
+	for _, event := range page.Events {
+		response.Events = append(response.Events, eventWithID(event))
+	}
+
+	if page.Next != nil {
+		response.NextPageToken = page.Next.String()
+	}
+
+	return response, nil
+}
+
+func (s *GRPCServer) Search(ctx context.Context, request *SearchRequest) (*EventsListResponse, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &EventsListResponse{}, err
+	}
+
+	events, err := s.app.SearchEvents(ctx, userID, request.GetQuery(), int(request.GetPageSize()))
+	if err != nil {
+		return &EventsListResponse{}, statusError(err)
+	}
+
+	response := &EventsListResponse{EventsList: make([]*EventWithID, 0, len(events))}
+	for _, event := range events {
+		response.EventsList = append(response.EventsList, eventWithID(event))
+	}
+
+	return response, nil
+}
+
+// eventQuery разбирает параметры выборки событий.
+func (s *GRPCServer) eventQuery(ctx context.Context, userID uuid.UUID,
+	request *ListEventsRequest,
+) (storage.EventQuery, error) {
+	query := storage.EventQuery{
+		UserID:           userID,
+		Title:            request.GetTitle(),
+		NotificationSent: request.NotificationSent,
+		Limit:            int(request.GetPageSize()),
+	}
+
+	loc, err := s.app.Location(ctx, userID, request.GetTimeZone())
+	if err != nil {
+		return query, err
+	}
+
+	if request.GetFrom() != "" {
+		from, err := storage.ParseEventTime(request.GetFrom(), loc)
+		if err != nil {
+			return query, err
+		}
+		query.From = &from
+	}
+
+	if request.GetTo() != "" {
+		to, err := storage.ParseEventTime(request.GetTo(), loc)
+		if err != nil {
+			return query, err
+		}
+		query.To = &to
+	}
+
+	if query.Order, err = storage.ParseSortOrder(request.GetOrder()); err != nil {
+		return query, err
+	}
+
+	if request.GetPageToken() != "" {
+		if query.After, err = storage.ParseEventCursor(request.GetPageToken()); err != nil {
+			return query, err
+		}
+	}
+
+	return query, nil
+}
+
 func (s *GRPCServer) listEventsUntyped(ctx context.Context, fn listEventsFunc,
 	request *EventsListRequest,
 ) (*EventsListResponse, error) {
-	userID, err := uuid.FromString(request.GetUserId())
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &EventsListResponse{
+			EventsList: []*EventWithID{},
+		}, err
+	}
+
+	loc, err := s.app.Location(ctx, userID, request.GetTimeZone())
 	if err != nil {
 		return &EventsListResponse{
 			EventsList: []*EventWithID{},
@@ -208,7 +469,7 @@ func (s *GRPCServer) listEventsUntyped(ctx context.Context, fn listEventsFunc,
 	}
 
 	startDate := request.GetStartDate()
-	dateParsed, err := time.Parse(time.DateOnly, startDate)
+	dateParsed, err := time.ParseInLocation(time.DateOnly, startDate, loc)
 	if err != nil {
 		return &EventsListResponse{
 			EventsList: []*EventWithID{},
@@ -224,19 +485,7 @@ func (s *GRPCServer) listEventsUntyped(ctx context.Context, fn listEventsFunc,
 
 	eventsList := make([]*EventWithID, 0)
 	for _, event := range events {
-		eventStruct := &Event{
-			UserId:       event.UserID.String(),
-			Title:        event.Title,
-			Description:  event.Description,
-			StartTime:    time.Time(event.StartTime).Format(time.DateTime),
-			FinishTime:   time.Time(event.FinishTime).Format(time.DateTime),
-			NotifyBefore: int32(event.NotifyBefore),
-		}
-		eventWithID := &EventWithID{
-			Id:    event.ID.String(),
-			Event: eventStruct,
-		}
-		eventsList = append(eventsList, eventWithID)
+		eventsList = append(eventsList, eventWithID(event))
 	}
 
 	return &EventsListResponse{
@@ -244,6 +493,361 @@ func (s *GRPCServer) listEventsUntyped(ctx context.Context, fn listEventsFunc,
 	}, err
 }
 
+// eventWithID преобразует событие в сообщение gRPC, время события представлено в его часовом поясе.
+func eventWithID(event storage.Event) *EventWithID {
+	eventLoc := event.Location()
+	eventStruct := &Event{
+		UserId:      event.UserID.String(),
+		Title:       event.Title,
+		Description: event.Description,
+		StartTime:   time.Time(event.StartTime).In(eventLoc).Format(time.DateTime),
+		FinishTime:  time.Time(event.FinishTime).In(eventLoc).Format(time.DateTime),
+		Rrule:       event.Recurrence.RRule,
+		TimeZone:    event.TimeZone,
+		Transparent: event.Transparent,
+		Version:     event.Version,
+	}
+	for _, attendee := range event.Attendees {
+		eventStruct.Attendees = append(eventStruct.Attendees, &Attendee{
+			UserId: attendee.UserID.String(),
+			Status: string(attendee.Status),
+		})
+	}
+	for _, reminder := range event.Reminders {
+		message := &Reminder{
+			Id:      reminder.ID.String(),
+			Offset:  int32(reminder.Offset),
+			Channel: string(reminder.Channel),
+		}
+		if reminder.SentAt != nil {
+			message.SentAt = time.Time(*reminder.SentAt).In(eventLoc).Format(time.DateTime)
+		}
+		eventStruct.Reminders = append(eventStruct.Reminders, message)
+	}
+	for _, exDate := range event.Recurrence.ExDates {
+		eventStruct.ExDates = append(eventStruct.ExDates, time.Time(exDate).In(eventLoc).Format(time.DateTime))
+	}
+
+	return &EventWithID{
+		Id:    event.ID.String(),
+		Event: eventStruct,
+	}
+}
+
+func (s *GRPCServer) GetUserSettings(ctx context.Context, request *UserSettingsRequest) (*UserSettings, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &UserSettings{}, err
+	}
+
+	settings, err := s.app.GetUserSettings(ctx, userID)
+	if err != nil {
+		return &UserSettings{}, err
+	}
+
+	return &UserSettings{
+		UserId:   settings.UserID.String(),
+		TimeZone: settings.TimeZone,
+	}, nil
+}
+
+func (s *GRPCServer) UpdateUserSettings(ctx context.Context, settings *UserSettings) (*EventResponse, error) {
+	userID, err := s.userID(ctx, settings.GetUserId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	err = s.app.UpdateUserSettings(ctx, userID, settings.GetTimeZone())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	return &EventResponse{
+		Result: 1,
+	}, nil
+}
+
+func (s *GRPCServer) GetNotificationSettings(ctx context.Context,
+	request *UserSettingsRequest,
+) (*NotificationSettings, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &NotificationSettings{}, err
+	}
+
+	settings, err := s.app.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return &NotificationSettings{}, err
+	}
+
+	return &NotificationSettings{
+		UserId:     settings.UserID.String(),
+		Channel:    string(settings.Channel),
+		Address:    settings.Address,
+		Locale:     settings.Locale,
+		DigestTime: settings.DigestTime,
+	}, nil
+}
+
+func (s *GRPCServer) UpdateNotificationSettings(ctx context.Context,
+	settings *NotificationSettings,
+) (*EventResponse, error) {
+	userID, err := s.userID(ctx, settings.GetUserId())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, err
+	}
+
+	err = s.app.UpdateNotificationSettings(ctx, userID, storage.NotificationChannel(settings.GetChannel()),
+		settings.GetAddress(), settings.GetLocale(), settings.GetDigestTime())
+	if err != nil {
+		return &EventResponse{
+			Result: 0,
+		}, statusError(err)
+	}
+
+	return &EventResponse{
+		Result: 1,
+	}, nil
+}
+
+func (s *GRPCServer) ExportEvents(ctx context.Context, request *ExportEventsRequest) (*ExportEventsResponse, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &ExportEventsResponse{}, err
+	}
+
+	loc, err := s.app.Location(ctx, userID, request.GetTimeZone())
+	if err != nil {
+		return &ExportEventsResponse{}, err
+	}
+
+	startDate, err := time.ParseInLocation(time.DateOnly, request.GetStartDate(), loc)
+	if err != nil {
+		return &ExportEventsResponse{}, err
+	}
+
+	finishDate, err := time.ParseInLocation(time.DateOnly, request.GetFinishDate(), loc)
+	if err != nil {
+		return &ExportEventsResponse{}, err
+	}
+
+	var calendar strings.Builder
+	err = s.app.ExportEvents(ctx, userID, storage.EventDate(startDate), storage.EventDate(finishDate), &calendar)
+	if err != nil {
+		return &ExportEventsResponse{}, err
+	}
+
+	return &ExportEventsResponse{
+		Calendar: calendar.String(),
+	}, nil
+}
+
+func (s *GRPCServer) ImportEvents(ctx context.Context, request *ImportEventsRequest) (*ImportEventsResponse, error) {
+	userID, err := s.userID(ctx, request.GetUserId())
+	if err != nil {
+		return &ImportEventsResponse{}, err
+	}
+
+	report, err := s.app.ImportEvents(ctx, userID, strings.NewReader(request.GetCalendar()))
+	if err != nil {
+		return &ImportEventsResponse{}, err
+	}
+
+	importErrors := make([]*ImportError, 0, len(report.Errors))
+	for _, importError := range report.Errors {
+		importErrors = append(importErrors, &ImportError{
+			Item:    int32(importError.Item),
+			Uid:     importError.UID,
+			Message: importError.Message,
+		})
+	}
+
+	return &ImportEventsResponse{
+		Imported: int32(report.Imported),
+		Errors:   importErrors,
+	}, nil
+}
+
+func (s *GRPCServer) FindFreeSlots(ctx context.Context, request *FreeSlotsRequest) (*FreeSlotsResponse, error) {
+	userIDs := make([]uuid.UUID, 0, len(request.GetUserIds()))
+	for _, value := range request.GetUserIds() {
+		userID, err := uuid.FromString(value)
+		if err != nil {
+			return &FreeSlotsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if userID, found := auth.UserIDFromContext(ctx); found && len(userIDs) == 0 {
+		userIDs = append(userIDs, userID)
+	}
+
+	if len(userIDs) == 0 {
+		return &FreeSlotsResponse{}, statusError(app.ErrNoUsers)
+	}
+
+	loc, err := s.app.Location(ctx, userIDs[0], request.GetTimeZone())
+	if err != nil {
+		return &FreeSlotsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	startDate, err := time.ParseInLocation(time.DateOnly, request.GetStartDate(), loc)
+	if err != nil {
+		return &FreeSlotsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	finishDate, err := time.ParseInLocation(time.DateOnly, request.GetFinishDate(), loc)
+	if err != nil {
+		return &FreeSlotsResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	weekdays := make([]time.Weekday, 0, len(request.GetWeekdays()))
+	for _, weekday := range request.GetWeekdays() {
+		weekdays = append(weekdays, time.Weekday(weekday))
+	}
+
+	workingHours, err := app.ParseWorkingHours(request.GetWorkStart(), request.GetWorkFinish(), weekdays, loc)
+	if err != nil {
+		return &FreeSlotsResponse{}, statusError(err)
+	}
+
+	slots, err := s.app.FindFreeSlots(ctx, userIDs, app.Interval{Start: startDate, Finish: finishDate},
+		time.Duration(request.GetDuration())*time.Minute, workingHours)
+	if err != nil {
+		return &FreeSlotsResponse{}, statusError(err)
+	}
+
+	freeSlots := make([]*FreeSlot, 0, len(slots))
+	for _, slot := range slots {
+		freeSlots = append(freeSlots, &FreeSlot{
+			StartTime:  slot.Start.In(loc).Format(time.RFC3339),
+			FinishTime: slot.Finish.In(loc).Format(time.RFC3339),
+		})
+	}
+
+	return &FreeSlotsResponse{
+		Slots: freeSlots,
+	}, nil
+}
+
+// userID возвращает аутентифицированного пользователя. Поле user_id запроса, если задано,
+// должно совпадать с ним. Если аутентификация отключена, используется user_id запроса.
+func (s *GRPCServer) userID(ctx context.Context, requestUserID string) (uuid.UUID, error) {
+	userID, found := auth.UserIDFromContext(ctx)
+	if !found {
+		if s.auth != nil {
+			return uuid.Nil, status.Error(codes.Unauthenticated, "request is not authenticated")
+		}
+
+		userID, err := uuid.FromString(requestUserID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		logger.AddField(ctx, logger.FieldUserID, userID.String())
+		return userID, nil
+	}
+
+	if requestUserID != "" {
+		requestID, err := uuid.FromString(requestUserID)
+		if err != nil || requestID != userID {
+			return uuid.Nil, status.Error(codes.PermissionDenied, "user_id does not match authenticated user")
+		}
+	}
+
+	return userID, nil
+}
+
+func parseAttendees(event *Event) ([]uuid.UUID, error) {
+	attendees := make([]uuid.UUID, 0, len(event.GetAttendees()))
+	for _, attendee := range event.GetAttendees() {
+		userID, err := uuid.FromString(attendee.GetUserId())
+		if err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, userID)
+	}
+
+	return attendees, nil
+}
+
+func parseReminders(event *Event) []storage.Reminder {
+	reminders := make([]storage.Reminder, 0, len(event.GetReminders()))
+	for _, reminder := range event.GetReminders() {
+		reminders = append(reminders, storage.Reminder{
+			Offset:  int(reminder.GetOffset()),
+			Channel: storage.NotificationChannel(reminder.GetChannel()),
+		})
+	}
+
+	return reminders
+}
+
+// parseEventTimes разбирает время события в формате RFC 3339 или time.DateTime. Время без смещения
+// считается временем в часовом поясе события, а если он не задан - в часовом поясе пользователя.
+func (s *GRPCServer) parseEventTimes(ctx context.Context, userID uuid.UUID, event *Event) (startTime,
+	finishTime storage.EventTime, recurrence storage.Recurrence, err error,
+) {
+	loc, err := s.app.Location(ctx, userID, event.GetTimeZone())
+	if err != nil {
+		return startTime, finishTime, recurrence, err
+	}
+
+	startTime, err = storage.ParseEventTime(event.GetStartTime(), loc)
+	if err != nil {
+		return startTime, finishTime, recurrence, err
+	}
+
+	finishTime, err = storage.ParseEventTime(event.GetFinishTime(), loc)
+	if err != nil {
+		return startTime, finishTime, recurrence, err
+	}
+
+	exDates, err := storage.ParseEventTimes(event.GetExDates(), loc)
+	if err != nil {
+		return startTime, finishTime, recurrence, err
+	}
+
+	recurrence = storage.Recurrence{
+		RRule:   event.GetRrule(),
+		ExDates: exDates,
+	}
+	return startTime, finishTime, recurrence, nil
+}
+
+// statusError преобразует ошибку приложения в ошибку gRPC с соответствующим кодом.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, app.ErrDateBusy):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, app.ErrInvalidPeriod), errors.Is(err, app.ErrInvalidDuration),
+		errors.Is(err, app.ErrInvalidWorkingHours), errors.Is(err, app.ErrNoUsers),
+		errors.Is(err, storage.ErrInvalidAttendeeStatus), errors.Is(err, storage.ErrInvalidRRule),
+		errors.Is(err, app.ErrInvalidLimit), errors.Is(err, app.ErrEmptySearch),
+		errors.Is(err, storage.ErrInvalidNotificationChannel), errors.Is(err, storage.ErrInvalidLocale),
+		errors.Is(err, storage.ErrInvalidReminder), errors.Is(err, app.ErrInvalidSnooze),
+		errors.Is(err, storage.ErrInvalidDigestTime):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, storage.ErrEventNotFound), errors.Is(err, storage.ErrAttendeeNotFound),
+		errors.Is(err, storage.ErrReminderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, storage.ErrReminderNotSent):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, app.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return err
+	}
+}
+
 func (s *GRPCServer) mustEmbedUnimplementedEventServiceServer() {
 	s.logger.Error("unimplemented server")
 }
@@ -2,15 +2,32 @@ package internalgrpc
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/require"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 	initstorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
 )
 
 var eventID string
@@ -31,7 +48,7 @@ func prepareServer() *GRPCServer {
 	}
 
 	calendar := app.New(memorystorage)
-	return NewGRPCServer(logg, calendar, cfg)
+	return NewGRPCServer(logg, calendar, nil, cfg)
 }
 
 func TestServer(t *testing.T) {
@@ -40,12 +57,12 @@ func TestServer(t *testing.T) {
 
 	t.Run("Create rpc test", func(t *testing.T) {
 		request := &Event{
-			UserId:       userID,
-			Title:        "Meeting",
-			Description:  "Very important meeting",
-			StartTime:    "2024-01-02 15:00:00",
-			FinishTime:   "2024-01-02 16:00:00",
-			NotifyBefore: 30,
+			UserId:      userID,
+			Title:       "Meeting",
+			Description: "Very important meeting",
+			StartTime:   "2024-01-02 15:00:00",
+			FinishTime:  "2024-01-02 16:00:00",
+			Reminders:   []*Reminder{{Offset: 30}},
 		}
 
 		response, err := s.Create(ctx, request)
@@ -66,7 +83,8 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "Very important meeting", response.EventsList[0].GetEvent().GetDescription())
 		require.Equal(t, "2024-01-02 15:00:00", response.EventsList[0].GetEvent().GetStartTime())
 		require.Equal(t, "2024-01-02 16:00:00", response.EventsList[0].GetEvent().GetFinishTime())
-		require.Equal(t, 30, int(response.EventsList[0].GetEvent().GetNotifyBefore()))
+		require.Len(t, response.EventsList[0].GetEvent().GetReminders(), 1)
+		require.Equal(t, 30, int(response.EventsList[0].GetEvent().GetReminders()[0].GetOffset()))
 		eventID = response.EventsList[0].GetId()
 	})
 
@@ -74,12 +92,12 @@ func TestServer(t *testing.T) {
 		request := &EventWithID{
 			Id: eventID,
 			Event: &Event{
-				UserId:       userID,
-				Title:        "Wedding",
-				Description:  "Very important wedding",
-				StartTime:    "2024-02-01 15:00:00",
-				FinishTime:   "2024-02-01 16:00:00",
-				NotifyBefore: 60,
+				UserId:      userID,
+				Title:       "Wedding",
+				Description: "Very important wedding",
+				StartTime:   "2024-02-01 15:00:00",
+				FinishTime:  "2024-02-01 16:00:00",
+				Reminders:   []*Reminder{{Offset: 60, Channel: "email"}},
 			},
 		}
 
@@ -101,13 +119,18 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "Very important wedding", response.EventsList[0].GetEvent().GetDescription())
 		require.Equal(t, "2024-02-01 15:00:00", response.EventsList[0].GetEvent().GetStartTime())
 		require.Equal(t, "2024-02-01 16:00:00", response.EventsList[0].GetEvent().GetFinishTime())
-		require.Equal(t, 60, int(response.EventsList[0].GetEvent().GetNotifyBefore()))
+		reminders := response.EventsList[0].GetEvent().GetReminders()
+		require.Len(t, reminders, 1)
+		require.Equal(t, 60, int(reminders[0].GetOffset()))
+		require.Equal(t, "email", reminders[0].GetChannel())
+		require.NotEmpty(t, reminders[0].GetId())
 		eventID = response.EventsList[0].GetId()
 	})
 
 	t.Run("Delete rpc test", func(t *testing.T) {
 		request := &EventID{
-			Id: eventID,
+			Id:     eventID,
+			UserId: userID,
 		}
 
 		response, err := s.Delete(ctx, request)
@@ -126,3 +149,523 @@ func TestServer(t *testing.T) {
 		require.Equal(t, 0, len(response.EventsList))
 	})
 }
+
+func TestServerICalendar(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+
+	t.Run("ImportEvents rpc test", func(t *testing.T) {
+		request := &ImportEventsRequest{
+			UserId: userID,
+			Calendar: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+				"BEGIN:VEVENT\r\nDTSTART:20240302T150000Z\r\nDURATION:PT1H\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nUID:bad\r\nDTSTART:20240302T150000Z\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+		}
+
+		response, err := s.ImportEvents(ctx, request)
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.GetImported()))
+		require.Equal(t, 1, len(response.GetErrors()))
+		require.Equal(t, "bad", response.GetErrors()[0].GetUid())
+	})
+
+	t.Run("ExportEvents rpc test", func(t *testing.T) {
+		request := &ExportEventsRequest{
+			UserId:     userID,
+			StartDate:  "2024-03-01",
+			FinishDate: "2024-04-01",
+		}
+
+		response, err := s.ExportEvents(ctx, request)
+		require.NoError(t, err)
+		require.Contains(t, response.GetCalendar(), "SUMMARY:Meeting\r\n")
+		require.Contains(t, response.GetCalendar(), "DTEND:20240302T160000Z\r\n")
+	})
+}
+
+func TestServerDateBusy(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+
+	t.Run("Create rpc test", func(t *testing.T) {
+		response, err := s.Create(ctx, &Event{
+			UserId:     userID,
+			Title:      "Meeting",
+			StartTime:  "2024-01-02 15:00:00",
+			FinishTime: "2024-01-02 16:00:00",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.Result))
+	})
+
+	t.Run("Create rpc overlapping test", func(t *testing.T) {
+		_, err := s.Create(ctx, &Event{
+			UserId:     userID,
+			Title:      "Lunch",
+			StartTime:  "2024-01-02 15:30:00",
+			FinishTime: "2024-01-02 16:30:00",
+		})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("Create rpc transparent test", func(t *testing.T) {
+		response, err := s.Create(ctx, &Event{
+			UserId:      userID,
+			Title:       "Reminder",
+			StartTime:   "2024-01-02 15:30:00",
+			FinishTime:  "2024-01-02 16:30:00",
+			Transparent: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.Result))
+	})
+}
+
+func TestServerOwnership(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+	const strangerID = "5b7c1f0e-8f4a-4d2b-9c53-2a1d6e0f7b11"
+
+	response, err := s.Create(ctx, &Event{
+		UserId:     userID,
+		Title:      "Meeting",
+		StartTime:  "2024-01-02 15:00:00",
+		FinishTime: "2024-01-02 16:00:00",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, int(response.Result))
+
+	events, err := s.ListEventsByDay(ctx, &EventsListRequest{UserId: userID, StartDate: "2024-01-02"})
+	require.NoError(t, err)
+	require.Len(t, events.EventsList, 1)
+	id := events.EventsList[0].GetId()
+
+	t.Run("Update rpc by another user test", func(t *testing.T) {
+		_, err := s.Update(ctx, &EventWithID{Id: id, Event: &Event{
+			UserId:     strangerID,
+			Title:      "Hijacked",
+			StartTime:  "2024-01-02 15:00:00",
+			FinishTime: "2024-01-02 16:00:00",
+		}})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Delete rpc by another user test", func(t *testing.T) {
+		_, err := s.Delete(ctx, &EventID{Id: id, UserId: strangerID})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Delete rpc by owner test", func(t *testing.T) {
+		response, err := s.Delete(ctx, &EventID{Id: id, UserId: userID})
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.Result))
+	})
+
+	t.Run("Delete rpc not found test", func(t *testing.T) {
+		_, err := s.Delete(ctx, &EventID{Id: id, UserId: userID})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServerGetAndPatch(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+
+	response, err := s.Create(ctx, &Event{
+		UserId:      userID,
+		Title:       "Meeting",
+		Description: "Agenda",
+		StartTime:   "2024-01-02 15:00:00",
+		FinishTime:  "2024-01-02 16:00:00",
+		TimeZone:    "Europe/Berlin",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, int(response.Result))
+
+	events, err := s.ListEventsByDay(ctx, &EventsListRequest{UserId: userID, StartDate: "2024-01-02"})
+	require.NoError(t, err)
+	require.Len(t, events.EventsList, 1)
+	id := events.EventsList[0].GetId()
+
+	t.Run("Get rpc test", func(t *testing.T) {
+		event, err := s.Get(ctx, &EventID{Id: id, UserId: userID})
+		require.NoError(t, err)
+		require.Equal(t, id, event.GetId())
+		require.Equal(t, "Meeting", event.GetEvent().GetTitle())
+		require.Equal(t, "2024-01-02 15:00:00", event.GetEvent().GetStartTime())
+	})
+
+	t.Run("Patch rpc test", func(t *testing.T) {
+		event, err := s.Patch(ctx, &PatchEventRequest{
+			Id:         id,
+			UserId:     userID,
+			Event:      &Event{Title: "Team meeting", Description: "ignored", StartTime: "2024-01-02 14:00:00"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title", "start_time"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Team meeting", event.GetEvent().GetTitle())
+		require.Equal(t, "Agenda", event.GetEvent().GetDescription())
+		require.Equal(t, "2024-01-02 14:00:00", event.GetEvent().GetStartTime())
+		require.Equal(t, "2024-01-02 16:00:00", event.GetEvent().GetFinishTime())
+	})
+
+	t.Run("Patch rpc invalid mask test", func(t *testing.T) {
+		_, err := s.Patch(ctx, &PatchEventRequest{
+			Id:         id,
+			UserId:     userID,
+			Event:      &Event{UserId: userID},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"user_id"}},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("version test", func(t *testing.T) {
+		event, err := s.Get(ctx, &EventID{Id: id, UserId: userID})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), event.GetEvent().GetVersion())
+
+		_, err = s.Update(ctx, &EventWithID{Id: id, Event: &Event{
+			UserId:     userID,
+			Title:      "Stale",
+			StartTime:  "2024-01-02 14:00:00",
+			FinishTime: "2024-01-02 16:00:00",
+			TimeZone:   "Europe/Berlin",
+			Version:    1,
+		}})
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		_, err = s.Patch(ctx, &PatchEventRequest{
+			Id:              id,
+			UserId:          userID,
+			Event:           &Event{Title: "Stale"},
+			UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+			ExpectedVersion: 1,
+		})
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		_, err = s.Delete(ctx, &EventID{Id: id, UserId: userID, ExpectedVersion: 1})
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		response, err := s.Update(ctx, &EventWithID{Id: id, Event: &Event{
+			UserId:     userID,
+			Title:      "Team meeting",
+			StartTime:  "2024-01-02 14:00:00",
+			FinishTime: "2024-01-02 16:00:00",
+			TimeZone:   "Europe/Berlin",
+			Version:    2,
+		}})
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.Result))
+
+		event, err = s.Get(ctx, &EventID{Id: id, UserId: userID})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), event.GetEvent().GetVersion())
+	})
+
+	t.Run("Patch rpc reminders test", func(t *testing.T) {
+		_, err := s.Patch(ctx, &PatchEventRequest{
+			Id:         id,
+			UserId:     userID,
+			Event:      &Event{Reminders: []*Reminder{{Offset: 10}, {Offset: 10}}},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"reminders"}},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		event, err := s.Patch(ctx, &PatchEventRequest{
+			Id:         id,
+			UserId:     userID,
+			Event:      &Event{Reminders: []*Reminder{{Offset: 10}, {Offset: 30}}},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"reminders"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, event.GetEvent().GetReminders(), 2)
+		require.Equal(t, 30, int(event.GetEvent().GetReminders()[0].GetOffset()))
+		require.Empty(t, event.GetEvent().GetReminders()[0].GetSentAt())
+
+		_, err = s.SnoozeReminder(ctx, &SnoozeReminderRequest{
+			EventId:    id,
+			ReminderId: event.GetEvent().GetReminders()[0].GetId(),
+			UserId:     userID,
+			Minutes:    5,
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = s.SnoozeReminder(ctx, &SnoozeReminderRequest{
+			EventId:    id,
+			ReminderId: uuid.Must(uuid.NewV4()).String(),
+			UserId:     userID,
+			Minutes:    5,
+		})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("not found test", func(t *testing.T) {
+		missing := uuid.Must(uuid.NewV4()).String()
+		_, err := s.Get(ctx, &EventID{Id: missing, UserId: userID})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = s.Patch(ctx, &PatchEventRequest{
+			Id:         missing,
+			UserId:     userID,
+			Event:      &Event{Title: "Meeting"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+		})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServerListEvents(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+	for _, day := range []string{"02", "03", "04"} {
+		response, err := s.Create(ctx, &Event{
+			UserId:     userID,
+			Title:      "Meeting " + day,
+			StartTime:  "2024-01-" + day + " 15:00:00",
+			FinishTime: "2024-01-" + day + " 16:00:00",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, int(response.Result))
+	}
+
+	t.Run("ListEvents rpc test", func(t *testing.T) {
+		request := &ListEventsRequest{
+			UserId:   userID,
+			From:     "2024-01-03 00:00:00",
+			Title:    "meeting",
+			PageSize: 1,
+		}
+
+		response, err := s.ListEvents(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, response.GetEvents(), 1)
+		require.Equal(t, "Meeting 03", response.GetEvents()[0].GetEvent().GetTitle())
+		require.NotEmpty(t, response.GetNextPageToken())
+
+		request.PageToken = response.GetNextPageToken()
+		response, err = s.ListEvents(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, response.GetEvents(), 1)
+		require.Equal(t, "Meeting 04", response.GetEvents()[0].GetEvent().GetTitle())
+		require.Empty(t, response.GetNextPageToken())
+	})
+
+	t.Run("ListEvents rpc invalid page token test", func(t *testing.T) {
+		_, err := s.ListEvents(ctx, &ListEventsRequest{UserId: userID, PageToken: "invalid"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestServerSearch(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+	response, err := s.Create(ctx, &Event{
+		UserId:      userID,
+		Title:       "Lunch",
+		Description: "Discuss the budget",
+		StartTime:   "2024-01-02 12:00:00",
+		FinishTime:  "2024-01-02 13:00:00",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, int(response.Result))
+
+	t.Run("Search rpc test", func(t *testing.T) {
+		response, err := s.Search(ctx, &SearchRequest{UserId: userID, Query: "Budget"})
+		require.NoError(t, err)
+		require.Len(t, response.GetEventsList(), 1)
+		require.Equal(t, "Lunch", response.GetEventsList()[0].GetEvent().GetTitle())
+	})
+
+	t.Run("Search rpc empty query test", func(t *testing.T) {
+		_, err := s.Search(ctx, &SearchRequest{UserId: userID})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestServerAuthentication(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DB.Type = "memory"
+	memorystorage, err := initstorage.New(cfg)
+	require.NoError(t, err)
+	authenticator, err := auth.New(config.AuthConf{APIKeys: []config.APIKeyConf{{Key: "s3cr3t", UserID: userID}}})
+	require.NoError(t, err)
+	s := NewGRPCServer(logger.New("info"), app.New(memorystorage), authenticator, cfg)
+	info := &grpc.UnaryServerInfo{FullMethod: "/event.EventService/GetUserSettings"}
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return s.GetUserSettings(ctx, request.(*UserSettingsRequest))
+	}
+
+	t.Run("authInterceptor api key test", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "ApiKey s3cr3t"))
+		response, err := s.authInterceptor(ctx, &UserSettingsRequest{}, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, userID, response.(*UserSettings).GetUserId())
+	})
+
+	t.Run("authInterceptor no credentials test", func(t *testing.T) {
+		_, err := s.authInterceptor(context.Background(), &UserSettingsRequest{UserId: userID}, info, handler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("authInterceptor foreign user_id test", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "ApiKey s3cr3t"))
+		_, err := s.authInterceptor(ctx, &UserSettingsRequest{UserId: "6a1e2f7c-3b8d-4a5e-9f0c-1d2e3f4a5b6c"},
+			info, handler)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestServerTracing(t *testing.T) {
+	recorder := tracingtest.NewRecorder(t)
+	s := prepareServer()
+	info := &grpc.UnaryServerInfo{FullMethod: "/event.EventService/Create"}
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return s.Create(ctx, request.(*Event))
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", tracingtest.TraceParent))
+	_, err := s.tracingInterceptor(ctx, &Event{
+		UserId:     userID,
+		Title:      "Traced",
+		StartTime:  "2024-05-02 15:00:00",
+		FinishTime: "2024-05-02 16:00:00",
+	}, info, handler)
+	require.NoError(t, err)
+
+	span := tracingtest.Span(t, recorder, "event.EventService/Create")
+	require.Equal(t, tracingtest.TraceID, span.SpanContext().TraceID().String())
+	require.Equal(t, tracingtest.SpanID, span.Parent().SpanID().String())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, otelcodes.Unset, span.Status().Code)
+
+	events, err := s.app.ListEventsByDate(context.Background(), uuid.FromStringOrNil(userID),
+		storage.EventDate(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "00-"+tracingtest.TraceID+"-"+span.SpanContext().SpanID().String()+"-01",
+		events[0].TraceParent)
+
+	_, err = s.tracingInterceptor(context.Background(), &Event{}, info,
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "storage is down")
+		})
+	require.Error(t, err)
+	require.Equal(t, otelcodes.Error, recorder.Ended()[len(recorder.Ended())-1].Status().Code)
+}
+
+// unavailableApp - приложение с недоступным хранилищем.
+type unavailableApp struct {
+	Application
+}
+
+func (unavailableApp) Ping(_ context.Context) error {
+	return errors.New("storage is unavailable")
+}
+
+func TestServerHealth(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DB.Type = "memory"
+	memorystorage, err := initstorage.New(cfg)
+	require.NoError(t, err)
+	authenticator, err := auth.New(config.AuthConf{APIKeys: []config.APIKeyConf{{Key: "s3cr3t", UserID: userID}}})
+	require.NoError(t, err)
+
+	// serve запускает сервер с аутентификацией на bufconn и возвращает соединение с ним без учетных данных
+	// и функцию, отменяющую контекст сервера.
+	serve := func(t *testing.T, calendar Application) (*grpc.ClientConn, context.CancelFunc) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		listener := bufconn.Listen(1 << 20)
+		server := NewGRPCServer(logger.New("info"), calendar, authenticator, cfg).newServer(ctx)
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn, cancel
+	}
+
+	// servingStatus возвращает состояние службы service или UNKNOWN, если проверка не удалась.
+	servingStatus := func(conn *grpc.ClientConn, service string) healthpb.HealthCheckResponse_ServingStatus {
+		response, err := healthpb.NewHealthClient(conn).Check(context.Background(),
+			&healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+
+		return response.GetStatus()
+	}
+
+	t.Run("serving without credentials", func(t *testing.T) {
+		conn, cancel := serve(t, app.New(memorystorage))
+		defer cancel()
+
+		require.Eventually(t, func() bool {
+			return servingStatus(conn, "") == healthpb.HealthCheckResponse_SERVING
+		}, time.Second, 5*time.Millisecond)
+		require.Equal(t, healthpb.HealthCheckResponse_SERVING,
+			servingStatus(conn, EventService_ServiceDesc.ServiceName))
+
+		_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		_, err = NewEventServiceClient(conn).GetUserSettings(context.Background(), &UserSettingsRequest{UserId: userID})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		cancel()
+		require.Eventually(t, func() bool {
+			return servingStatus(conn, "") == healthpb.HealthCheckResponse_NOT_SERVING
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("storage is unavailable", func(t *testing.T) {
+		conn, cancel := serve(t, unavailableApp{app.New(memorystorage)})
+		defer cancel()
+
+		require.Eventually(t, func() bool {
+			return servingStatus(conn, EventService_ServiceDesc.ServiceName) == healthpb.HealthCheckResponse_NOT_SERVING
+		}, time.Second, 5*time.Millisecond)
+		require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(conn, ""))
+	})
+}
+
+func TestServerRequestID(t *testing.T) {
+	s := prepareServer()
+	info := &grpc.UnaryServerInfo{FullMethod: "/event.EventService/Get"}
+	eventID := uuid.Must(uuid.NewV4()).String()
+
+	intercept := func(t *testing.T, ctx context.Context, request interface{}) context.Context {
+		t.Helper()
+		var handlerCtx context.Context
+		_, err := s.loggingInterceptor(ctx, request, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			handlerCtx = ctx
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return handlerCtx
+	}
+
+	t.Run("propagated", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "request-1"))
+		ctx = intercept(t, ctx, &EventID{Id: eventID, UserId: userID})
+		require.Equal(t, "request-1", logger.RequestIDFromContext(ctx))
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		first := logger.RequestIDFromContext(intercept(t, context.Background(), &EventID{Id: eventID}))
+		second := logger.RequestIDFromContext(intercept(t, context.Background(), &EventID{Id: eventID}))
+		require.NotEmpty(t, first)
+		require.NotEqual(t, first, second)
+	})
+
+	t.Run("event", func(t *testing.T) {
+		require.Equal(t, eventID, requestEventID(&EventID{Id: eventID}))
+		require.Equal(t, eventID, requestEventID(&SnoozeReminderRequest{EventId: eventID}))
+		require.Empty(t, requestEventID(&UserSettingsRequest{UserId: userID}))
+	})
+}
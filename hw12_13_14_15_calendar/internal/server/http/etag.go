@@ -0,0 +1,122 @@
+package internalhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+var (
+	errIfMatchRequired = errors.New("If-Match header is required")
+	errInvalidIfMatch  = errors.New("invalid If-Match header")
+)
+
+// eventETag возвращает ETag события - его версию в кавычках.
+func eventETag(event storage.Event) string {
+	return strconv.Quote(strconv.FormatInt(event.Version, 10))
+}
+
+// ifMatch - условие заголовка If-Match: любая версия события или одна из перечисленных версий.
+type ifMatch struct {
+	any      bool
+	versions []int64
+}
+
+// parseIfMatch разбирает заголовок If-Match: * или список ETag через запятую (RFC 9110, раздел 13.1.1).
+// If-Match сравнивает ETag строго, поэтому слабые ETag и ETag, которые не являются версией события,
+// допустимы, но не совпадают ни с одной версией.
+func parseIfMatch(r *http.Request) (ifMatch, error) {
+	value := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	switch value {
+	case "":
+		return ifMatch{}, errIfMatchRequired
+	case "*":
+		return ifMatch{any: true}, nil
+	}
+
+	condition, tags := ifMatch{}, 0
+	for {
+		// Пустые элементы списка допустимы и пропускаются.
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			break
+		}
+
+		weak := strings.HasPrefix(value, "W/")
+		value = strings.TrimPrefix(value, "W/")
+		if len(value) < 2 || value[0] != '"' {
+			return ifMatch{}, errInvalidIfMatch
+		}
+
+		end := strings.IndexByte(value[1:], '"') + 1
+		if end == 0 {
+			return ifMatch{}, errInvalidIfMatch
+		}
+
+		tag := value[1:end]
+		value = strings.TrimLeft(value[end+1:], " \t")
+		if value != "" && value[0] != ',' {
+			return ifMatch{}, errInvalidIfMatch
+		}
+
+		tags++
+		if version, err := strconv.ParseInt(tag, 10, 64); err == nil && version > 0 && !weak {
+			condition.versions = append(condition.versions, version)
+		}
+	}
+
+	if tags == 0 {
+		return ifMatch{}, errInvalidIfMatch
+	}
+
+	return condition, nil
+}
+
+// ifMatchVersion возвращает версию события id, которую ожидает заголовок If-Match, или 0 для *.
+// Если версия в заголовке не одна, она выбирается по текущей версии события. Если ни одна не совпала,
+// возвращается storage.ErrVersionMismatch.
+func (s *Server) ifMatchVersion(r *http.Request, id, userID uuid.UUID) (int64, error) {
+	condition, err := parseIfMatch(r)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case condition.any:
+		return 0, nil
+	case len(condition.versions) == 1:
+		return condition.versions[0], nil
+	}
+
+	event, err := s.app.GetEvent(r.Context(), id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, version := range condition.versions {
+		if version == event.Version {
+			return version, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: current version %d does not match If-Match", storage.ErrVersionMismatch,
+		event.Version)
+}
+
+// ifMatchStatus возвращает HTTP-статус ошибки проверки заголовка If-Match.
+func ifMatchStatus(err error) int {
+	switch {
+	case errors.Is(err, errIfMatchRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, errInvalidIfMatch):
+		return http.StatusBadRequest
+	default:
+		return errorStatus(err)
+	}
+}
@@ -3,6 +3,18 @@ package internalhttp
 import (
 	"net/http"
 	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
 )
 
 type ResponseWriter struct {
@@ -15,13 +27,79 @@ func (r *ResponseWriter) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 }
 
+// loggingMiddleware пишет запрос в журнал и учитывает его в метриках. Метка route - шаблон пути
+// маршрута, а не путь запроса, чтобы ID в пути не порождали новые ряды метрик. ID запроса берется
+// из заголовка X-Request-Id или создается, передается в контексте и возвращается в ответе.
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := logger.NewRequestID(r.Header.Get(logger.RequestIDHeader))
+		w.Header().Set(logger.RequestIDHeader, requestID)
+		r = r.WithContext(logger.ContextWithRequestID(r.Context(), requestID))
+		if eventID := mux.Vars(r)["ID"]; eventID != "" {
+			logger.AddField(r.Context(), logger.FieldEventID, eventID)
+		}
+
 		rw := &ResponseWriter{w, 200}
 		start := time.Now()
 		next.ServeHTTP(rw, r)
-		time.Sleep(time.Millisecond * 10)
 		duration := time.Since(start)
 		s.logger.LogHTTPRequest(r, duration, rw.statusCode)
+		metrics.ObserveHTTPRequest(routeTemplate(r), r.Method, rw.statusCode, duration)
+	})
+}
+
+// tracingMiddleware начинает span запроса, продолжая трассировку из заголовков traceparent и baggage.
+// Ответы с кодом 5xx отмечаются в span как ошибки.
+func (s *Server) tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethod(r.Method), semconv.HTTPRoute(route)))
+		defer span.End()
+
+		rw := &ResponseWriter{w, http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPStatusCode(rw.statusCode))
+		if rw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.statusCode))
+		}
 	})
 }
+
+// routeTemplate возвращает шаблон пути маршрута, обработавшего запрос.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unknown"
+}
+
+// authMiddleware аутентифицирует запрос по заголовку Authorization и помещает пользователя в контекст.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.authenticate(r)
+		if err != nil {
+			s.logger.Debug("authentication failed: ", err)
+			w.Header().Set("WWW-Authenticate", auth.SchemeBearer+", "+auth.SchemeAPIKey)
+			s.writeResponse(http.StatusUnauthorized, err.Error(), w)
+			return
+		}
+
+		logger.AddField(r.Context(), logger.FieldUserID, userID.String())
+		next.ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), userID)))
+	})
+}
+
+func (s *Server) authenticate(r *http.Request) (uuid.UUID, error) {
+	scheme, credentials, err := auth.ParseAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.auth.Authenticate(r.Context(), scheme, credentials)
+}
@@ -0,0 +1,206 @@
+package internalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// mergePatchContentType - тип содержимого JSON Merge Patch (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	errUnknownPatchField  = errors.New("unknown field")
+	errRequiredPatchField = errors.New("required field cannot be null")
+)
+
+// EventPatchRequest - тело запроса PATCH /events/{ID} в формате JSON Merge Patch (RFC 7396).
+// Поля называются так же, как в EventRequest. Отсутствующие поля не изменяются,
+// null сбрасывает поле в значение по умолчанию. У времени начала и окончания значения по умолчанию нет,
+// поэтому null в них не допускается.
+type EventPatchRequest map[string]json.RawMessage
+
+// patch возвращает изменения события event. Время без смещения относится к часовому поясу
+// из запроса, а если он не изменяется - к часовому поясу события.
+func (pr EventPatchRequest) patch(event storage.Event) (storage.EventPatch, error) {
+	patch := storage.EventPatch{}
+	for field := range pr {
+		switch field {
+		case "title", "description", "startTime", "finishTime", "reminders", "rrule", "exDates", "timeZone",
+			"transparent", "attendees":
+		default:
+			return patch, fmt.Errorf("%w %q", errUnknownPatchField, field)
+		}
+	}
+
+	loc := event.Location()
+	if raw, found := pr["timeZone"]; found {
+		var timeZone string
+		if err := decodePatchField(raw, "timeZone", &timeZone); err != nil {
+			return patch, err
+		}
+
+		var err error
+		if loc, err = storage.LoadLocation(timeZone); err != nil {
+			return patch, err
+		}
+		patch.TimeZone = &timeZone
+	}
+
+	if err := pr.decodeString("title", &patch.Title); err != nil {
+		return patch, err
+	}
+
+	if err := pr.decodeString("description", &patch.Description); err != nil {
+		return patch, err
+	}
+
+	if raw, found := pr["reminders"]; found {
+		var requests []ReminderRequest
+		if err := decodePatchField(raw, "reminders", &requests); err != nil {
+			return patch, err
+		}
+
+		reminders := reminders(requests)
+		patch.Reminders = &reminders
+	}
+
+	if raw, found := pr["transparent"]; found {
+		var transparent bool
+		if err := decodePatchField(raw, "transparent", &transparent); err != nil {
+			return patch, err
+		}
+		patch.Transparent = &transparent
+	}
+
+	if err := pr.decodeTime("startTime", loc, &patch.StartTime); err != nil {
+		return patch, err
+	}
+
+	if err := pr.decodeTime("finishTime", loc, &patch.FinishTime); err != nil {
+		return patch, err
+	}
+
+	if err := pr.decodeRecurrence(event.Recurrence, loc, &patch); err != nil {
+		return patch, err
+	}
+
+	if raw, found := pr["attendees"]; found {
+		var userIDs []uuid.UUID
+		if err := decodePatchField(raw, "attendees", &userIDs); err != nil {
+			return patch, err
+		}
+
+		attendees := storage.NewAttendees(event.UserID, userIDs, event.Attendees)
+		patch.Attendees = &attendees
+	}
+
+	return patch, nil
+}
+
+// decodeRecurrence изменяет правило повторения и исключенные даты независимо друг от друга.
+func (pr EventPatchRequest) decodeRecurrence(recurrence storage.Recurrence, loc *time.Location,
+	patch *storage.EventPatch,
+) error {
+	rawRRule, rruleFound := pr["rrule"]
+	rawExDates, exDatesFound := pr["exDates"]
+	if !rruleFound && !exDatesFound {
+		return nil
+	}
+
+	if rruleFound {
+		recurrence.RRule = ""
+		if err := decodePatchField(rawRRule, "rrule", &recurrence.RRule); err != nil {
+			return err
+		}
+	}
+
+	if exDatesFound {
+		var exDates []string
+		if err := decodePatchField(rawExDates, "exDates", &exDates); err != nil {
+			return err
+		}
+
+		var err error
+		if recurrence.ExDates, err = storage.ParseEventTimes(exDates, loc); err != nil {
+			return err
+		}
+	}
+
+	patch.Recurrence = &recurrence
+	return nil
+}
+
+func (pr EventPatchRequest) decodeString(field string, target **string) error {
+	raw, found := pr[field]
+	if !found {
+		return nil
+	}
+
+	var value string
+	if err := decodePatchField(raw, field, &value); err != nil {
+		return err
+	}
+	*target = &value
+
+	return nil
+}
+
+func (pr EventPatchRequest) decodeTime(field string, loc *time.Location, target **storage.EventTime) error {
+	raw, found := pr[field]
+	if !found {
+		return nil
+	}
+
+	if isNull(raw) {
+		return fmt.Errorf("%w: %q", errRequiredPatchField, field)
+	}
+
+	var value string
+	if err := decodePatchField(raw, field, &value); err != nil {
+		return err
+	}
+
+	eventTime, err := storage.ParseEventTime(value, loc)
+	if err != nil {
+		return err
+	}
+	*target = &eventTime
+
+	return nil
+}
+
+// decodePatchField разбирает значение поля. Значение null оставляет target нулевым.
+func decodePatchField(raw json.RawMessage, field string, target interface{}) error {
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("invalid value of field %q: %w", field, err)
+	}
+
+	return nil
+}
+
+// isNull проверяет, что значение поля - null.
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// isPatchContentType проверяет тип содержимого запроса PATCH: JSON Merge Patch или JSON.
+func isPatchContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == mergePatchContentType || mediaType == "application/json"
+}
@@ -1,28 +1,41 @@
 package internalhttp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/health"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/ical"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 )
 
 var ErrUserIDHeader = errors.New("error getting userid from header ")
 
+// maxImportSize - наибольший размер документа iCalendar, принимаемого при импорте.
+const maxImportSize = 10 << 20
+
 type Server struct {
 	host   string
 	port   string
 	logger Logger
 	app    Application
+	auth   auth.Authenticator
 	server *http.Server
 }
 
@@ -36,50 +49,114 @@ type Logger interface {
 }
 
 type Application interface {
+	Ping(ctx context.Context) error
 	CreateEvent(ctx context.Context, userID uuid.UUID, title, description string, startTime,
-		finishTime storage.EventTime, notifyBefore int) error
+		finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence, timeZone string,
+		transparent bool, attendees []uuid.UUID) error
 	ListEventsByDate(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
 	ListEventsByWeek(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
 	ListEventsByMonth(ctx context.Context, userID uuid.UUID, date storage.EventDate) ([]storage.Event, error)
-	UpdateEvent(ctx context.Context, ID, userID uuid.UUID, title, description string, startTime,
-		finishTime storage.EventTime, notifyBefore int, notificationSent bool) error
-	DeleteEvent(ctx context.Context, ID uuid.UUID) error
+	ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error)
+	SearchEvents(ctx context.Context, userID uuid.UUID, text string, limit int) ([]storage.Event, error)
+	UpdateEvent(ctx context.Context, ID, userID uuid.UUID, version int64, title, description string, startTime,
+		finishTime storage.EventTime, reminders []storage.Reminder, recurrence storage.Recurrence,
+		timeZone string, transparent bool, attendees []uuid.UUID) error
+	RespondToInvitation(ctx context.Context, eventID, userID uuid.UUID, status storage.AttendeeStatus) error
+	GetEvent(ctx context.Context, id, userID uuid.UUID) (storage.Event, error)
+	PatchEvent(ctx context.Context, id, userID uuid.UUID, version int64, patch storage.EventPatch) error
+	DeleteEvent(ctx context.Context, id, userID uuid.UUID, version int64) error
+	SnoozeReminder(ctx context.Context, eventID, reminderID, userID uuid.UUID, duration time.Duration) error
+	ExportEvents(ctx context.Context, userID uuid.UUID, startDate, finishDate storage.EventDate, w io.Writer) error
+	ImportEvents(ctx context.Context, userID uuid.UUID, r io.Reader) (*app.ImportReport, error)
+	GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error)
+	UpdateUserSettings(ctx context.Context, userID uuid.UUID, timeZone string) error
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (storage.NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, userID uuid.UUID, channel storage.NotificationChannel,
+		address, locale, digestTime string) error
+	Location(ctx context.Context, userID uuid.UUID, timeZone string) (*time.Location, error)
+	FindFreeSlots(ctx context.Context, userIDs []uuid.UUID, period app.Interval, duration time.Duration,
+		workingHours app.WorkingHours) ([]app.Interval, error)
 }
 
+// EventRequest - тело запроса создания и изменения события. Пересечения бесконечной серии RRule
+// с другими событиями проверяются только в первые 366 дней.
 type EventRequest struct {
-	Title        string            `json:"title"`
-	Description  string            `json:"description"`
-	StartTime    storage.EventTime `json:"startTime"`
-	FinishTime   storage.EventTime `json:"finishTime"`
-	NotifyBefore int               `json:"notifyBefore"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	StartTime   storage.EventTime   `json:"startTime"`
+	FinishTime  storage.EventTime   `json:"finishTime"`
+	Reminders   []ReminderRequest   `json:"reminders"`
+	RRule       string              `json:"rrule"`
+	ExDates     []storage.EventTime `json:"exDates"`
+	TimeZone    string              `json:"timeZone"`
+	Transparent bool                `json:"transparent"`
+	Attendees   []uuid.UUID         `json:"attendees"`
+	times       eventRequestTimes
+}
+
+// ReminderRequest - напоминание за Offset минут до начала события. Channel - канал доставки
+// напоминания, по умолчанию - канал из настроек получателя.
+type ReminderRequest struct {
+	Offset  int    `json:"offset"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// SnoozeRequest - количество минут, на которое откладывается напоминание.
+type SnoozeRequest struct {
+	Minutes int `json:"minutes"`
+}
+
+// eventRequestTimes хранит исходные значения времени запроса, чтобы время без смещения
+// можно было отнести к часовому поясу пользователя по умолчанию.
+type eventRequestTimes struct {
+	startTime  string
+	finishTime string
+	exDates    []string
 }
 
 func (er EventRequest) MarshalJSON() ([]byte, error) {
 	var tmp struct {
-		Title        string
-		Description  string
-		StartTime    string
-		FinishTime   string
-		NotifyBefore int
+		Title       string
+		Description string
+		StartTime   string
+		FinishTime  string
+		Reminders   []ReminderRequest `json:",omitempty"`
+		RRule       string            `json:",omitempty"`
+		ExDates     []string          `json:",omitempty"`
+		TimeZone    string            `json:",omitempty"`
+		Transparent bool              `json:",omitempty"`
+		Attendees   []uuid.UUID       `json:",omitempty"`
 	}
 
 	tmp.Title = er.Title
 	tmp.Description = er.Description
-	tmp.StartTime = time.Time(er.StartTime).Format(time.DateTime)
-	tmp.FinishTime = time.Time(er.FinishTime).Format(time.DateTime)
-	tmp.NotifyBefore = er.NotifyBefore
+	tmp.StartTime = time.Time(er.StartTime).Format(time.RFC3339)
+	tmp.FinishTime = time.Time(er.FinishTime).Format(time.RFC3339)
+	tmp.Reminders = er.Reminders
+	tmp.RRule = er.RRule
+	for _, exDate := range er.ExDates {
+		tmp.ExDates = append(tmp.ExDates, time.Time(exDate).Format(time.RFC3339))
+	}
+	tmp.TimeZone = er.TimeZone
+	tmp.Transparent = er.Transparent
+	tmp.Attendees = er.Attendees
+
 	json, err := json.Marshal(tmp)
 	return json, err
 }
 
 func (er *EventRequest) UnmarshalJSON(data []byte) (err error) {
-	var startTime, finishTime time.Time
 	var tmp struct {
-		Title        string
-		Description  string
-		StartTime    string
-		FinishTime   string
-		NotifyBefore int
+		Title       string
+		Description string
+		StartTime   string
+		FinishTime  string
+		Reminders   []ReminderRequest
+		RRule       string
+		ExDates     []string
+		TimeZone    string
+		Transparent bool
+		Attendees   []uuid.UUID
 	}
 	if err = json.Unmarshal(data, &tmp); err != nil {
 		return err
@@ -87,23 +164,94 @@ func (er *EventRequest) UnmarshalJSON(data []byte) (err error) {
 
 	er.Title = tmp.Title
 	er.Description = tmp.Description
-	er.NotifyBefore = tmp.NotifyBefore
-	startTime, err = time.Parse(time.DateTime, tmp.StartTime)
+	er.Reminders = tmp.Reminders
+	er.RRule = tmp.RRule
+	er.TimeZone = tmp.TimeZone
+	er.Transparent = tmp.Transparent
+	er.Attendees = tmp.Attendees
+	er.times = eventRequestTimes{
+		startTime:  tmp.StartTime,
+		finishTime: tmp.FinishTime,
+		exDates:    tmp.ExDates,
+	}
+
+	loc, err := storage.LoadLocation(tmp.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	return er.parseTimes(loc)
+}
+
+// parseTimes разбирает время запроса, время без смещения считается временем в часовом поясе loc.
+func (er *EventRequest) parseTimes(loc *time.Location) (err error) {
+	er.StartTime, err = storage.ParseEventTime(er.times.startTime, loc)
 	if err != nil {
 		return err
 	}
 
-	er.StartTime = storage.EventTime(startTime)
-	finishTime, err = time.Parse(time.DateTime, tmp.FinishTime)
+	er.FinishTime, err = storage.ParseEventTime(er.times.finishTime, loc)
 	if err != nil {
 		return err
 	}
 
-	er.FinishTime = storage.EventTime(finishTime)
-	er.NotifyBefore = tmp.NotifyBefore
+	er.ExDates, err = storage.ParseEventTimes(er.times.exDates, loc)
 	return err
 }
 
+func (er EventRequest) reminders() []storage.Reminder {
+	return reminders(er.Reminders)
+}
+
+func reminders(requests []ReminderRequest) []storage.Reminder {
+	result := make([]storage.Reminder, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, storage.Reminder{
+			Offset:  request.Offset,
+			Channel: storage.NotificationChannel(request.Channel),
+		})
+	}
+
+	return result
+}
+
+func (er EventRequest) recurrence() storage.Recurrence {
+	return storage.Recurrence{
+		RRule:   er.RRule,
+		ExDates: er.ExDates,
+	}
+}
+
+type UserSettingsRequest struct {
+	TimeZone string `json:"timeZone"`
+}
+
+// NotificationSettingsRequest - канал доставки уведомлений: "log", "email" или "webhook",
+// адрес получателя - e-mail или URL вебхука, язык уведомлений, например "ru" (по умолчанию - язык сервера),
+// и местное время ежедневного дайджеста, например "08:00" (по умолчанию дайджест не отправляется).
+type NotificationSettingsRequest struct {
+	Channel    string `json:"channel"`
+	Address    string `json:"address"`
+	Locale     string `json:"locale,omitempty"`
+	DigestTime string `json:"digestTime,omitempty"`
+}
+
+type InvitationResponse struct {
+	Status string `json:"status"`
+}
+
+type FreeSlot struct {
+	StartTime  string `json:"startTime"`
+	FinishTime string `json:"finishTime"`
+}
+
+// EventsPage - страница выборки событий. NextCursor передается в параметре cursor для получения
+// следующей страницы и отсутствует на последней странице.
+type EventsPage struct {
+	Events     []storage.Event `json:"events"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
 type ServerResponse struct {
 	Status  int
 	Message string
@@ -113,30 +261,70 @@ type UserID string
 
 type listEventsFunc func(context.Context, uuid.UUID, storage.EventDate) ([]storage.Event, error)
 
-func NewServer(logger Logger, app Application, cfg *config.Config) *Server {
+// NewServer создает HTTP сервер. Если authenticator равен nil, аутентификация отключена
+// и пользователь берется из заголовка X-User-Id.
+func NewServer(logger Logger, app Application, authenticator auth.Authenticator, cfg *config.Config) *Server {
 	return &Server{
 		host:   cfg.Server.Host,
 		port:   cfg.Server.Port,
 		logger: logger,
 		app:    app,
+		auth:   authenticator,
 	}
 }
 
-func (s *Server) Start(ctx context.Context) error {
-	addr := net.JoinHostPort(s.host, s.port)
+// routes возвращает маршрутизатор со всеми обработчиками сервера.
+func (s *Server) routes() *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/hello", s.helloWorldHandler)
 	router.HandleFunc("/events", s.createEventHandler).Methods("POST")
-	router.HandleFunc("/events/{ID}", s.updateEventHandler).Methods("PUT")
-	router.HandleFunc("/events/{ID}", s.deleteEventHandler).Methods("DELETE")
+	router.HandleFunc("/events", s.listEventsHandler).Methods("GET")
 	router.HandleFunc("/events/bydate", s.listEventsByDateHandler).Methods("GET")
 	router.HandleFunc("/events/byweek", s.listEventsByWeekHandler).Methods("GET")
 	router.HandleFunc("/events/bymonth", s.listEventsByMonthHandler).Methods("GET")
+	router.HandleFunc("/events/search", s.searchEventsHandler).Methods("GET")
+	router.HandleFunc("/events/export", s.exportEventsHandler).Methods("GET")
+	router.HandleFunc("/events/import", s.importEventsHandler).Methods("POST")
+	// Маршруты с ID события регистрируются после статических, чтобы не перехватывать их.
+	router.HandleFunc("/events/{ID}", s.getEventHandler).Methods("GET")
+	router.HandleFunc("/events/{ID}", s.updateEventHandler).Methods("PUT")
+	router.HandleFunc("/events/{ID}", s.patchEventHandler).Methods("PATCH")
+	router.HandleFunc("/events/{ID}", s.deleteEventHandler).Methods("DELETE")
+	router.HandleFunc("/events/{ID}/rsvp", s.respondToInvitationHandler).Methods("POST")
+	router.HandleFunc("/events/{ID}/reminders/{reminderID}/snooze", s.snoozeReminderHandler).Methods("POST")
+	router.HandleFunc("/freebusy", s.freeBusyHandler).Methods("GET")
+	router.HandleFunc("/settings", s.getUserSettingsHandler).Methods("GET")
+	router.HandleFunc("/settings", s.updateUserSettingsHandler).Methods("PUT")
+	router.HandleFunc("/settings/notifications", s.getNotificationSettingsHandler).Methods("GET")
+	router.HandleFunc("/settings/notifications", s.updateNotificationSettingsHandler).Methods("PUT")
+	router.Use(s.tracingMiddleware)
 	router.Use(s.loggingMiddleware)
+	if s.auth != nil {
+		router.Use(s.authMiddleware)
+	}
+
+	return router
+}
+
+// handler возвращает обработчик сервера: служебные пути без аутентификации и маршруты API.
+func (s *Server) handler() http.Handler {
+	handler := http.NewServeMux()
+	handler.Handle("/metrics", metrics.Handler())
+	handler.Handle(health.LivenessPath, health.LivenessHandler())
+	handler.Handle(health.ReadinessPath, health.ReadinessHandler(health.Check{Name: "storage", Check: s.app.Ping}))
+	handler.Handle("/", s.routes())
+	return handler
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	addr := net.JoinHostPort(s.host, s.port)
+	if s.auth == nil {
+		s.logger.Warn("authentication is not configured, trusting X-User-Id header")
+	}
 
 	server := &http.Server{
 		Addr:              addr,
-		Handler:           router,
+		Handler:           s.handler(),
 		ReadHeaderTimeout: time.Second * 5,
 		BaseContext:       func(_ net.Listener) context.Context { return ctx },
 	}
@@ -192,10 +380,15 @@ func (s *Server) createEventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = s.localizeEventRequest(r.Context(), userID, &data); err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
 	err = s.app.CreateEvent(r.Context(), userID, data.Title, data.Description, data.StartTime, data.FinishTime,
-		data.NotifyBefore)
+		data.reminders(), data.recurrence(), data.TimeZone, data.Transparent, data.Attendees)
 	if err != nil {
-		s.writeResponse(http.StatusInternalServerError, err.Error(), w)
+		s.writeResponse(errorStatus(err), err.Error(), w)
 		s.logger.Error(err)
 		return
 	}
@@ -217,6 +410,12 @@ func (s *Server) updateEventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := s.ifMatchVersion(r, id, userID)
+	if err != nil {
+		s.writeResponse(ifMatchStatus(err), err.Error(), w)
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		s.writeResponse(http.StatusBadRequest, "failed to read request body", w)
@@ -231,10 +430,16 @@ func (s *Server) updateEventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.app.UpdateEvent(r.Context(), id, userID, data.Title, data.Description, data.StartTime,
-		data.FinishTime, data.NotifyBefore, false)
+	if err = s.localizeEventRequest(r.Context(), userID, data); err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
+	err = s.app.UpdateEvent(r.Context(), id, userID, version, data.Title, data.Description, data.StartTime,
+		data.FinishTime, data.reminders(), data.recurrence(), data.TimeZone, data.Transparent,
+		data.Attendees)
 	if err != nil {
-		s.writeResponse(http.StatusInternalServerError, err.Error(), w)
+		s.writeResponse(errorStatus(err), err.Error(), w)
 		s.logger.Error(err)
 		return
 	}
@@ -242,8 +447,99 @@ func (s *Server) updateEventHandler(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(http.StatusOK, "event was updated", w)
 }
 
+// Get event handler.
+func (s *Server) getEventHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	id, err := uuid.FromString(mux.Vars(r)["ID"])
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse id path parameter", w)
+		return
+	}
+
+	event, err := s.app.GetEvent(r.Context(), id, userID)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.Header().Set("ETag", eventETag(event))
+	s.writeJSON(event, w)
+}
+
+// Patch event handler.
+func (s *Server) patchEventHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	id, err := uuid.FromString(mux.Vars(r)["ID"])
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse id path parameter", w)
+		return
+	}
+
+	version, err := s.ifMatchVersion(r, id, userID)
+	if err != nil {
+		s.writeResponse(ifMatchStatus(err), err.Error(), w)
+		return
+	}
+
+	if !isPatchContentType(r.Header.Get("Content-Type")) {
+		s.writeResponse(http.StatusUnsupportedMediaType, "content type must be "+mergePatchContentType, w)
+		return
+	}
+
+	data := EventPatchRequest{}
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to unmarshal request body", w)
+		return
+	}
+	defer r.Body.Close()
+
+	event, err := s.app.GetEvent(r.Context(), id, userID)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	patch, err := data.patch(event)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
+	if err = s.app.PatchEvent(r.Context(), id, userID, version, patch); err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	event, err = s.app.GetEvent(r.Context(), id, userID)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.Header().Set("ETag", eventETag(event))
+	s.writeJSON(event, w)
+}
+
 // Delete event handler.
 func (s *Server) deleteEventHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := uuid.FromString(vars["ID"])
 	if err != nil {
@@ -251,9 +547,15 @@ func (s *Server) deleteEventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.app.DeleteEvent(r.Context(), id)
+	version, err := s.ifMatchVersion(r, id, userID)
 	if err != nil {
-		s.writeResponse(http.StatusInternalServerError, err.Error(), w)
+		s.writeResponse(ifMatchStatus(err), err.Error(), w)
+		return
+	}
+
+	err = s.app.DeleteEvent(r.Context(), id, userID, version)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
 		s.logger.Error(err)
 		return
 	}
@@ -261,6 +563,86 @@ func (s *Server) deleteEventHandler(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(http.StatusOK, "event was deleted", w)
 }
 
+// Respond to invitation handler.
+func (s *Server) respondToInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := uuid.FromString(vars["ID"])
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse id path parameter", w)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to read request body", w)
+		return
+	}
+	defer r.Body.Close()
+
+	data := InvitationResponse{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to unmarshal request body", w)
+		return
+	}
+
+	err = s.app.RespondToInvitation(r.Context(), id, userID, storage.AttendeeStatus(data.Status))
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		return
+	}
+
+	s.writeResponse(http.StatusOK, "invitation response was saved", w)
+}
+
+// Snooze reminder handler.
+func (s *Server) snoozeReminderHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := uuid.FromString(vars["ID"])
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse id path parameter", w)
+		return
+	}
+
+	reminderID, err := uuid.FromString(vars["reminderID"])
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse reminderID path parameter", w)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to read request body", w)
+		return
+	}
+	defer r.Body.Close()
+
+	data := SnoozeRequest{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to unmarshal request body", w)
+		return
+	}
+
+	err = s.app.SnoozeReminder(r.Context(), id, reminderID, userID, time.Duration(data.Minutes)*time.Minute)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		return
+	}
+
+	s.writeResponse(http.StatusOK, "reminder was snoozed", w)
+}
+
 // List events by date handler.
 func (s *Server) listEventsByDateHandler(w http.ResponseWriter, r *http.Request) {
 	s.listEventsUntyped(s.app.ListEventsByDate, w, r)
@@ -283,8 +665,14 @@ func (s *Server) listEventsUntyped(fn listEventsFunc, w http.ResponseWriter, r *
 	}
 
 	query := r.URL.Query()
+	loc, err := s.app.Location(r.Context(), userID, query.Get("time_zone"))
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to load time_zone query parameter", w)
+		return
+	}
+
 	startDate := query.Get("start_date")
-	dateParsed, err := time.Parse(time.DateOnly, startDate)
+	dateParsed, err := time.ParseInLocation(time.DateOnly, startDate, loc)
 	if err != nil {
 		s.writeResponse(http.StatusBadRequest, "failed to parse start_date query parameter", w)
 		return
@@ -312,6 +700,434 @@ func (s *Server) listEventsUntyped(fn listEventsFunc, w http.ResponseWriter, r *
 	}
 }
 
+// List events handler.
+func (s *Server) listEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	query, err := s.parseEventQuery(r, userID)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
+	page, err := s.app.ListEvents(r.Context(), query)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	result := EventsPage{Events: page.Events}
+	if page.Next != nil {
+		result.NextCursor = page.Next.String()
+	}
+
+	s.writeJSON(result, w)
+}
+
+// Search events handler.
+func (s *Server) searchEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			s.writeResponse(http.StatusBadRequest, "failed to parse limit query parameter", w)
+			return
+		}
+	}
+
+	events, err := s.app.SearchEvents(r.Context(), userID, query.Get("q"), limit)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	s.writeJSON(events, w)
+}
+
+// parseEventQuery разбирает параметры выборки событий. Время без смещения в параметрах from и to
+// относится к часовому поясу time_zone или к часовому поясу пользователя по умолчанию.
+func (s *Server) parseEventQuery(r *http.Request, userID uuid.UUID) (storage.EventQuery, error) {
+	values := r.URL.Query()
+	query := storage.EventQuery{UserID: userID, Title: values.Get("title")}
+	loc, err := s.app.Location(r.Context(), userID, values.Get("time_zone"))
+	if err != nil {
+		return query, errors.New("failed to load time_zone query parameter")
+	}
+
+	for param, target := range map[string]**storage.EventTime{"from": &query.From, "to": &query.To} {
+		if value := values.Get(param); value != "" {
+			eventTime, err := storage.ParseEventTime(value, loc)
+			if err != nil {
+				return query, fmt.Errorf("failed to parse %s query parameter", param)
+			}
+			*target = &eventTime
+		}
+	}
+
+	if value := values.Get("notification_sent"); value != "" {
+		notificationSent, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, errors.New("failed to parse notification_sent query parameter")
+		}
+		query.NotificationSent = &notificationSent
+	}
+
+	if value := values.Get("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			return query, errors.New("failed to parse limit query parameter")
+		}
+	}
+
+	if query.Order, err = storage.ParseSortOrder(values.Get("sort")); err != nil {
+		return query, err
+	}
+
+	if value := values.Get("cursor"); value != "" {
+		if query.After, err = storage.ParseEventCursor(value); err != nil {
+			return query, err
+		}
+	}
+
+	return query, nil
+}
+
+// Export events handler.
+func (s *Server) exportEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	loc, err := s.app.Location(r.Context(), userID, query.Get("time_zone"))
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to load time_zone query parameter", w)
+		return
+	}
+
+	startDate, err := time.ParseInLocation(time.DateOnly, query.Get("start_date"), loc)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse start_date query parameter", w)
+		return
+	}
+
+	finishDate, err := time.ParseInLocation(time.DateOnly, query.Get("finish_date"), loc)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse finish_date query parameter", w)
+		return
+	}
+
+	var calendar bytes.Buffer
+	err = s.app.ExportEvents(r.Context(), userID, storage.EventDate(startDate), storage.EventDate(finishDate),
+		&calendar)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="calendar.ics"`)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(calendar.Bytes())
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Import events handler.
+func (s *Server) importEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	defer r.Body.Close()
+	report, err := s.app.ImportEvents(r.Context(), userID, http.MaxBytesReader(w, r.Body, maxImportSize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.writeResponse(http.StatusRequestEntityTooLarge, "calendar is too large", w)
+		return
+	}
+
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse calendar: "+err.Error(), w)
+		return
+	}
+
+	res, err := json.Marshal(report)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(res)
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Free/busy handler. Возвращает промежутки, в которые свободны все пользователи user_id
+// (по умолчанию - пользователь из заголовка X-User-Id).
+func (s *Server) freeBusyHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	loc, err := s.app.Location(r.Context(), userID, query.Get("time_zone"))
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to load time_zone query parameter", w)
+		return
+	}
+
+	userIDs := []uuid.UUID{userID}
+	if values, found := query["user_id"]; found {
+		userIDs = make([]uuid.UUID, 0, len(values))
+		for _, value := range values {
+			id, err := uuid.FromString(value)
+			if err != nil {
+				s.writeResponse(http.StatusBadRequest, "failed to parse user_id query parameter", w)
+				return
+			}
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	startDate, err := time.ParseInLocation(time.DateOnly, query.Get("start_date"), loc)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse start_date query parameter", w)
+		return
+	}
+
+	finishDate, err := time.ParseInLocation(time.DateOnly, query.Get("finish_date"), loc)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse finish_date query parameter", w)
+		return
+	}
+
+	duration, err := time.ParseDuration(query.Get("duration"))
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to parse duration query parameter", w)
+		return
+	}
+
+	weekdays := make([]time.Weekday, 0, len(query["weekday"]))
+	for _, value := range query["weekday"] {
+		weekday, err := strconv.Atoi(value)
+		if err != nil {
+			s.writeResponse(http.StatusBadRequest, "failed to parse weekday query parameter", w)
+			return
+		}
+		weekdays = append(weekdays, time.Weekday(weekday))
+	}
+
+	workingHours, err := app.ParseWorkingHours(query.Get("work_start"), query.Get("work_finish"), weekdays, loc)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
+	slots, err := s.app.FindFreeSlots(r.Context(), userIDs, app.Interval{Start: startDate, Finish: finishDate},
+		duration, workingHours)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		return
+	}
+
+	freeSlots := make([]FreeSlot, 0, len(slots))
+	for _, slot := range slots {
+		freeSlots = append(freeSlots, FreeSlot{
+			StartTime:  slot.Start.In(loc).Format(time.RFC3339),
+			FinishTime: slot.Finish.In(loc).Format(time.RFC3339),
+		})
+	}
+
+	res, err := json.Marshal(freeSlots)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(res)
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Get user settings handler.
+func (s *Server) getUserSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	settings, err := s.app.GetUserSettings(r.Context(), userID)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	res, err := json.Marshal(settings)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(res)
+	if err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// Update user settings handler.
+func (s *Server) updateUserSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to read request body", w)
+		return
+	}
+	defer r.Body.Close()
+
+	data := UserSettingsRequest{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to unmarshal request body", w)
+		return
+	}
+
+	err = s.app.UpdateUserSettings(r.Context(), userID, data.TimeZone)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, err.Error(), w)
+		return
+	}
+
+	s.writeResponse(http.StatusOK, "settings were updated", w)
+}
+
+// Get notification settings handler.
+func (s *Server) getNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	settings, err := s.app.GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	s.writeJSON(settings, w)
+}
+
+// Update notification settings handler.
+func (s *Server) updateNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := s.getUserID(w, r)
+	if err != nil {
+		return
+	}
+
+	data := NotificationSettingsRequest{}
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		s.writeResponse(http.StatusBadRequest, "failed to unmarshal request body", w)
+		return
+	}
+	defer r.Body.Close()
+
+	err = s.app.UpdateNotificationSettings(r.Context(), userID, storage.NotificationChannel(data.Channel),
+		data.Address, data.Locale, data.DigestTime)
+	if err != nil {
+		s.writeResponse(errorStatus(err), err.Error(), w)
+		s.logger.Error(err)
+		return
+	}
+
+	s.writeResponse(http.StatusOK, "notification settings were updated", w)
+}
+
+// localizeEventRequest относит время без смещения к часовому поясу пользователя по умолчанию,
+// если часовой пояс не указан в самом запросе.
+func (s *Server) localizeEventRequest(ctx context.Context, userID uuid.UUID, data *EventRequest) error {
+	if data.TimeZone != "" {
+		return nil
+	}
+
+	loc, err := s.app.Location(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+
+	return data.parseTimes(loc)
+}
+
+// errorStatus возвращает HTTP-статус ответа для ошибки приложения.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrDateBusy):
+		return http.StatusConflict
+	case errors.Is(err, app.ErrInvalidPeriod), errors.Is(err, app.ErrInvalidDuration),
+		errors.Is(err, app.ErrInvalidWorkingHours), errors.Is(err, app.ErrNoUsers),
+		errors.Is(err, storage.ErrInvalidAttendeeStatus), errors.Is(err, storage.ErrInvalidRRule),
+		errors.Is(err, app.ErrInvalidLimit), errors.Is(err, app.ErrEmptySearch),
+		errors.Is(err, storage.ErrInvalidNotificationChannel), errors.Is(err, storage.ErrInvalidLocale),
+		errors.Is(err, storage.ErrInvalidReminder), errors.Is(err, app.ErrInvalidSnooze),
+		errors.Is(err, storage.ErrInvalidDigestTime):
+		return http.StatusBadRequest
+	case errors.Is(err, storage.ErrEventNotFound), errors.Is(err, storage.ErrAttendeeNotFound),
+		errors.Is(err, storage.ErrReminderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrReminderNotSent):
+		return http.StatusConflict
+	case errors.Is(err, app.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON записывает значение v в ответ со статусом 200.
+func (s *Server) writeJSON(v interface{}, w http.ResponseWriter) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		s.writeResponse(http.StatusInternalServerError, "internal server error", w)
+		s.logger.Error(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(res); err != nil {
+		s.logger.Error(err)
+	}
+}
+
 func (s *Server) writeResponse(status int, message string, w http.ResponseWriter) {
 	res, err := json.Marshal(ServerResponse{
 		Status:  status,
@@ -332,6 +1148,15 @@ func (s *Server) writeResponse(status int, message string, w http.ResponseWriter
 }
 
 func (s *Server) getUserID(w http.ResponseWriter, r *http.Request) (userID uuid.UUID, err error) {
+	if userID, found := auth.UserIDFromContext(r.Context()); found {
+		return userID, nil
+	}
+
+	if s.auth != nil {
+		s.writeResponse(http.StatusUnauthorized, "request is not authenticated", w)
+		return userID, auth.ErrNoCredentials
+	}
+
 	userIDSlice, found := r.Header["X-User-Id"]
 	if !found {
 		s.writeResponse(http.StatusBadRequest, "x-user-id header is not provided", w)
@@ -349,5 +1174,6 @@ func (s *Server) getUserID(w http.ResponseWriter, r *http.Request) (userID uuid.
 		return userID, ErrUserIDHeader
 	}
 
+	logger.AddField(r.Context(), logger.FieldUserID, userID.String())
 	return userID, nil
 }
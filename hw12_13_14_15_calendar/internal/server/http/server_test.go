@@ -4,21 +4,31 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/app"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/auth"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
 	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
 	initstorage "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage/init"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
 )
 
 var eventID string
@@ -39,7 +49,44 @@ func prepareServer() *Server {
 	}
 
 	calendar := app.New(memorystorage)
-	return NewServer(logg, calendar, cfg)
+	return NewServer(logg, calendar, nil, cfg)
+}
+
+// testServer - запущенный для теста сервер календаря со всеми маршрутами и хранилищем в памяти.
+type testServer struct {
+	*Server
+	url    string
+	client *http.Client
+}
+
+// newTestServer запускает сервер календаря и останавливает его по завершении теста.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	s := prepareServer()
+	server := httptest.NewServer(s.routes())
+	t.Cleanup(server.Close)
+
+	return &testServer{
+		Server: s,
+		url:    server.URL,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// doRequest выполняет запрос от имени пользователя userID. Заголовки header добавляются к запросу
+// и могут заменить X-User-Id.
+func (ts *testServer) doRequest(t *testing.T, method, path, body string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, ts.url+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-User-Id", userID)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	response, err := ts.client.Do(req)
+	require.NoError(t, err)
+	return response
 }
 
 func TestServer(t *testing.T) {
@@ -54,7 +101,7 @@ func TestServer(t *testing.T) {
 			Timeout: 30 * time.Second,
 		}
 		eventReqBodyJSON := `{"title":"Meeting","description":"Very important meeting","startTime": 
-		"2024-01-02 15:00:00","finishTime": "2024-01-02 16:00:00","notifyBefore": 30}`
+		"2024-01-02 15:00:00","finishTime": "2024-01-02 16:00:00","reminders": [{"offset": 30}]}`
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/events",
 			bytes.NewReader([]byte(eventReqBodyJSON)))
 		require.NoError(t, err)
@@ -93,7 +140,8 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "Very important meeting", events[0].Description)
 		require.Equal(t, "2024-01-02 15:00:00", time.Time(events[0].StartTime).Format(time.DateTime))
 		require.Equal(t, "2024-01-02 16:00:00", time.Time(events[0].FinishTime).Format(time.DateTime))
-		require.Equal(t, 30, events[0].NotifyBefore)
+		require.Len(t, events[0].Reminders, 1)
+		require.Equal(t, 30, events[0].Reminders[0].Offset)
 	})
 
 	t.Run("updateEventHandler test", func(t *testing.T) {
@@ -105,11 +153,13 @@ func TestServer(t *testing.T) {
 			Timeout: 30 * time.Second,
 		}
 		eventReqBodyJSON := `{"title":"Wedding","description":"Very important wedding",
-		"startTime": "2024-02-01 15:00:00","finishTime": "2024-02-01 16:00:00","notifyBefore": 60}`
+		"startTime": "2024-02-01 15:00:00","finishTime": "2024-02-01 16:00:00",
+		"reminders": [{"offset": 60}, {"offset": 10, "channel": "email"}]}`
 		req, err := http.NewRequestWithContext(ctx, http.MethodPut, server.URL+"/events/"+eventID,
 			bytes.NewReader([]byte(eventReqBodyJSON)))
 		require.NoError(t, err)
 		req.Header.Add("X-User-Id", userID)
+		req.Header.Add("If-Match", "*")
 		response, err := client.Do(req)
 		require.NoError(t, err)
 		respBody, err := io.ReadAll(response.Body)
@@ -144,7 +194,9 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "Very important wedding", events[0].Description)
 		require.Equal(t, "2024-02-01 15:00:00", time.Time(events[0].StartTime).Format(time.DateTime))
 		require.Equal(t, "2024-02-01 16:00:00", time.Time(events[0].FinishTime).Format(time.DateTime))
-		require.Equal(t, 60, events[0].NotifyBefore)
+		require.Len(t, events[0].Reminders, 2)
+		require.Equal(t, 60, events[0].Reminders[0].Offset)
+		require.Equal(t, storage.ChannelEmail, events[0].Reminders[1].Channel)
 	})
 
 	t.Run("deleteEventHandler test", func(t *testing.T) {
@@ -157,6 +209,8 @@ func TestServer(t *testing.T) {
 		}
 		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, server.URL+"/events/"+eventID, nil)
 		require.NoError(t, err)
+		req.Header.Add("X-User-Id", userID)
+		req.Header.Add("If-Match", `"2"`)
 		response, err := client.Do(req)
 		require.NoError(t, err)
 		respBody, err := io.ReadAll(response.Body)
@@ -187,3 +241,818 @@ func TestServer(t *testing.T) {
 		require.Equal(t, 0, len(events))
 	})
 }
+
+func TestServerICalendar(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("importEventsHandler test", func(t *testing.T) {
+		calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+			"BEGIN:VEVENT\r\nUID:good\r\nDTSTART:20240302T150000Z\r\nDTEND:20240302T160000Z\r\nSUMMARY:Meeting\r\n" +
+			"BEGIN:VALARM\r\nTRIGGER:-PT30M\r\nACTION:DISPLAY\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+			"BEGIN:VEVENT\r\nUID:bad\r\nDTSTART:tomorrow\r\nSUMMARY:Party\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"
+		response := ts.doRequest(t, http.MethodPost, "/events/import", calendar, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		report := &app.ImportReport{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(report))
+		require.Equal(t, 1, report.Imported)
+		require.Equal(t, 1, len(report.Errors))
+		require.Equal(t, 2, report.Errors[0].Item)
+		require.Equal(t, "bad", report.Errors[0].UID)
+	})
+
+	t.Run("importEventsHandler malformed test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events/import", "not a calendar", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("importEventsHandler too large test", func(t *testing.T) {
+		calendar := "BEGIN:VCALENDAR\r\n" + strings.Repeat("X-PADDING:"+strings.Repeat("x", 1000)+"\r\n",
+			maxImportSize/1000) + "END:VCALENDAR\r\n"
+		response := ts.doRequest(t, http.MethodPost, "/events/import", calendar, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+
+	t.Run("exportEventsHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, "/events/export?start_date=2024-03-01&finish_date=2024-04-01",
+			"", nil)
+		respBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, "text/calendar; charset=utf-8", response.Header.Get("Content-Type"))
+		require.Contains(t, string(respBody), "BEGIN:VCALENDAR\r\n")
+		require.Contains(t, string(respBody), "SUMMARY:Meeting\r\n")
+		require.Contains(t, string(respBody), "DTSTART:20240302T150000Z\r\n")
+		require.Contains(t, string(respBody), "TRIGGER:-PT30M\r\n")
+	})
+}
+
+func TestServerDateBusy(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("createEventHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Standup","startTime":"2024-01-01 10:00:00",
+		"finishTime":"2024-01-01 10:30:00","rrule":"FREQ=DAILY"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("createEventHandler overlapping test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting","startTime":"2024-01-03 10:15:00",
+		"finishTime":"2024-01-03 11:00:00"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+
+	t.Run("createEventHandler adjacent test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting","startTime":"2024-01-03 10:30:00",
+		"finishTime":"2024-01-03 11:00:00"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("createEventHandler transparent test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Working from home",
+		"startTime":"2024-01-03 09:00:00","finishTime":"2024-01-03 18:00:00","transparent":true}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+}
+
+func TestServerOwnership(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	const strangerID = "5b7c1f0e-8f4a-4d2b-9c53-2a1d6e0f7b11"
+	asStranger := http.Header{"X-User-Id": {strangerID}, "If-Match": {"*"}}
+	asOwner := http.Header{"If-Match": {"*"}}
+
+	response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting",
+	"startTime":"2024-01-02 15:00:00","finishTime":"2024-01-02 16:00:00"}`, nil)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	events, err := ts.app.ListEventsByDate(ctx, uuid.FromStringOrNil(userID),
+		storage.EventDate(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	path := "/events/" + events[0].ID.String()
+
+	t.Run("updateEventHandler by another user test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPut, path, `{"title":"Hijacked",
+		"startTime":"2024-01-02 15:00:00","finishTime":"2024-01-02 16:00:00"}`, asStranger)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	t.Run("getEventHandler by another user test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, path, "", asStranger)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	t.Run("deleteEventHandler by another user test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodDelete, path, "", asStranger)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	t.Run("deleteEventHandler by owner test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodDelete, path, "", asOwner)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("deleteEventHandler not found test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodDelete, path, "", asOwner)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func TestServerGetAndPatch(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	anyVersion := http.Header{"If-Match": {"*"}}
+	mergePatch := http.Header{"If-Match": {"*"}, "Content-Type": {"application/merge-patch+json"}}
+
+	decodeEvent := func(t *testing.T, response *http.Response) storage.Event {
+		t.Helper()
+		event := storage.Event{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&event))
+		return event
+	}
+
+	response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting","description":"Agenda",
+	"startTime":"2024-01-02 15:00:00","finishTime":"2024-01-02 16:00:00","timeZone":"Europe/Berlin",
+	"rrule":"FREQ=DAILY","exDates":["2024-01-03 15:00:00"]}`, nil)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	events, err := ts.app.ListEventsByDate(ctx, uuid.FromStringOrNil(userID),
+		storage.EventDate(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	path := "/events/" + events[0].ID.String()
+
+	t.Run("getEventHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, path, "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		event := decodeEvent(t, response)
+		require.Equal(t, events[0].ID, event.ID)
+		require.Equal(t, "Meeting", event.Title)
+		require.Equal(t, "Europe/Berlin", event.TimeZone)
+		require.Equal(t, `"1"`, response.Header.Get("ETag"))
+	})
+
+	t.Run("patchEventHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPatch, path,
+			`{"title":"Team meeting","description":null,"startTime":"2024-01-02 14:00:00","rrule":"FREQ=WEEKLY"}`,
+			mergePatch)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		event := decodeEvent(t, response)
+		require.Equal(t, "Team meeting", event.Title)
+		require.Equal(t, "", event.Description)
+		require.Equal(t, "2024-01-02T14:00:00+01:00", time.Time(event.StartTime).Format(time.RFC3339))
+		require.Equal(t, "2024-01-02T16:00:00+01:00", time.Time(event.FinishTime).Format(time.RFC3339))
+		require.Equal(t, "FREQ=WEEKLY", event.Recurrence.RRule)
+		require.Len(t, event.Recurrence.ExDates, 1)
+		require.Equal(t, int64(2), event.Version)
+		require.Equal(t, `"2"`, response.Header.Get("ETag"))
+	})
+
+	t.Run("patchEventHandler If-Match test", func(t *testing.T) {
+		doPatch := func(t *testing.T, header http.Header) *http.Response {
+			t.Helper()
+			return ts.doRequest(t, http.MethodPatch, path, `{"description":"Agenda"}`, header)
+		}
+
+		response := doPatch(t, nil)
+		response.Body.Close()
+		require.Equal(t, http.StatusPreconditionRequired, response.StatusCode)
+
+		response = doPatch(t, http.Header{"If-Match": {"2"}})
+		response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		response = doPatch(t, http.Header{"If-Match": {`"1"`}})
+		response.Body.Close()
+		require.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+
+		response = doPatch(t, http.Header{"If-Match": {`"2"`}})
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, `"3"`, response.Header.Get("ETag"))
+		require.Equal(t, "Agenda", decodeEvent(t, response).Description)
+
+		for _, ifMatch := range []string{`W/"3"`, `"1", "2"`, `"meeting"`, `"0"`} {
+			response = doPatch(t, http.Header{"If-Match": {ifMatch}})
+			response.Body.Close()
+			require.Equal(t, http.StatusPreconditionFailed, response.StatusCode, ifMatch)
+		}
+
+		for _, ifMatch := range []string{`,`, `"1" "3"`, `W/3`, `"3`} {
+			response = doPatch(t, http.Header{"If-Match": {ifMatch}})
+			response.Body.Close()
+			require.Equal(t, http.StatusBadRequest, response.StatusCode, ifMatch)
+		}
+
+		response = doPatch(t, http.Header{"If-Match": {`"1", W/"3",, "3",`}})
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, `"4"`, response.Header.Get("ETag"))
+	})
+
+	t.Run("patchEventHandler unknown field test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPatch, path, `{"userId":"`+userID+`"}`, anyVersion)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("patchEventHandler null time test", func(t *testing.T) {
+		for _, body := range []string{`{"startTime":null}`, `{"finishTime":null}`} {
+			response := ts.doRequest(t, http.MethodPatch, path, body, mergePatch)
+			response.Body.Close()
+			require.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+		}
+	})
+
+	t.Run("patchEventHandler content type test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPatch, path, `{"title":"Meeting"}`,
+			http.Header{"If-Match": {"*"}, "Content-Type": {"text/plain"}})
+		defer response.Body.Close()
+		require.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode)
+	})
+
+	t.Run("not found test", func(t *testing.T) {
+		missing := "/events/" + uuid.Must(uuid.NewV4()).String()
+		response := ts.doRequest(t, http.MethodGet, missing, "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+		response = ts.doRequest(t, http.MethodPatch, missing, `{"title":"Meeting"}`, anyVersion)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func TestServerListEvents(t *testing.T) {
+	ts := newTestServer(t)
+
+	for _, day := range []string{"02", "03", "04"} {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting `+day+`",
+		"startTime":"2024-01-`+day+` 15:00:00","finishTime":"2024-01-`+day+` 16:00:00"}`, nil)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	}
+
+	t.Run("listEventsHandler test", func(t *testing.T) {
+		path := "/events?from=2024-01-01+00:00:00&to=2024-02-01+00:00:00&title=meeting&sort=desc&limit=2"
+		response := ts.doRequest(t, http.MethodGet, path, "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		page := struct {
+			Events     []storage.Event `json:"events"`
+			NextCursor string          `json:"nextCursor"`
+		}{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&page))
+		require.Len(t, page.Events, 2)
+		require.Equal(t, "Meeting 04", page.Events[0].Title)
+		require.Equal(t, "Meeting 03", page.Events[1].Title)
+		require.NotEmpty(t, page.NextCursor)
+
+		response = ts.doRequest(t, http.MethodGet, path+"&cursor="+page.NextCursor, "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		page.NextCursor = ""
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&page))
+		require.Len(t, page.Events, 1)
+		require.Equal(t, "Meeting 02", page.Events[0].Title)
+		require.Empty(t, page.NextCursor)
+	})
+
+	t.Run("listEventsHandler invalid parameters test", func(t *testing.T) {
+		for _, path := range []string{"/events?limit=-1", "/events?sort=random", "/events?cursor=invalid",
+			"/events?notification_sent=maybe", "/events?from=tomorrow"} {
+			response := ts.doRequest(t, http.MethodGet, path, "", nil)
+			response.Body.Close()
+			require.Equal(t, http.StatusBadRequest, response.StatusCode, path)
+		}
+	})
+}
+
+func TestServerRoutes(t *testing.T) {
+	ts := newTestServer(t)
+
+	for _, path := range []string{"/events/bydate", "/events/byweek", "/events/bymonth"} {
+		response := ts.doRequest(t, http.MethodGet, path+"?start_date=2024-01-02", "", nil)
+		respBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode, path)
+		require.Equal(t, "[]", string(respBody), path)
+	}
+}
+
+func TestServerRequestDuration(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+
+	get := func(t *testing.T, path string) string {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, path)
+		return string(body)
+	}
+
+	// value возвращает значение ряда гистограммы длительности запросов /hello.
+	value := func(t *testing.T, metrics, suffix, labels string) string {
+		t.Helper()
+		prefix := fmt.Sprintf(`calendar_http_request_duration_seconds_%s{method="GET",route="/hello",status="200"%s} `,
+			suffix, labels)
+		for _, line := range strings.Split(metrics, "\n") {
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimPrefix(line, prefix)
+			}
+		}
+
+		require.Fail(t, "series not found", prefix)
+		return ""
+	}
+
+	get(t, "/hello")
+	metrics := get(t, "/metrics")
+	// Быстрый обработчик попадает в корзину до 10 мс.
+	require.Equal(t, value(t, metrics, "count", ""), value(t, metrics, "bucket", `,le="0.01"`))
+}
+
+func TestServerSearch(t *testing.T) {
+	ts := newTestServer(t)
+
+	response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Budget review",
+	"startTime":"2024-01-02 15:00:00","finishTime":"2024-01-02 16:00:00"}`, nil)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	t.Run("searchEventsHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, "/events/search?q=budget", "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		events := make([]storage.Event, 0)
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&events))
+		require.Len(t, events, 1)
+		require.Equal(t, "Budget review", events[0].Title)
+	})
+
+	t.Run("searchEventsHandler empty query test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, "/events/search?q=", "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestServerFreeBusy(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("createEventHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Meeting",
+		"startTime":"2024-01-02T12:00:00+03:00","finishTime":"2024-01-02T13:00:00+03:00",
+		"timeZone":"Europe/Moscow"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("freeBusyHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, "/freebusy?user_id="+userID+
+			"&start_date=2024-01-02&finish_date=2024-01-03&duration=30m&work_start=10:00&work_finish=18:00"+
+			"&time_zone=Europe/Moscow", "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		slots := make([]FreeSlot, 0)
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&slots))
+		require.Equal(t, []FreeSlot{
+			{StartTime: "2024-01-02T10:00:00+03:00", FinishTime: "2024-01-02T12:00:00+03:00"},
+			{StartTime: "2024-01-02T13:00:00+03:00", FinishTime: "2024-01-02T18:00:00+03:00"},
+		}, slots)
+	})
+
+	t.Run("freeBusyHandler bad duration test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet,
+			"/freebusy?start_date=2024-01-02&finish_date=2024-01-03&duration=-30m", "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestServerInvitations(t *testing.T) {
+	const inviteeID = "6a1e2f7c-3b8d-4a5e-9f0c-1d2e3f4a5b6c"
+	ts := newTestServer(t)
+	asInvitee := http.Header{"X-User-Id": {inviteeID}}
+
+	t.Run("createEventHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Planning",
+		"startTime":"2024-01-02 10:00:00","finishTime":"2024-01-02 11:00:00","attendees":["`+inviteeID+`"]}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("listEventsByWeekHandler invitee test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodGet, "/events/byweek?start_date=2024-01-01", "", asInvitee)
+		defer response.Body.Close()
+		events := make([]storage.Event, 0)
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&events))
+		require.Equal(t, 1, len(events))
+		require.Equal(t, "Planning", events[0].Title)
+		require.Equal(t, storage.NeedsAction, events[0].Attendees[0].Status)
+		eventID = events[0].ID.String()
+	})
+
+	t.Run("respondToInvitationHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events/"+eventID+"/rsvp", `{"status":"accepted"}`, asInvitee)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("respondToInvitationHandler invalid status test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events/"+eventID+"/rsvp", `{"status":"maybe"}`, asInvitee)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("respondToInvitationHandler not invited test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events/"+eventID+"/rsvp", `{"status":"accepted"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	t.Run("invitee is busy after accepting", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Lunch",
+		"startTime":"2024-01-02 10:30:00","finishTime":"2024-01-02 11:30:00"}`, asInvitee)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+}
+
+func TestServerReminders(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+	mergePatch := http.Header{"If-Match": {"*"}, "Content-Type": {"application/merge-patch+json"}}
+
+	startTime := time.Now().UTC().Add(10 * time.Minute).Format(time.RFC3339)
+	finishTime := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	response := ts.doRequest(t, http.MethodPost, "/events", `{"title":"Standup","startTime":"`+startTime+
+		`","finishTime":"`+finishTime+`","reminders":[{"offset":15},{"offset":5,"channel":"log"}]}`, nil)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	events, err := ts.app.SearchEvents(ctx, uuid.FromStringOrNil(userID), "standup", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	path := "/events/" + events[0].ID.String()
+	reminderID := events[0].Reminders[0].ID.String()
+	snoozePath := path + "/reminders/" + reminderID + "/snooze"
+
+	t.Run("invalid reminder test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPatch, path, `{"reminders":[{"offset":15},{"offset":15}]}`,
+			mergePatch)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("snoozeReminderHandler not sent test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPost, snoozePath, `{"minutes":5}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+
+	t.Run("snoozeReminderHandler test", func(t *testing.T) {
+		_, err := ts.app.(*app.App).EnqueueNotifications(ctx)
+		require.NoError(t, err)
+
+		response := ts.doRequest(t, http.MethodPost, snoozePath, `{"minutes":5}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("snoozeReminderHandler invalid request test", func(t *testing.T) {
+		tests := []struct {
+			path   string
+			body   string
+			status int
+		}{
+			{path: snoozePath, body: `{"minutes":0}`, status: http.StatusBadRequest},
+			{path: snoozePath, body: `{"minutes":1441}`, status: http.StatusBadRequest},
+			{path: path + "/reminders/first/snooze", body: `{"minutes":5}`, status: http.StatusBadRequest},
+			{
+				path:   path + "/reminders/" + uuid.Must(uuid.NewV4()).String() + "/snooze",
+				body:   `{"minutes":5}`,
+				status: http.StatusNotFound,
+			},
+		}
+
+		for _, tc := range tests {
+			response := ts.doRequest(t, http.MethodPost, tc.path, tc.body, nil)
+			response.Body.Close()
+			require.Equal(t, tc.status, response.StatusCode, tc)
+		}
+	})
+
+	t.Run("patch reminders test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPatch, path, `{"reminders":[{"offset":15},{"offset":30}]}`,
+			mergePatch)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		event := storage.Event{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&event))
+		require.Len(t, event.Reminders, 2)
+		require.Equal(t, 30, event.Reminders[0].Offset)
+		require.Equal(t, reminderID, event.Reminders[1].ID.String())
+		require.NotNil(t, event.Reminders[1].SentAt)
+	})
+}
+
+func TestServerNotificationSettings(t *testing.T) {
+	ts := newTestServer(t)
+	const path = "/settings/notifications"
+
+	t.Run("updateNotificationSettingsHandler test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPut, path,
+			`{"channel":"email","address":"alice@example.com","locale":"ru","digestTime":"08:00"}`, nil)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		response = ts.doRequest(t, http.MethodGet, path, "", nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		settings := storage.NotificationSettings{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&settings))
+		require.Equal(t, storage.ChannelEmail, settings.Channel)
+		require.Equal(t, "alice@example.com", settings.Address)
+		require.Equal(t, "ru", settings.Locale)
+		require.Equal(t, "08:00", settings.DigestTime)
+	})
+
+	t.Run("updateNotificationSettingsHandler invalid channel test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPut, path, `{"channel":"sms","address":"+10000000000"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("updateNotificationSettingsHandler invalid digest time test", func(t *testing.T) {
+		response := ts.doRequest(t, http.MethodPut, path, `{"channel":"log","digestTime":"8 am"}`, nil)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestServerAuthentication(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DB.Type = "memory"
+	memorystorage, err := initstorage.New(cfg)
+	require.NoError(t, err)
+	authenticator, err := auth.New(config.AuthConf{APIKeys: []config.APIKeyConf{{Key: "s3cr3t", UserID: userID}}})
+	require.NoError(t, err)
+	s := NewServer(logger.New("info"), app.New(memorystorage), authenticator, cfg)
+
+	ctx := context.Background()
+	router := mux.NewRouter()
+	router.HandleFunc("/settings", s.getUserSettingsHandler).Methods("GET")
+	router.Use(s.authMiddleware)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	client := http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	getSettings := func(t *testing.T, header, value string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/settings", nil)
+		require.NoError(t, err)
+		req.Header.Add(header, value)
+		response, err := client.Do(req)
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("authMiddleware api key test", func(t *testing.T) {
+		response := getSettings(t, "Authorization", "ApiKey s3cr3t")
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		settings := storage.UserSettings{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&settings))
+		require.Equal(t, userID, settings.UserID.String())
+	})
+
+	t.Run("authMiddleware invalid key test", func(t *testing.T) {
+		response := getSettings(t, "Authorization", "ApiKey wrong")
+		defer response.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		require.NotEmpty(t, response.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("authMiddleware ignores X-User-Id test", func(t *testing.T) {
+		response := getSettings(t, "X-User-Id", userID)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+}
+
+func TestServerMetrics(t *testing.T) {
+	s := prepareServer()
+	ctx := context.Background()
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	client := http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	get := func(t *testing.T, path string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Add("X-User-Id", userID)
+		response, err := client.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		respBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(respBody)
+	}
+
+	status, _ := get(t, "/events/"+uuid.Must(uuid.NewV4()).String())
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, body := get(t, "/metrics")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `calendar_http_requests_total{method="GET",route="/events/{ID}",status="404"}`)
+	require.Contains(t, body, `calendar_http_request_duration_seconds_bucket{method="GET",route="/events/{ID}"`)
+}
+
+func TestServerTracing(t *testing.T) {
+	recorder := tracingtest.NewRecorder(t)
+	s := prepareServer()
+	ctx := context.Background()
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	client := http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	eventReqBodyJSON := `{"title":"Traced","startTime":"2024-05-02 15:00:00","finishTime":"2024-05-02 16:00:00"}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/events",
+		bytes.NewReader([]byte(eventReqBodyJSON)))
+	require.NoError(t, err)
+	req.Header.Add("X-User-Id", userID)
+	req.Header.Add("traceparent", tracingtest.TraceParent)
+	response, err := client.Do(req)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	span := tracingtest.Span(t, recorder, "POST /events")
+	require.Equal(t, tracingtest.TraceID, span.SpanContext().TraceID().String())
+	require.Equal(t, tracingtest.SpanID, span.Parent().SpanID().String())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Contains(t, span.Attributes(), semconv.HTTPStatusCode(http.StatusOK))
+
+	events, err := s.app.ListEventsByDate(ctx, uuid.FromStringOrNil(userID),
+		storage.EventDate(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "00-"+tracingtest.TraceID+"-"+span.SpanContext().SpanID().String()+"-01",
+		events[0].TraceParent)
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events/"+events[0].ID.String(), nil)
+	require.NoError(t, err)
+	req.Header.Add("X-User-Id", userID)
+	response, err = client.Do(req)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	span = tracingtest.Span(t, recorder, "GET /events/{ID}")
+	require.False(t, span.Parent().IsValid())
+	require.NotEqual(t, tracingtest.TraceID, span.SpanContext().TraceID().String())
+}
+
+// unavailableApp - приложение с недоступным хранилищем.
+type unavailableApp struct {
+	Application
+}
+
+func (unavailableApp) Ping(_ context.Context) error {
+	return errors.New("storage is unavailable")
+}
+
+func TestServerHealth(t *testing.T) {
+	ctx := context.Background()
+	get := func(t *testing.T, s *Server, path string) (int, string) {
+		t.Helper()
+		server := httptest.NewServer(s.handler())
+		defer server.Close()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		return response.StatusCode, string(body)
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		s := prepareServer()
+		status, body := get(t, s, "/healthz")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"status":"ok"}`, body)
+
+		status, body = get(t, s, "/readyz")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"status":"ok","checks":{"storage":"ok"}}`, body)
+	})
+
+	t.Run("storage is unavailable", func(t *testing.T) {
+		s := prepareServer()
+		s.app = unavailableApp{s.app}
+		status, body := get(t, s, "/healthz")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"status":"ok"}`, body)
+
+		status, body = get(t, s, "/readyz")
+		require.Equal(t, http.StatusServiceUnavailable, status)
+		require.JSONEq(t, `{"status":"not ready","checks":{"storage":"storage is unavailable"}}`, body)
+	})
+}
+
+func TestServerRequestID(t *testing.T) {
+	s := prepareServer()
+	logFile := filepath.Join(t.TempDir(), "calendar.log")
+	_, err := logger.NewFromConfig(config.LoggerConf{Level: "info", Format: logger.FormatJSON, Output: logFile})
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.New("info") })
+
+	ctx := context.Background()
+	server := httptest.NewServer(s.routes())
+	defer server.Close()
+	eventID := uuid.Must(uuid.NewV4()).String()
+
+	get := func(t *testing.T, requestID string) string {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events/"+eventID, nil)
+		require.NoError(t, err)
+		req.Header.Add("X-User-Id", userID)
+		if requestID != "" {
+			req.Header.Add("X-Request-Id", requestID)
+		}
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusNotFound, response.StatusCode)
+		return response.Header.Get("X-Request-Id")
+	}
+
+	require.Equal(t, "request-1", get(t, "request-1"))
+	generated := get(t, "")
+	require.NotEmpty(t, generated)
+	require.NotEqual(t, generated, get(t, ""))
+	require.NotEqual(t, "invalid", get(t, "invalid request id"))
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	var record map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record[logger.FieldRequestID] == "request-1" {
+			break
+		}
+	}
+
+	require.Equal(t, "http request", record["msg"])
+	require.Equal(t, "request-1", record[logger.FieldRequestID])
+	require.Equal(t, userID, record[logger.FieldUserID])
+	require.Equal(t, eventID, record[logger.FieldEventID])
+	require.Equal(t, http.MethodGet, record[logger.FieldMethod])
+	require.InDelta(t, float64(http.StatusNotFound), record[logger.FieldStatus], 0)
+}
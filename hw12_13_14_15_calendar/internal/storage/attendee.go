@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+type AttendeeStatus string
+
+// Статусы участника события (RFC 5545, PARTSTAT).
+const (
+	NeedsAction AttendeeStatus = "needs-action"
+	Accepted    AttendeeStatus = "accepted"
+	Declined    AttendeeStatus = "declined"
+	Tentative   AttendeeStatus = "tentative"
+)
+
+var (
+	ErrAttendeeNotFound      = errors.New("attendee not found")
+	ErrInvalidAttendeeStatus = errors.New("invalid attendee status")
+)
+
+type Attendee struct {
+	UserID uuid.UUID      // ID приглашенного пользователя
+	Status AttendeeStatus // Ответ на приглашение
+}
+
+// ParseAttendeeStatus проверяет и возвращает статус участника.
+func ParseAttendeeStatus(value string) (AttendeeStatus, error) {
+	status := AttendeeStatus(value)
+	switch status {
+	case NeedsAction, Accepted, Declined, Tentative:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidAttendeeStatus, value)
+	}
+}
+
+// NewAttendees возвращает список участников для пользователей userIDs. Статусы участников,
+// которые уже были приглашены в previous, сохраняются, новые участники получают статус NeedsAction.
+// Владелец события ownerID и повторы из списка исключаются.
+func NewAttendees(ownerID uuid.UUID, userIDs []uuid.UUID, previous []Attendee) []Attendee {
+	statuses := make(map[uuid.UUID]AttendeeStatus, len(previous))
+	for _, attendee := range previous {
+		statuses[attendee.UserID] = attendee.Status
+	}
+
+	result := make([]Attendee, 0, len(userIDs))
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == ownerID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		status, found := statuses[userID]
+		if !found {
+			status = NeedsAction
+		}
+		result = append(result, Attendee{UserID: userID, Status: status})
+	}
+
+	return result
+}
+
+// Attendee возвращает участника события userID.
+func (e Event) Attendee(userID uuid.UUID) (Attendee, bool) {
+	for _, attendee := range e.Attendees {
+		if attendee.UserID == userID {
+			return attendee, true
+		}
+	}
+
+	return Attendee{}, false
+}
+
+// IsVisibleTo возвращает true, если событие видно пользователю: он владелец события
+// или приглашен и не отклонил приглашение.
+func (e Event) IsVisibleTo(userID uuid.UUID) bool {
+	if e.UserID == userID {
+		return true
+	}
+
+	attendee, found := e.Attendee(userID)
+	return found && attendee.Status != Declined
+}
+
+// IsBusyFor возвращает true, если событие занимает время пользователя: он владелец события
+// или принял приглашение. Прозрачные события время не занимают.
+func (e Event) IsBusyFor(userID uuid.UUID) bool {
+	if e.Transparent {
+		return false
+	}
+
+	if e.UserID == userID {
+		return true
+	}
+
+	attendee, found := e.Attendee(userID)
+	return found && attendee.Status == Accepted
+}
+
+// Recipients возвращает пользователей, которым отправляется напоминание о событии:
+// владельца и участников, принявших приглашение.
+func (e Event) Recipients() []uuid.UUID {
+	result := []uuid.UUID{e.UserID}
+	for _, attendee := range e.Attendees {
+		if attendee.Status == Accepted {
+			result = append(result, attendee.UserID)
+		}
+	}
+
+	return result
+}
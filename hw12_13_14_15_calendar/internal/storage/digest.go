@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// DigestTimeLayout - формат времени отправки ежедневного дайджеста в часовом поясе пользователя.
+const DigestTimeLayout = "15:04"
+
+// Виды уведомлений.
+const (
+	NotificationReminder NotificationType = "reminder" // Напоминание о событии
+	NotificationDigest   NotificationType = "digest"   // Ежедневный дайджест событий
+)
+
+var ErrInvalidDigestTime = errors.New("invalid digest time")
+
+type NotificationType string
+
+// AgendaItem - событие (повторение события) в ежедневном дайджесте.
+type AgendaItem struct {
+	EventID    uuid.UUID // ID события
+	Title      string    // Название события
+	StartTime  EventTime // Дата и время начала события (повторения события)
+	FinishTime EventTime // Дата и время окончания события (повторения события)
+	Recurring  bool      // Признак повторяющегося события
+}
+
+// DigestSubscriber - пользователь, включивший ежедневный дайджест.
+type DigestSubscriber struct {
+	UserID     uuid.UUID // ID пользователя
+	TimeZone   string    // Часовой пояс пользователя (IANA)
+	DigestTime string    // Время отправки дайджеста в формате DigestTimeLayout
+	LastDigest string    // Дата последнего отправленного дайджеста (2006-01-02), пустая, если дайджестов не было
+}
+
+// DueDigest возвращает дату дайджеста, который пора отправить в момент now: текущую дату в часовом поясе
+// пользователя, если время отправки уже наступило, а дайджест за эту дату еще не отправлялся.
+func (d DigestSubscriber) DueDigest(now time.Time) (EventDate, bool) {
+	digestTime, err := time.Parse(DigestTimeLayout, d.DigestTime)
+	if err != nil {
+		return EventDate{}, false
+	}
+
+	local := now.In(UserSettings{TimeZone: d.TimeZone}.Location())
+	year, month, day := local.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, local.Location())
+	sendAt := time.Date(year, month, day, digestTime.Hour(), digestTime.Minute(), 0, 0, local.Location())
+	if local.Before(sendAt) || d.LastDigest >= date.Format(time.DateOnly) {
+		return EventDate{}, false
+	}
+
+	return EventDate(date), true
+}
+
+// ValidateDigestTime проверяет время отправки дайджеста, пустое значение отключает дайджест.
+func ValidateDigestTime(digestTime string) error {
+	if digestTime == "" {
+		return nil
+	}
+
+	// Разбор по формату "15:04" допускает час из одной цифры, поэтому длина проверяется отдельно.
+	if _, err := time.Parse(DigestTimeLayout, digestTime); err != nil || len(digestTime) != len(DigestTimeLayout) {
+		return fmt.Errorf("%w %q: expected HH:MM", ErrInvalidDigestTime, digestTime)
+	}
+
+	return nil
+}
+
+// DigestKey возвращает ключ дедупликации дайджеста пользователю userID за дату date.
+func DigestKey(userID uuid.UUID, date EventDate) string {
+	return fmt.Sprintf("digest:%s:%s", userID, time.Time(date).Format(time.DateOnly))
+}
+
+// NewDigestMessage возвращает дайджест событий events пользователю userID за дату date.
+// События в дайджесте упорядочены по времени начала.
+func NewDigestMessage(userID uuid.UUID, date EventDate, events []Event) OutboxMessage {
+	agenda := make([]AgendaItem, 0, len(events))
+	for _, event := range events {
+		agenda = append(agenda, AgendaItem{
+			EventID:    event.ID,
+			Title:      event.Title,
+			StartTime:  event.StartTime,
+			FinishTime: event.FinishTime,
+			Recurring:  event.Recurrence.IsRecurring(),
+		})
+	}
+
+	sort.SliceStable(agenda, func(i, j int) bool {
+		return time.Time(agenda[i].StartTime).Before(time.Time(agenda[j].StartTime))
+	})
+
+	return OutboxMessage{
+		Key:       DigestKey(userID, date),
+		Type:      NotificationDigest,
+		UserID:    userID,
+		StartTime: EventTime(date),
+		Agenda:    agenda,
+	}
+}
+
+func (a AgendaItem) MarshalJSON() ([]byte, error) {
+	var tmp struct {
+		EventID    string
+		Title      string
+		StartTime  string
+		FinishTime string
+		Recurring  bool
+	}
+
+	tmp.EventID = a.EventID.String()
+	tmp.Title = a.Title
+	tmp.StartTime = time.Time(a.StartTime).Format(time.RFC3339)
+	tmp.FinishTime = time.Time(a.FinishTime).Format(time.RFC3339)
+	tmp.Recurring = a.Recurring
+	return json.Marshal(tmp)
+}
+
+func (a *AgendaItem) UnmarshalJSON(data []byte) (err error) {
+	var tmp struct {
+		EventID    string
+		Title      string
+		StartTime  string
+		FinishTime string
+		Recurring  bool
+	}
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if a.EventID, err = uuid.FromString(tmp.EventID); err != nil {
+		return err
+	}
+
+	a.Title = tmp.Title
+	a.Recurring = tmp.Recurring
+	if a.StartTime, err = ParseEventTime(tmp.StartTime, time.UTC); err != nil {
+		return err
+	}
+
+	a.FinishTime, err = ParseEventTime(tmp.FinishTime, time.UTC)
+	return err
+}
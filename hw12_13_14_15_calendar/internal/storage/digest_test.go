@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueDigest(t *testing.T) {
+	tokyo, err := LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 2024-01-02 00:30 UTC - 09:30 в Токио.
+	now := time.Date(2024, time.January, 2, 0, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		subscriber DigestSubscriber
+		due        bool
+		date       time.Time
+	}{
+		{
+			name:       "digest time in user zone",
+			subscriber: DigestSubscriber{TimeZone: "Asia/Tokyo", DigestTime: "09:00"},
+			due:        true,
+			date:       time.Date(2024, time.January, 2, 0, 0, 0, 0, tokyo),
+		},
+		{
+			name:       "digest time not reached",
+			subscriber: DigestSubscriber{TimeZone: "Asia/Tokyo", DigestTime: "10:00"},
+		},
+		{
+			name:       "default zone",
+			subscriber: DigestSubscriber{DigestTime: "00:15"},
+			due:        true,
+			date:       time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "already sent",
+			subscriber: DigestSubscriber{TimeZone: "Asia/Tokyo", DigestTime: "09:00", LastDigest: "2024-01-02"},
+		},
+		{
+			name:       "sent yesterday",
+			subscriber: DigestSubscriber{TimeZone: "Asia/Tokyo", DigestTime: "09:00", LastDigest: "2024-01-01"},
+			due:        true,
+			date:       time.Date(2024, time.January, 2, 0, 0, 0, 0, tokyo),
+		},
+		{
+			name:       "disabled",
+			subscriber: DigestSubscriber{TimeZone: "Asia/Tokyo"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			date, due := tc.subscriber.DueDigest(now)
+			require.Equal(t, tc.due, due)
+			if tc.due {
+				require.True(t, time.Time(date).Equal(tc.date), time.Time(date))
+			}
+		})
+	}
+}
+
+func TestValidateDigestTime(t *testing.T) {
+	for _, valid := range []string{"", "00:00", "08:30", "23:59"} {
+		require.NoError(t, ValidateDigestTime(valid), valid)
+	}
+
+	for _, invalid := range []string{"8:30", "24:00", "08:60", "08:30:00", "morning"} {
+		require.ErrorIs(t, ValidateDigestTime(invalid), ErrInvalidDigestTime, invalid)
+	}
+}
+
+func TestNewDigestMessage(t *testing.T) {
+	userID := uuid.Must(uuid.NewV4())
+	day := EventDate(date(2024, time.January, 2, 0))
+	lunch := Event{ID: uuid.Must(uuid.NewV4()), Title: "Lunch", StartTime: EventTime(date(2024, time.January, 2, 12))}
+	standup := recurringEvent("FREQ=DAILY")
+	standup.ID = uuid.Must(uuid.NewV4())
+
+	message := NewDigestMessage(userID, day, []Event{lunch, standup})
+	require.Equal(t, NotificationDigest, message.Type)
+	require.Equal(t, "digest:"+userID.String()+":2024-01-02", message.Key)
+	require.Equal(t, userID, message.UserID)
+	require.Equal(t, EventTime(day), message.StartTime)
+	require.Len(t, message.Agenda, 2)
+	require.Equal(t, standup.ID, message.Agenda[0].EventID)
+	require.True(t, message.Agenda[0].Recurring)
+	require.Equal(t, "Lunch", message.Agenda[1].Title)
+
+	t.Run("agenda json", func(t *testing.T) {
+		data, err := json.Marshal(message.Agenda)
+		require.NoError(t, err)
+
+		var agenda []AgendaItem
+		require.NoError(t, json.Unmarshal(data, &agenda))
+		require.Len(t, agenda, 2)
+		for i := range agenda {
+			require.Equal(t, message.Agenda[i].EventID, agenda[i].EventID)
+			require.Equal(t, message.Agenda[i].Title, agenda[i].Title)
+			require.True(t, time.Time(message.Agenda[i].StartTime).Equal(time.Time(agenda[i].StartTime)))
+		}
+	})
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -12,57 +13,180 @@ type (
 	EventDate time.Time
 )
 
+var (
+	// ErrEventNotFound возвращается хранилищем, если события с указанным ID нет.
+	ErrEventNotFound = errors.New("event not found")
+	// ErrVersionMismatch возвращается хранилищем, если событие было изменено после чтения.
+	ErrVersionMismatch = errors.New("event version mismatch")
+)
+
 type Event struct {
-	ID               uuid.UUID // Уникальный идентификатор события
-	UserID           uuid.UUID // ID пользователя, владельца события
-	Title            string    // Короткий текст
-	Description      string    // Описание события - длинный текст, опционально
-	StartTime        EventTime // Дата и время начала события
-	FinishTime       EventTime // Дата и время окончания события
-	NotifyBefore     int       // За сколько времени (минуты) высылать уведомление, опционально
-	NotificationSent bool      // Признак того, что по событию было отправлено уведомление
+	ID          uuid.UUID  // Уникальный идентификатор события
+	UserID      uuid.UUID  // ID пользователя, владельца события
+	Title       string     // Короткий текст
+	Description string     // Описание события - длинный текст, опционально
+	StartTime   EventTime  // Дата и время начала события
+	FinishTime  EventTime  // Дата и время окончания события
+	Reminders   []Reminder // Напоминания о событии, опционально
+	Recurrence  Recurrence // Правило повторения события, опционально
+	TimeZone    string     // Часовой пояс события (IANA), в котором разворачиваются повторения
+	Transparent bool       // Событие не занимает время (free/transparent) и не проверяется на пересечения
+	Attendees   []Attendee // Приглашенные пользователи и их ответы, опционально
+	Version     int64      // Версия события, увеличивается хранилищем при каждом изменении
+	TraceParent string     // Контекст трассировки (W3C traceparent) запроса, последним изменившего событие
+}
+
+// EventPatch содержит поля события для частичного обновления, nil - поле не изменяется.
+type EventPatch struct {
+	UserID      *uuid.UUID
+	Title       *string
+	Description *string
+	StartTime   *EventTime
+	FinishTime  *EventTime
+	Reminders   *[]Reminder
+	Recurrence  *Recurrence
+	TimeZone    *string
+	Transparent *bool
+	Attendees   *[]Attendee
+}
+
+// Apply применяет заданные поля к событию.
+func (p EventPatch) Apply(event *Event) {
+	if p.UserID != nil {
+		event.UserID = *p.UserID
+	}
+
+	if p.Title != nil {
+		event.Title = *p.Title
+	}
+
+	if p.Description != nil {
+		event.Description = *p.Description
+	}
+
+	if p.StartTime != nil {
+		event.StartTime = *p.StartTime
+	}
+
+	if p.FinishTime != nil {
+		event.FinishTime = *p.FinishTime
+	}
+
+	if p.Reminders != nil {
+		event.Reminders = append([]Reminder(nil), *p.Reminders...)
+	}
+
+	if p.Recurrence != nil {
+		event.Recurrence = *p.Recurrence
+	}
+
+	if p.TimeZone != nil {
+		event.TimeZone = *p.TimeZone
+	}
+
+	if p.Transparent != nil {
+		event.Transparent = *p.Transparent
+	}
+
+	if p.Attendees != nil {
+		event.Attendees = append([]Attendee(nil), *p.Attendees...)
+	}
+}
+
+// AffectsBusyTime возвращает true, если изменение может привести к пересечению с другими событиями.
+func (p EventPatch) AffectsBusyTime() bool {
+	return p.UserID != nil || p.StartTime != nil || p.FinishTime != nil || p.Recurrence != nil ||
+		p.TimeZone != nil || p.Transparent != nil || p.Attendees != nil
+}
+
+// Overlaps возвращает true, если события пересекаются по времени. События, одно из которых
+// заканчивается в момент начала другого, не пересекаются.
+func (e Event) Overlaps(other Event) bool {
+	return time.Time(e.StartTime).Before(time.Time(other.FinishTime)) &&
+		time.Time(other.StartTime).Before(time.Time(e.FinishTime))
+}
+
+// Location возвращает часовой пояс события, UTC - если он не задан или неизвестен.
+func (e Event) Location() *time.Location {
+	loc, err := LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// InLocation возвращает копию события, время которого представлено в часовом поясе события.
+func (e Event) InLocation() Event {
+	loc := e.Location()
+	e.StartTime = EventTime(time.Time(e.StartTime).In(loc))
+	e.FinishTime = EventTime(time.Time(e.FinishTime).In(loc))
+	return e
 }
 
 func (e Event) MarshalJSON() ([]byte, error) {
 	var tmp struct {
-		ID               string
-		UserID           string
-		Title            string
-		Description      string
-		StartTime        string
-		FinishTime       string
-		NotifyBefore     int
-		NotificationSent bool
+		ID          string
+		UserID      string
+		Title       string
+		Description string
+		StartTime   string
+		FinishTime  string
+		Reminders   []Reminder `json:",omitempty"`
+		RRule       string     `json:",omitempty"`
+		ExDates     []string   `json:",omitempty"`
+		TimeZone    string     `json:",omitempty"`
+		Transparent bool       `json:",omitempty"`
+		Attendees   []Attendee `json:",omitempty"`
+		Version     int64      `json:",omitempty"`
 	}
 
+	loc := e.Location()
 	tmp.ID = e.ID.String()
 	tmp.UserID = e.UserID.String()
 	tmp.Title = e.Title
 	tmp.Description = e.Description
-	tmp.StartTime = time.Time(e.StartTime).Format(time.DateTime)
-	tmp.FinishTime = time.Time(e.FinishTime).Format(time.DateTime)
-	tmp.NotifyBefore = e.NotifyBefore
-	tmp.NotificationSent = e.NotificationSent
+	tmp.StartTime = time.Time(e.StartTime).In(loc).Format(time.RFC3339)
+	tmp.FinishTime = time.Time(e.FinishTime).In(loc).Format(time.RFC3339)
+	tmp.Reminders = e.Reminders
+	tmp.RRule = e.Recurrence.RRule
+	for _, exDate := range e.Recurrence.ExDates {
+		tmp.ExDates = append(tmp.ExDates, time.Time(exDate).In(loc).Format(time.RFC3339))
+	}
+	tmp.TimeZone = e.TimeZone
+	tmp.Transparent = e.Transparent
+	tmp.Attendees = e.Attendees
+	tmp.Version = e.Version
+
 	json, err := json.Marshal(tmp)
 	return json, err
 }
 
 func (e *Event) UnmarshalJSON(data []byte) (err error) {
-	var startTime, finishTime time.Time
 	var tmp struct {
-		ID               string
-		UserID           string
-		Title            string
-		Description      string
-		StartTime        string
-		FinishTime       string
-		NotifyBefore     int
-		NotificationSent bool
+		ID          string
+		UserID      string
+		Title       string
+		Description string
+		StartTime   string
+		FinishTime  string
+		Reminders   []Reminder
+		RRule       string
+		ExDates     []string
+		TimeZone    string
+		Transparent bool
+		Attendees   []Attendee
+		Version     int64
 	}
 	if err = json.Unmarshal(data, &tmp); err != nil {
 		return err
 	}
 
+	loc, err := LoadLocation(tmp.TimeZone)
+	if err != nil {
+		return err
+	}
+
 	e.ID, err = uuid.FromString(tmp.ID)
 	if err != nil {
 		return err
@@ -75,20 +199,52 @@ func (e *Event) UnmarshalJSON(data []byte) (err error) {
 
 	e.Title = tmp.Title
 	e.Description = tmp.Description
-	e.NotifyBefore = tmp.NotifyBefore
-	startTime, err = time.Parse(time.DateTime, tmp.StartTime)
+	e.StartTime, err = ParseEventTime(tmp.StartTime, loc)
 	if err != nil {
 		return err
 	}
 
-	e.StartTime = EventTime(startTime)
-	finishTime, err = time.Parse(time.DateTime, tmp.FinishTime)
+	e.FinishTime, err = ParseEventTime(tmp.FinishTime, loc)
 	if err != nil {
 		return err
 	}
 
-	e.FinishTime = EventTime(finishTime)
-	e.NotifyBefore = tmp.NotifyBefore
-	e.NotificationSent = tmp.NotificationSent
+	e.Reminders = tmp.Reminders
+	e.Recurrence.RRule = tmp.RRule
+	e.TimeZone = tmp.TimeZone
+	e.Transparent = tmp.Transparent
+	e.Attendees = tmp.Attendees
+	e.Version = tmp.Version
+	e.Recurrence.ExDates, err = ParseEventTimes(tmp.ExDates, loc)
 	return err
 }
+
+// ParseEventTime разбирает дату и время в формате RFC 3339 (со смещением) или time.DateTime.
+// Время без смещения считается временем в часовом поясе loc.
+func ParseEventTime(value string, loc *time.Location) (EventTime, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.ParseInLocation(time.DateTime, value, loc)
+	}
+
+	return EventTime(parsed), err
+}
+
+// ParseEventTimes разбирает список дат и времени в формате RFC 3339 или time.DateTime.
+func ParseEventTimes(values []string, loc *time.Location) ([]EventTime, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	result := make([]EventTime, 0, len(values))
+	for _, value := range values {
+		parsed, err := ParseEventTime(value, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, parsed)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLocked возвращается хранилищем, если блокировку удерживает другой процесс.
+var ErrLocked = errors.New("lock is held by another process")
+
+// Lock - именованная блокировка, удерживаемая до вызова Unlock. Блокировка может быть потеряна
+// без вызова Unlock, например при разрыве соединения с базой данных, тогда закрывается канал Lost.
+type Lock interface {
+	// Lost возвращает канал, который закрывается, когда блокировка потеряна или снята.
+	Lost() <-chan struct{}
+	// Unlock снимает блокировку. Повторный вызов ничего не делает.
+	Unlock(ctx context.Context) error
+}
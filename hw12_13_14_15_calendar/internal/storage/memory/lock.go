@@ -0,0 +1,75 @@
+package memorystorage
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// locks - именованные блокировки процессов, работающих с одним хранилищем.
+type locks struct {
+	mu   sync.Mutex
+	held map[string]*lock
+}
+
+type lock struct {
+	name  string
+	locks *locks
+	once  sync.Once
+	lost  chan struct{}
+}
+
+// TryLock захватывает блокировку name. Возвращает storage.ErrLocked, если она уже захвачена.
+func (s *Storage) TryLock(_ context.Context, name string) (storage.Lock, error) {
+	s.locks.mu.Lock()
+	defer s.locks.mu.Unlock()
+
+	if _, held := s.locks.held[name]; held {
+		return nil, storage.ErrLocked
+	}
+
+	l := &lock{name: name, locks: &s.locks, lost: make(chan struct{})}
+	s.locks.held[name] = l
+	return l, nil
+}
+
+// Lock захватывает блокировку name. Если она уже захвачена, ждет ее освобождения, пока не отменен контекст.
+func (s *Storage) Lock(ctx context.Context, name string) (storage.Lock, error) {
+	for {
+		l, err := s.TryLock(ctx, name)
+		if !errors.Is(err, storage.ErrLocked) {
+			return l, err
+		}
+
+		s.locks.mu.Lock()
+		held, ok := s.locks.held[name]
+		s.locks.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		select {
+		case <-held.lost:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *lock) Unlock(_ context.Context) error {
+	l.once.Do(func() {
+		l.locks.mu.Lock()
+		defer l.locks.mu.Unlock()
+
+		delete(l.locks.held, l.name)
+		close(l.lost)
+	})
+
+	return nil
+}
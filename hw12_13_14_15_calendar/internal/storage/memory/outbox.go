@@ -0,0 +1,148 @@
+package memorystorage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// outbox - уведомления, ожидающие публикации в очередь.
+type outbox struct {
+	mu        sync.Mutex // Не дает нескольким публикациям выбрать одни и те же сообщения
+	messages  []storage.OutboxMessage
+	lastID    int64
+	keys      map[string]bool // Ключи уведомлений, когда-либо поставленных в outbox
+	delivered map[string]bool // Ключи доставленных уведомлений
+}
+
+func newOutbox() *outbox {
+	return &outbox{
+		keys:      make(map[string]bool),
+		delivered: make(map[string]bool),
+	}
+}
+
+// EnqueueNotifications ставит в outbox напоминания, которые пора отправить, и отмечает
+// их отправленными. Возвращает количество поставленных уведомлений.
+func (s *Storage) EnqueueNotifications(ctx context.Context) (enqueued int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outbox.mu.Lock()
+	defer s.outbox.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	now := time.Now()
+	for id, event := range s.events {
+		due, err := event.DueReminders(now)
+		if err != nil {
+			return enqueued, err
+		}
+
+		if len(due) == 0 {
+			continue
+		}
+
+		for _, reminder := range due {
+			for _, message := range storage.NewOutboxMessages(reminder) {
+				if s.outbox.add(message) {
+					enqueued++
+				}
+			}
+		}
+
+		event.MarkReminded(due, now)
+		s.events[id] = event
+	}
+
+	return enqueued, nil
+}
+
+// ScheduleNotification ставит сообщение в outbox, если сообщения с тем же ключом там еще не было.
+func (s *Storage) ScheduleNotification(ctx context.Context, message storage.OutboxMessage) error {
+	s.outbox.mu.Lock()
+	defer s.outbox.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	s.outbox.add(message)
+	return nil
+}
+
+// add добавляет сообщение в outbox. Возвращает false, если сообщение с тем же ключом уже было поставлено.
+func (o *outbox) add(message storage.OutboxMessage) bool {
+	if o.keys[message.Key] {
+		return false
+	}
+
+	o.lastID++
+	message.ID = o.lastID
+	o.keys[message.Key] = true
+	o.messages = append(o.messages, message)
+	return true
+}
+
+// RelayNotifications публикует до limit первых сообщений outbox, время публикации которых наступило,
+// функцией publish и удаляет из outbox опубликованные. Возвращает количество опубликованных сообщений.
+func (s *Storage) RelayNotifications(ctx context.Context, limit int,
+	publish storage.PublishFunc,
+) (published int, err error) {
+	s.outbox.mu.Lock()
+	defer s.outbox.mu.Unlock()
+
+	now := time.Now()
+	batch := make([]storage.OutboxMessage, 0, limit)
+	for _, message := range s.outbox.messages {
+		if len(batch) == limit {
+			break
+		}
+
+		if !message.NotBefore.After(now) {
+			batch = append(batch, message)
+		}
+	}
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	published, err = publish(ctx, batch)
+	publishedIDs := make(map[int64]bool, published)
+	for _, message := range batch[:published] {
+		publishedIDs[message.ID] = true
+	}
+
+	pending := s.outbox.messages[:0]
+	for _, message := range s.outbox.messages {
+		if !publishedIDs[message.ID] {
+			pending = append(pending, message)
+		}
+	}
+	s.outbox.messages = pending
+
+	return published, err
+}
+
+// IsNotificationDelivered возвращает true, если уведомление key уже доставлено.
+func (s *Storage) IsNotificationDelivered(ctx context.Context, key string) (bool, error) {
+	s.outbox.mu.Lock()
+	defer s.outbox.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	return s.outbox.delivered[key], nil
+}
+
+// MarkNotificationDelivered отмечает уведомление key доставленным. Возвращает false,
+// если уведомление уже было доставлено раньше или его нет в outbox.
+func (s *Storage) MarkNotificationDelivered(ctx context.Context, key string) (first bool, err error) {
+	s.outbox.mu.Lock()
+	defer s.outbox.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	if !s.outbox.keys[key] || s.outbox.delivered[key] {
+		return false, nil
+	}
+
+	s.outbox.delivered[key] = true
+	return true, nil
+}
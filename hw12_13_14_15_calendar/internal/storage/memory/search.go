@@ -0,0 +1,58 @@
+package memorystorage
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// searchIndex - инвертированный индекс: слово названия или описания -> события, в которых оно встречается.
+type searchIndex map[string]map[uuid.UUID]struct{}
+
+func (idx searchIndex) add(event storage.Event) {
+	for _, term := range storage.SearchTerms(event.Title + " " + event.Description) {
+		if idx[term] == nil {
+			idx[term] = make(map[uuid.UUID]struct{})
+		}
+		idx[term][event.ID] = struct{}{}
+	}
+}
+
+func (idx searchIndex) remove(event storage.Event) {
+	for _, term := range storage.SearchTerms(event.Title + " " + event.Description) {
+		delete(idx[term], event.ID)
+		if len(idx[term]) == 0 {
+			delete(idx, term)
+		}
+	}
+}
+
+// lookup возвращает ID событий, в которых встречаются все слова terms.
+func (idx searchIndex) lookup(terms []string) []uuid.UUID {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	smallest := idx[terms[0]]
+	for _, term := range terms[1:] {
+		if len(idx[term]) < len(smallest) {
+			smallest = idx[term]
+		}
+	}
+
+	result := make([]uuid.UUID, 0, len(smallest))
+	for id := range smallest {
+		found := true
+		for _, term := range terms {
+			if _, ok := idx[term][id]; !ok {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
@@ -3,6 +3,7 @@ package memorystorage
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,12 +16,15 @@ type Events map[uuid.UUID]storage.Event
 type Storage struct {
 	mu     sync.RWMutex
 	events Events
+	index  searchIndex
+	users  map[uuid.UUID]storage.UserSettings
+	notify map[uuid.UUID]storage.NotificationSettings
+	digest map[uuid.UUID]string // Даты последних отправленных дайджестов
+	outbox *outbox
+	locks  locks
 }
 
-var (
-	errEventExists   = errors.New("event already exists")
-	errEventNotFound = errors.New("event not found")
-)
+var errEventExists = errors.New("event already exists")
 
 func (s *Storage) Connect() error {
 	return nil
@@ -30,6 +34,11 @@ func (s *Storage) Close() error {
 	return nil
 }
 
+// Ping всегда успешен: хранилище в памяти доступно, пока работает процесс.
+func (s *Storage) Ping(_ context.Context) error {
+	return nil
+}
+
 func (s *Storage) CreateEvent(ctx context.Context, event storage.Event) error {
 	_ = context.WithoutCancel(ctx)
 	s.mu.Lock()
@@ -39,80 +48,116 @@ func (s *Storage) CreateEvent(ctx context.Context, event storage.Event) error {
 		return errEventExists
 	}
 
+	event.Attendees = append([]storage.Attendee(nil), event.Attendees...)
+	event.Reminders = append([]storage.Reminder(nil), event.Reminders...)
+	event.Version = 1
 	s.events[event.ID] = event
+	s.index.add(event)
 
 	return nil
 }
 
+// UpdateEvent сохраняет событие, если его версия не изменилась с момента чтения.
 func (s *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	_ = context.WithoutCancel(ctx)
-	if _, exists := s.events[event.ID]; !exists {
-		return errEventNotFound
+	previous, exists := s.events[event.ID]
+	if !exists {
+		return storage.ErrEventNotFound
 	}
 
+	if previous.Version != event.Version {
+		return storage.ErrVersionMismatch
+	}
+
+	event.Version++
+	event.Attendees = append([]storage.Attendee(nil), event.Attendees...)
+	event.Reminders = append([]storage.Reminder(nil), event.Reminders...)
+	event.KeepSentMarks(previous)
 	s.events[event.ID] = event
+	s.index.remove(previous)
+	s.index.add(event)
 
 	return nil
 }
 
-func (s *Storage) PatchEvent(ctx context.Context, id uuid.UUID, userID *uuid.UUID, title, description *string,
-	startTime, finishTime *storage.EventTime, notifyBefore *int, notificationSent *bool,
-) error {
+func (s *Storage) PatchEvent(ctx context.Context, id uuid.UUID, patch storage.EventPatch) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	_ = context.WithoutCancel(ctx)
 	if _, exists := s.events[id]; !exists {
-		return errEventNotFound
+		return storage.ErrEventNotFound
 	}
 
 	newEvent := s.events[id]
+	s.index.remove(newEvent)
+	patch.Apply(&newEvent)
+	newEvent.KeepSentMarks(s.events[id])
+	newEvent.Version++
+	s.events[id] = newEvent
+	s.index.add(newEvent)
+	return nil
+}
 
-	if userID != nil {
-		newEvent.UserID = *userID
-	}
-
-	if title != nil {
-		newEvent.Title = *title
-	}
+func (s *Storage) UpdateAttendeeStatus(ctx context.Context, eventID, userID uuid.UUID,
+	status storage.AttendeeStatus,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if description != nil {
-		newEvent.Description = *description
+	_ = context.WithoutCancel(ctx)
+	event, exists := s.events[eventID]
+	if !exists {
+		return storage.ErrEventNotFound
 	}
 
-	if startTime != nil {
-		newEvent.StartTime = *startTime
+	attendees := append([]storage.Attendee(nil), event.Attendees...)
+	for i := range attendees {
+		if attendees[i].UserID == userID {
+			attendees[i].Status = status
+			event.Attendees = attendees
+			event.Version++
+			s.events[eventID] = event
+			return nil
+		}
 	}
 
-	if finishTime != nil {
-		newEvent.FinishTime = *finishTime
-	}
+	return storage.ErrAttendeeNotFound
+}
 
-	if notifyBefore != nil {
-		newEvent.NotifyBefore = *notifyBefore
-	}
+func (s *Storage) GetEvent(ctx context.Context, id uuid.UUID) (storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if notificationSent != nil {
-		newEvent.NotificationSent = *notificationSent
+	_ = context.WithoutCancel(ctx)
+	event, exists := s.events[id]
+	if !exists {
+		return storage.Event{}, storage.ErrEventNotFound
 	}
 
-	s.events[id] = newEvent
-	return nil
+	return event, nil
 }
 
-func (s *Storage) DeleteEvent(ctx context.Context, id uuid.UUID) error {
+// DeleteEvent удаляет событие. Если version не равна 0, событие удаляется, только если его версия совпадает.
+func (s *Storage) DeleteEvent(ctx context.Context, id uuid.UUID, version int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	_ = context.WithoutCancel(ctx)
-	if _, exists := s.events[id]; !exists {
-		return errEventNotFound
+	event, exists := s.events[id]
+	if !exists {
+		return storage.ErrEventNotFound
+	}
+
+	if version != 0 && event.Version != version {
+		return storage.ErrVersionMismatch
 	}
 
 	delete(s.events, id)
+	s.index.remove(event)
 
 	return nil
 }
@@ -147,23 +192,104 @@ func (s *Storage) ListEventsByPeriod(ctx context.Context, userID uuid.UUID, star
 	_ = context.WithoutCancel(ctx)
 	result := make([]storage.Event, 0)
 
+	periodStartTime := time.Time(startDate)
+	periodFinishTime := time.Time(finishDate)
 	for _, event := range s.events {
+		if !event.IsVisibleTo(userID) {
+			continue
+		}
+
 		eventStartTime := time.Time(event.StartTime)
 		eventFinishTime := time.Time(event.FinishTime)
-		periodStartTime := time.Time(startDate)
-		periodFinishTime := time.Time(finishDate)
-		if event.UserID == userID && eventStartTime.Compare(periodFinishTime) < 0 &&
-			eventFinishTime.Compare(periodStartTime) >= 0 {
+		if event.Recurrence.IsRecurring() {
+			if eventStartTime.Compare(periodFinishTime) < 0 {
+				result = append(result, event)
+			}
+			continue
+		}
+
+		if eventStartTime.Compare(periodFinishTime) < 0 && eventFinishTime.Compare(periodStartTime) >= 0 {
+			result = append(result, event)
+		}
+	}
+
+	return storage.ExpandOccurrences(result, periodStartTime, periodFinishTime)
+}
+
+// ListEvents возвращает страницу событий, удовлетворяющих условиям выборки query.
+func (s *Storage) ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_ = context.WithoutCancel(ctx)
+	result := make([]storage.Event, 0)
+	for _, event := range s.events {
+		if query.Matches(event) {
+			event.Attendees = append([]storage.Attendee(nil), event.Attendees...)
+			result = append(result, event)
+		}
+	}
+
+	return query.Page(result), nil
+}
+
+// SearchEvents возвращает события, видимые пользователю, в названии или описании которых встречаются
+// все слова terms, в порядке убывания релевантности.
+func (s *Storage) SearchEvents(ctx context.Context, userID uuid.UUID, terms []string,
+	limit int,
+) ([]storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_ = context.WithoutCancel(ctx)
+	result := make([]storage.Event, 0)
+	ranks := make(map[uuid.UUID]float64)
+	for _, id := range s.index.lookup(terms) {
+		event := s.events[id]
+		if event.IsVisibleTo(userID) {
+			event.Attendees = append([]storage.Attendee(nil), event.Attendees...)
 			result = append(result, event)
+			ranks[id] = event.SearchRank(terms)
 		}
 	}
 
+	sort.Slice(result, func(i, j int) bool {
+		if ranks[result[i].ID] != ranks[result[j].ID] {
+			return ranks[result[i].ID] > ranks[result[j].ID]
+		}
+
+		return storage.CursorOf(result[i]).Before(*storage.CursorOf(result[j]))
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
 	return result, nil
 }
 
-func (s *Storage) SelectEventsToNotify(ctx context.Context) ([]storage.Event, error) {
+func (s *Storage) ListBusyEvents(ctx context.Context, userID uuid.UUID, startTime,
+	finishTime storage.EventTime,
+) ([]storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	_ = context.WithoutCancel(ctx)
-	return []storage.Event{}, nil
+	result := make([]storage.Event, 0)
+	for _, event := range s.events {
+		if !event.IsBusyFor(userID) {
+			continue
+		}
+
+		occurrences, err := event.Occurrences(time.Time(startTime), time.Time(finishTime))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, occurrences...)
+	}
+
+	return result, nil
 }
 
 func (s *Storage) PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error) {
@@ -172,8 +298,96 @@ func (s *Storage) PurgeEvents(ctx context.Context, purgeIntervalDays int) (purge
 	return 0, nil
 }
 
+func (s *Storage) GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_ = context.WithoutCancel(ctx)
+	if settings, exists := s.users[userID]; exists {
+		return settings, nil
+	}
+
+	return storage.UserSettings{UserID: userID, TimeZone: storage.DefaultTimeZone}, nil
+}
+
+func (s *Storage) UpdateUserSettings(ctx context.Context, settings storage.UserSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	s.users[settings.UserID] = settings
+	return nil
+}
+
+func (s *Storage) GetNotificationSettings(ctx context.Context,
+	userID uuid.UUID,
+) (storage.NotificationSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_ = context.WithoutCancel(ctx)
+	if settings, exists := s.notify[userID]; exists {
+		return settings, nil
+	}
+
+	return storage.NotificationSettings{UserID: userID, Channel: storage.ChannelLog}, nil
+}
+
+func (s *Storage) UpdateNotificationSettings(ctx context.Context, settings storage.NotificationSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	s.notify[settings.UserID] = settings
+	return nil
+}
+
+// ListDigestSubscribers возвращает пользователей, включивших ежедневный дайджест.
+func (s *Storage) ListDigestSubscribers(ctx context.Context) ([]storage.DigestSubscriber, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_ = context.WithoutCancel(ctx)
+	result := make([]storage.DigestSubscriber, 0)
+	for userID, settings := range s.notify {
+		if settings.DigestTime == "" {
+			continue
+		}
+
+		timeZone := storage.DefaultTimeZone
+		if userSettings, exists := s.users[userID]; exists {
+			timeZone = userSettings.TimeZone
+		}
+
+		result = append(result, storage.DigestSubscriber{
+			UserID:     userID,
+			TimeZone:   timeZone,
+			DigestTime: settings.DigestTime,
+			LastDigest: s.digest[userID],
+		})
+	}
+
+	return result, nil
+}
+
+// MarkDigestSent отмечает, что дайджест пользователю userID за дату date поставлен в outbox.
+func (s *Storage) MarkDigestSent(ctx context.Context, userID uuid.UUID, date storage.EventDate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = context.WithoutCancel(ctx)
+	s.digest[userID] = time.Time(date).Format(time.DateOnly)
+	return nil
+}
+
 func New() *Storage {
 	return &Storage{
 		events: make(Events, 0),
+		index:  make(searchIndex),
+		users:  make(map[uuid.UUID]storage.UserSettings),
+		notify: make(map[uuid.UUID]storage.NotificationSettings),
+		digest: make(map[uuid.UUID]string),
+		outbox: newOutbox(),
+		locks:  locks{held: make(map[string]*lock)},
 	}
 }
@@ -16,22 +16,25 @@ func TestStorage(t *testing.T) {
 	id, _ := uuid.NewV4()
 	userID, _ := uuid.NewV4()
 	memstor := New()
+	reminders := []storage.Reminder{{ID: uuid.Must(uuid.NewV4()), Offset: 60}}
 	createdEvent := &storage.Event{
-		ID:           id,
-		UserID:       userID,
-		Title:        "Meeting",
-		StartTime:    storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
-		FinishTime:   storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
-		NotifyBefore: 60,
+		ID:         id,
+		UserID:     userID,
+		Title:      "Meeting",
+		StartTime:  storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+		FinishTime: storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
+		Reminders:  reminders,
+		Version:    1,
 	}
 
 	updatedEvent := &storage.Event{
-		ID:           id,
-		UserID:       userID,
-		Title:        "Party",
-		StartTime:    storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
-		FinishTime:   storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
-		NotifyBefore: 60,
+		ID:         id,
+		UserID:     userID,
+		Title:      "Party",
+		StartTime:  storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+		FinishTime: storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
+		Reminders:  reminders,
+		Version:    1,
 	}
 
 	ctx := context.Background()
@@ -51,6 +54,13 @@ func TestStorage(t *testing.T) {
 		err := memstor.UpdateEvent(ctx, *updatedEvent)
 		require.NoError(t, err)
 		require.Equal(t, "Party", memstor.events[id].Title)
+		require.Equal(t, int64(2), memstor.events[id].Version)
+	})
+
+	t.Run("update stale version", func(t *testing.T) {
+		err := memstor.UpdateEvent(ctx, *updatedEvent)
+		require.ErrorIs(t, err, storage.ErrVersionMismatch)
+		updatedEvent.Version = 2
 	})
 
 	t.Run("list events by date", func(t *testing.T) {
@@ -69,7 +79,10 @@ func TestStorage(t *testing.T) {
 	})
 
 	t.Run("delete event", func(t *testing.T) {
-		err := memstor.DeleteEvent(ctx, id)
+		err := memstor.DeleteEvent(ctx, id, 1)
+		require.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+		err = memstor.DeleteEvent(ctx, id, 2)
 		require.NoError(t, err)
 		_, found := memstor.events[id]
 		require.False(t, found)
@@ -77,7 +90,7 @@ func TestStorage(t *testing.T) {
 
 	t.Run("update non-existed event", func(t *testing.T) {
 		err := memstor.UpdateEvent(ctx, *updatedEvent)
-		require.ErrorIs(t, err, errEventNotFound)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
 	})
 }
 
@@ -93,12 +106,11 @@ func TestStorageConcurrent(t *testing.T) {
 		for i := 0; i < 1_000_000; i++ {
 			id, _ := uuid.NewV4()
 			testEvent := &storage.Event{
-				ID:           id,
-				UserID:       userID,
-				Title:        "Meeting",
-				StartTime:    storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
-				FinishTime:   storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
-				NotifyBefore: 60,
+				ID:         id,
+				UserID:     userID,
+				Title:      "Meeting",
+				StartTime:  storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+				FinishTime: storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
 			}
 			err := memstor.CreateEvent(ctx, *testEvent)
 			require.Nil(t, err)
@@ -110,12 +122,11 @@ func TestStorageConcurrent(t *testing.T) {
 		for i := 0; i < 1_000_000; i++ {
 			id, _ := uuid.NewV4()
 			testEvent := &storage.Event{
-				ID:           id,
-				UserID:       userID,
-				Title:        "Meeting",
-				StartTime:    storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
-				FinishTime:   storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
-				NotifyBefore: 60,
+				ID:         id,
+				UserID:     userID,
+				Title:      "Meeting",
+				StartTime:  storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+				FinishTime: storage.EventTime(time.Date(2024, time.January, 1, 11, 0, 0, 0, time.UTC)),
 			}
 			err := memstor.CreateEvent(ctx, *testEvent)
 			require.Nil(t, err)
@@ -124,3 +135,152 @@ func TestStorageConcurrent(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestStorageRecurringEvents(t *testing.T) {
+	id, _ := uuid.NewV4()
+	userID, _ := uuid.NewV4()
+	memstor := New()
+	ctx := context.Background()
+	event := storage.Event{
+		ID:         id,
+		UserID:     userID,
+		Title:      "Standup",
+		StartTime:  storage.EventTime(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+		FinishTime: storage.EventTime(time.Date(2024, time.January, 1, 10, 15, 0, 0, time.UTC)),
+		Reminders:  []storage.Reminder{{ID: uuid.Must(uuid.NewV4()), Offset: 15}},
+		Recurrence: storage.Recurrence{
+			RRule:   "FREQ=WEEKLY;BYDAY=MO,TH",
+			ExDates: []storage.EventTime{storage.EventTime(time.Date(2024, time.January, 11, 10, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	require.NoError(t, memstor.CreateEvent(ctx, event))
+
+	t.Run("list events by week expands occurrences", func(t *testing.T) {
+		events, err := memstor.ListEventsByWeek(ctx, userID, storage.EventDate(time.Date(2024, time.January,
+			8, 0, 0, 0, 0, time.UTC)))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(events))
+		require.Equal(t, id, events[0].ID)
+		require.Equal(t, storage.EventTime(time.Date(2024, time.January, 8, 10, 0, 0, 0, time.UTC)), events[0].StartTime)
+		require.Equal(t, storage.EventTime(time.Date(2024, time.January, 8, 10, 15, 0, 0, time.UTC)),
+			events[0].FinishTime)
+	})
+
+	t.Run("list events by month expands occurrences", func(t *testing.T) {
+		events, err := memstor.ListEventsByMonth(ctx, userID, storage.EventDate(time.Date(2024, time.February,
+			1, 0, 0, 0, 0, time.UTC)))
+		require.NoError(t, err)
+		require.Equal(t, 9, len(events))
+	})
+
+	t.Run("enqueue notifications for occurrence", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Minute)
+		event.StartTime = storage.EventTime(now.Add(10 * time.Minute))
+		event.FinishTime = storage.EventTime(now.Add(20 * time.Minute))
+		event.Recurrence = storage.Recurrence{RRule: "FREQ=DAILY"}
+		event.Version = memstor.events[id].Version
+		require.NoError(t, memstor.UpdateEvent(ctx, event))
+
+		enqueued, err := memstor.EnqueueNotifications(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, enqueued)
+		require.Equal(t, event.StartTime, *memstor.events[id].Reminders[0].Occurrence)
+		require.NotNil(t, memstor.events[id].Reminders[0].SentAt)
+		require.Nil(t, event.Reminders[0].SentAt)
+
+		enqueued, err = memstor.EnqueueNotifications(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 0, enqueued)
+
+		var messages []storage.OutboxMessage
+		published, err := memstor.RelayNotifications(ctx, 10,
+			func(_ context.Context, batch []storage.OutboxMessage) (int, error) {
+				messages = batch
+				return len(batch), nil
+			})
+		require.NoError(t, err)
+		require.Equal(t, 1, published)
+		require.Equal(t, event.StartTime, messages[0].StartTime)
+		require.Equal(t, storage.NotificationKey(id, event.StartTime, 15, userID), messages[0].Key)
+		require.Equal(t, event.Reminders[0].ID, messages[0].ReminderID)
+		require.True(t, messages[0].Recurring)
+	})
+
+	t.Run("snoozed notification waits for its time", func(t *testing.T) {
+		reminder := memstor.events[id].Reminders[0]
+		message := storage.SnoozedMessage(memstor.events[id], reminder, userID, time.Now().Add(time.Hour))
+		require.NoError(t, memstor.ScheduleNotification(ctx, message))
+		require.NoError(t, memstor.ScheduleNotification(ctx, message))
+
+		publish := func(_ context.Context, batch []storage.OutboxMessage) (int, error) {
+			return len(batch), nil
+		}
+		published, err := memstor.RelayNotifications(ctx, 10, publish)
+		require.NoError(t, err)
+		require.Equal(t, 0, published)
+
+		memstor.outbox.messages[0].NotBefore = time.Now()
+		published, err = memstor.RelayNotifications(ctx, 10, publish)
+		require.NoError(t, err)
+		require.Equal(t, 1, published)
+	})
+}
+
+func TestStorageAttendees(t *testing.T) {
+	ctx := context.Background()
+	memstor := New()
+	ownerID, _ := uuid.NewV4()
+	aliceID, _ := uuid.NewV4()
+	bobID, _ := uuid.NewV4()
+	event := storage.Event{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     ownerID,
+		Title:      "Planning",
+		StartTime:  storage.EventTime(time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)),
+		FinishTime: storage.EventTime(time.Date(2024, time.January, 2, 11, 0, 0, 0, time.UTC)),
+		Attendees:  storage.NewAttendees(ownerID, []uuid.UUID{aliceID, bobID, ownerID}, nil),
+	}
+	require.NoError(t, memstor.CreateEvent(ctx, event))
+	week := storage.EventDate(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	t.Run("invitee sees event", func(t *testing.T) {
+		events, err := memstor.ListEventsByWeek(ctx, aliceID, week)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, []storage.Attendee{
+			{UserID: aliceID, Status: storage.NeedsAction},
+			{UserID: bobID, Status: storage.NeedsAction},
+		}, events[0].Attendees)
+	})
+
+	t.Run("accepted invitation is busy time", func(t *testing.T) {
+		require.NoError(t, memstor.UpdateAttendeeStatus(ctx, event.ID, aliceID, storage.Accepted))
+		events, err := memstor.ListBusyEvents(ctx, aliceID, event.StartTime, event.FinishTime)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		events, err = memstor.ListBusyEvents(ctx, bobID, event.StartTime, event.FinishTime)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("declined invitation is not listed", func(t *testing.T) {
+		require.NoError(t, memstor.UpdateAttendeeStatus(ctx, event.ID, bobID, storage.Declined))
+		events, err := memstor.ListEventsByWeek(ctx, bobID, week)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("recipients are owner and accepted attendees", func(t *testing.T) {
+		stored, err := memstor.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{ownerID, aliceID}, stored.Recipients())
+	})
+
+	t.Run("respond without invitation", func(t *testing.T) {
+		strangerID, _ := uuid.NewV4()
+		err := memstor.UpdateAttendeeStatus(ctx, event.ID, strangerID, storage.Accepted)
+		require.ErrorIs(t, err, storage.ErrAttendeeNotFound)
+	})
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// OutboxMessage - уведомление, записанное в outbox в одной транзакции с отметкой события
+// и ожидающее публикации в очередь.
+type OutboxMessage struct {
+	ID          int64               // Порядковый номер сообщения в outbox
+	Key         string              // Ключ дедупликации уведомления
+	Type        NotificationType    // Вид уведомления: напоминание или дайджест
+	EventID     uuid.UUID           // ID события, для дайджеста не используется
+	ReminderID  uuid.UUID           // ID напоминания
+	UserID      uuid.UUID           // ID пользователя, получателя уведомления
+	Channel     NotificationChannel // Канал доставки напоминания, пустое значение - канал из настроек получателя
+	Title       string              // Название события
+	StartTime   EventTime           // Дата и время начала события (повторения события), для дайджеста - начало дня
+	Recurring   bool                // Признак повторяющегося события
+	NotBefore   time.Time           // Время, раньше которого сообщение не публикуется, опционально
+	Agenda      []AgendaItem        // События дайджеста
+	TraceParent string              // Контекст трассировки (W3C traceparent) запроса, создавшего уведомление
+}
+
+// PublishFunc публикует сообщения по порядку и возвращает количество опубликованных.
+// Если публикация прервалась ошибкой, опубликованными считаются только первые published сообщений.
+type PublishFunc func(ctx context.Context, messages []OutboxMessage) (published int, err error)
+
+// NotificationKey возвращает ключ дедупликации напоминания пользователю userID за offset минут
+// до повторения события, начинающегося в startTime. Повторная постановка того же напоминания
+// в outbox игнорируется.
+func NotificationKey(eventID uuid.UUID, startTime EventTime, offset int, userID uuid.UUID) string {
+	return fmt.Sprintf("%s:%d:%d:%s", eventID, time.Time(startTime).Unix(), offset, userID)
+}
+
+// NewOutboxMessages возвращает напоминания о повторении события для владельца
+// и участников, принявших приглашение.
+func NewOutboxMessages(due DueReminder) []OutboxMessage {
+	event := due.Occurrence
+	recipients := event.Recipients()
+	result := make([]OutboxMessage, 0, len(recipients))
+	for _, userID := range recipients {
+		result = append(result, OutboxMessage{
+			Key:         NotificationKey(event.ID, event.StartTime, due.Reminder.Offset, userID),
+			Type:        NotificationReminder,
+			EventID:     event.ID,
+			ReminderID:  due.Reminder.ID,
+			UserID:      userID,
+			Channel:     due.Reminder.Channel,
+			Title:       event.Title,
+			StartTime:   event.StartTime,
+			Recurring:   event.Recurrence.IsRecurring(),
+			TraceParent: event.TraceParent,
+		})
+	}
+
+	return result
+}
+
+// SnoozedMessage возвращает повторное напоминание reminder пользователю userID о последнем повторении
+// события, о котором оно отправлялось. Сообщение публикуется не раньше notBefore.
+func SnoozedMessage(event Event, reminder Reminder, userID uuid.UUID, notBefore time.Time) OutboxMessage {
+	startTime := event.StartTime
+	if reminder.Occurrence != nil {
+		startTime = *reminder.Occurrence
+	}
+
+	key := NotificationKey(event.ID, startTime, reminder.Offset, userID)
+	return OutboxMessage{
+		Key:        fmt.Sprintf("%s:snooze:%d", key, notBefore.Unix()),
+		Type:       NotificationReminder,
+		EventID:    event.ID,
+		ReminderID: reminder.ID,
+		UserID:     userID,
+		Channel:    reminder.Channel,
+		Title:      event.Title,
+		StartTime:  startTime,
+		Recurring:  event.Recurrence.IsRecurring(),
+		NotBefore:  notBefore,
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutboxMessages(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	guest := uuid.Must(uuid.NewV4())
+	declined := uuid.Must(uuid.NewV4())
+	event := recurringEvent("FREQ=DAILY")
+	event.ID = uuid.Must(uuid.NewV4())
+	event.UserID = owner
+	event.Attendees = []Attendee{{UserID: guest, Status: Accepted}, {UserID: declined, Status: Declined}}
+	event.Reminders[0].Channel = ChannelEmail
+	event.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	messages := NewOutboxMessages(DueReminder{Reminder: event.Reminders[0], Occurrence: event})
+	require.Len(t, messages, 2)
+	for _, message := range messages {
+		require.Equal(t, event.ID, message.EventID)
+		require.Equal(t, event.Reminders[0].ID, message.ReminderID)
+		require.Equal(t, ChannelEmail, message.Channel)
+		require.True(t, message.Recurring)
+		require.True(t, message.NotBefore.IsZero())
+		require.Equal(t, NotificationKey(event.ID, event.StartTime, 15, message.UserID), message.Key)
+		require.Equal(t, event.TraceParent, message.TraceParent)
+	}
+	require.ElementsMatch(t, []uuid.UUID{owner, guest}, []uuid.UUID{messages[0].UserID, messages[1].UserID})
+	require.NotEqual(t, messages[0].Key, messages[1].Key)
+}
+
+func TestNotificationKey(t *testing.T) {
+	eventID := uuid.Must(uuid.NewV4())
+	userID := uuid.Must(uuid.NewV4())
+	startTime := EventTime(date(2024, time.January, 1, 10))
+	require.NotEqual(t, NotificationKey(eventID, startTime, 15, userID), NotificationKey(eventID, startTime, 60, userID))
+	require.Equal(t, NotificationKey(eventID, startTime, 15, userID), NotificationKey(eventID, startTime, 15, userID))
+}
+
+func TestSnoozedMessage(t *testing.T) {
+	event := recurringEvent("FREQ=DAILY")
+	event.ID = uuid.Must(uuid.NewV4())
+	userID := uuid.Must(uuid.NewV4())
+	reminder := event.Reminders[0]
+	occurrence := EventTime(date(2024, time.January, 3, 10))
+	reminder.Occurrence = &occurrence
+	notBefore := date(2024, time.January, 3, 9).Add(55 * time.Minute)
+
+	message := SnoozedMessage(event, reminder, userID, notBefore)
+	require.Equal(t, userID, message.UserID)
+	require.Equal(t, reminder.ID, message.ReminderID)
+	require.Equal(t, occurrence, message.StartTime)
+	require.Equal(t, notBefore, message.NotBefore)
+	require.NotEqual(t, NotificationKey(event.ID, occurrence, reminder.Offset, userID), message.Key)
+
+	later := SnoozedMessage(event, reminder, userID, notBefore.Add(5*time.Minute))
+	require.NotEqual(t, message.Key, later.Key)
+}
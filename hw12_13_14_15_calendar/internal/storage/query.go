@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Порядок сортировки событий по времени начала.
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Ограничения количества событий на странице.
+const (
+	DefaultEventsLimit = 100
+	MaxEventsLimit     = 1000
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidSortOrder = errors.New("invalid sort order")
+)
+
+type SortOrder string
+
+// ParseSortOrder проверяет и возвращает порядок сортировки, пустое значение - по возрастанию.
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(value)); order {
+	case "":
+		return SortAsc, nil
+	case SortAsc, SortDesc:
+		return order, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidSortOrder, value)
+	}
+}
+
+// EventQuery - параметры выборки событий пользователя. Повторяющееся событие выбирается целиком,
+// если его серия пересекается с периодом [From, To). События упорядочены по времени начала и ID.
+type EventQuery struct {
+	UserID           uuid.UUID    // Владелец или участник, не отклонивший приглашение
+	From             *EventTime   // Начало периода, опционально
+	To               *EventTime   // Окончание периода, опционально
+	Title            string       // Подстрока названия без учета регистра, опционально
+	NotificationSent *bool        // Признак отправки хотя бы одного напоминания о событии, опционально
+	Order            SortOrder    // Порядок сортировки
+	Limit            int          // Количество событий на странице
+	After            *EventCursor // Позиция, после которой начинается страница, опционально
+}
+
+// EventCursor - позиция события в выборке, упорядоченной по времени начала и ID.
+type EventCursor struct {
+	StartTime time.Time
+	ID        uuid.UUID
+}
+
+// EventPage - страница выборки событий. Next равен nil на последней странице.
+type EventPage struct {
+	Events []Event
+	Next   *EventCursor
+}
+
+// CursorOf возвращает позицию события в выборке.
+func CursorOf(event Event) *EventCursor {
+	return &EventCursor{StartTime: time.Time(event.StartTime), ID: event.ID}
+}
+
+// String возвращает непрозрачное строковое представление позиции.
+func (c EventCursor) String() string {
+	value := strconv.FormatInt(c.StartTime.UnixNano(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// ParseEventCursor разбирает позицию, полученную из EventCursor.String.
+func ParseEventCursor(value string) (*EventCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	startTime, id, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(startTime, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &EventCursor{StartTime: time.Unix(0, nanos).UTC()}
+	if cursor.ID, err = uuid.FromString(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Before возвращает true, если позиция c предшествует other при сортировке по возрастанию.
+func (c EventCursor) Before(other EventCursor) bool {
+	if !c.StartTime.Equal(other.StartTime) {
+		return c.StartTime.Before(other.StartTime)
+	}
+
+	return bytes.Compare(c.ID.Bytes(), other.ID.Bytes()) < 0
+}
+
+// Matches возвращает true, если событие удовлетворяет условиям выборки, кроме позиции страницы.
+func (q EventQuery) Matches(event Event) bool {
+	if !event.IsVisibleTo(q.UserID) {
+		return false
+	}
+
+	if q.To != nil && !time.Time(event.StartTime).Before(time.Time(*q.To)) {
+		return false
+	}
+
+	if q.From != nil {
+		seriesFinish, finite, err := event.SeriesFinishTime()
+		if err != nil || (finite && !seriesFinish.After(time.Time(*q.From))) {
+			return false
+		}
+	}
+
+	if q.Title != "" && !strings.Contains(strings.ToLower(event.Title), strings.ToLower(q.Title)) {
+		return false
+	}
+
+	return q.NotificationSent == nil || event.IsReminded() == *q.NotificationSent
+}
+
+// PageSize возвращает количество событий на странице, DefaultEventsLimit - если оно не задано.
+func (q EventQuery) PageSize() int {
+	if q.Limit <= 0 {
+		return DefaultEventsLimit
+	}
+
+	return q.Limit
+}
+
+// Page сортирует подходящие события и возвращает страницу выборки.
+func (q EventQuery) Page(events []Event) EventPage {
+	limit := q.PageSize()
+	sort.Slice(events, func(i, j int) bool {
+		if q.Order == SortDesc {
+			return CursorOf(events[j]).Before(*CursorOf(events[i]))
+		}
+
+		return CursorOf(events[i]).Before(*CursorOf(events[j]))
+	})
+
+	page := EventPage{Events: make([]Event, 0, limit)}
+	for _, event := range events {
+		if q.After != nil && !q.isAfter(*CursorOf(event)) {
+			continue
+		}
+
+		if len(page.Events) == limit {
+			page.Next = CursorOf(page.Events[len(page.Events)-1])
+			break
+		}
+		page.Events = append(page.Events, event)
+	}
+
+	return page
+}
+
+func (q EventQuery) isAfter(cursor EventCursor) bool {
+	if q.Order == SortDesc {
+		return cursor.Before(*q.After)
+	}
+
+	return q.After.Before(cursor)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		cursor := EventCursor{StartTime: date(2024, time.January, 1, 10), ID: uuid.Must(uuid.NewV4())}
+		parsed, err := ParseEventCursor(cursor.String())
+		require.NoError(t, err)
+		require.True(t, cursor.StartTime.Equal(parsed.StartTime))
+		require.Equal(t, cursor.ID, parsed.ID)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		for _, value := range []string{"not base64!", "bm8gc2VwYXJhdG9y", "MTpub3QtYS11dWlk"} {
+			_, err := ParseEventCursor(value)
+			require.ErrorIs(t, err, ErrInvalidCursor, value)
+		}
+	})
+
+	t.Run("order", func(t *testing.T) {
+		first := EventCursor{StartTime: date(2024, time.January, 1, 10), ID: uuid.Must(uuid.FromString(
+			"00000000-0000-0000-0000-000000000002"))}
+		second := EventCursor{StartTime: first.StartTime, ID: uuid.Must(uuid.FromString(
+			"00000000-0000-0000-0000-000000000003"))}
+		third := EventCursor{StartTime: date(2024, time.January, 1, 11), ID: uuid.Must(uuid.FromString(
+			"00000000-0000-0000-0000-000000000001"))}
+		require.True(t, first.Before(second))
+		require.True(t, second.Before(third))
+		require.False(t, third.Before(first))
+		require.False(t, first.Before(first))
+	})
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("")
+	require.NoError(t, err)
+	require.Equal(t, SortAsc, order)
+
+	order, err = ParseSortOrder("DESC")
+	require.NoError(t, err)
+	require.Equal(t, SortDesc, order)
+
+	_, err = ParseSortOrder("random")
+	require.ErrorIs(t, err, ErrInvalidSortOrder)
+}
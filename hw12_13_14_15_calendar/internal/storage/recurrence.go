@@ -0,0 +1,426 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxIterations ограничивает перебор периодов правила, чтобы некорректное правило не зациклило расчет.
+const maxIterations = 100_000
+
+var (
+	ErrInvalidRRule       = errors.New("invalid recurrence rule")
+	errUnsupportedRRule   = errors.New("unsupported recurrence rule part")
+	errCountAndUntilRRule = errors.New("COUNT and UNTIL must not occur in the same rule")
+)
+
+var (
+	weekdays     = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+	untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+)
+
+type Recurrence struct {
+	RRule   string      // Правило повторения в формате RRULE (RFC 5545), пустое для однократного события
+	ExDates []EventTime // Даты и время начала исключенных повторений (EXDATE)
+}
+
+type WeekdayNum struct {
+	Weekday time.Weekday // День недели
+	N       int          // Порядковый номер дня недели в месяце (1, 2, -1...), 0 - все такие дни
+}
+
+type RRule struct {
+	Freq      Frequency    // Частота повторения
+	Interval  int          // Интервал между периодами повторения
+	ByDay     []WeekdayNum // Дни недели повторения
+	Count     int          // Количество повторений, 0 - не ограничено
+	Until     time.Time    // Дата и время последнего повторения, нулевое значение - не ограничено
+	WeekStart time.Weekday // Первый день недели
+}
+
+func (r Recurrence) IsRecurring() bool {
+	return r.RRule != ""
+}
+
+// ParseRRule разбирает значение свойства RRULE, например "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	rule := &RRule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		name, val, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRRule, part)
+		}
+
+		if err := rule.setPart(strings.ToUpper(name), strings.ToUpper(val)); err != nil {
+			return nil, err
+		}
+	}
+
+	switch rule.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return nil, fmt.Errorf("%w: FREQ must be one of DAILY, WEEKLY, MONTHLY, YEARLY", ErrInvalidRRule)
+	}
+
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRRule, errCountAndUntilRRule)
+	}
+
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("%w: numeric BYDAY is supported for FREQ=MONTHLY only", errUnsupportedRRule)
+		}
+	}
+
+	if len(rule.ByDay) > 0 && rule.Freq == Yearly {
+		return nil, fmt.Errorf("%w: BYDAY is not supported for FREQ=YEARLY", errUnsupportedRRule)
+	}
+
+	return rule, nil
+}
+
+func (r *RRule) setPart(name, val string) (err error) {
+	switch name {
+	case "FREQ":
+		r.Freq = Frequency(val)
+	case "INTERVAL":
+		r.Interval, err = strconv.Atoi(val)
+		if err != nil || r.Interval < 1 {
+			return fmt.Errorf("%w: INTERVAL=%s", ErrInvalidRRule, val)
+		}
+	case "COUNT":
+		r.Count, err = strconv.Atoi(val)
+		if err != nil || r.Count < 1 {
+			return fmt.Errorf("%w: COUNT=%s", ErrInvalidRRule, val)
+		}
+	case "UNTIL":
+		for _, layout := range untilLayouts {
+			if r.Until, err = time.Parse(layout, val); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: UNTIL=%s", ErrInvalidRRule, val)
+	case "BYDAY":
+		for _, day := range strings.Split(val, ",") {
+			weekdayNum, err := parseWeekdayNum(day)
+			if err != nil {
+				return err
+			}
+			r.ByDay = append(r.ByDay, weekdayNum)
+		}
+	case "WKST":
+		weekdayNum, err := parseWeekdayNum(val)
+		if err != nil || weekdayNum.N != 0 {
+			return fmt.Errorf("%w: WKST=%s", ErrInvalidRRule, val)
+		}
+		r.WeekStart = weekdayNum.Weekday
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedRRule, name)
+	}
+
+	return nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRRule, value)
+	}
+
+	weekdayNum := WeekdayNum{Weekday: -1}
+	for i, weekday := range weekdays {
+		if weekday == value[len(value)-2:] {
+			weekdayNum.Weekday = time.Weekday(i)
+		}
+	}
+
+	if weekdayNum.Weekday < 0 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRRule, value)
+	}
+
+	if n := value[:len(value)-2]; n != "" {
+		var err error
+		weekdayNum.N, err = strconv.Atoi(n)
+		if err != nil || weekdayNum.N == 0 || weekdayNum.N > 5 || weekdayNum.N < -5 {
+			return WeekdayNum{}, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRRule, value)
+		}
+	}
+
+	return weekdayNum, nil
+}
+
+// Iterate вызывает fn для начала каждого повторения правила по возрастанию, начиная с dtstart,
+// пока fn возвращает true и правило не исчерпано. Первым повторением всегда считается dtstart.
+func (r *RRule) Iterate(dtstart time.Time, fn func(start time.Time) bool) {
+	count := 0
+	emit := func(start time.Time) bool {
+		if !r.Until.IsZero() && start.After(r.Until) {
+			return false
+		}
+
+		if r.Count > 0 && count >= r.Count {
+			return false
+		}
+
+		count++
+		return fn(start)
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+
+	for period := 0; period < maxIterations; period++ {
+		for _, start := range r.periodStarts(dtstart, period) {
+			if !start.After(dtstart) {
+				continue
+			}
+
+			if !emit(start) {
+				return
+			}
+		}
+	}
+}
+
+// periodStarts возвращает упорядоченные начала повторений в периоде с номером period.
+func (r *RRule) periodStarts(dtstart time.Time, period int) []time.Time {
+	year, month, day := dtstart.Date()
+	hour, minute, sec := dtstart.Clock()
+	loc := dtstart.Location()
+	n := period * r.Interval
+	switch r.Freq {
+	case Daily:
+		start := time.Date(year, month, day+n, hour, minute, sec, dtstart.Nanosecond(), loc)
+		if len(r.ByDay) > 0 && !r.hasWeekday(start.Weekday()) {
+			return nil
+		}
+		return []time.Time{start}
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := time.Date(year, month, day-offset+7*n, hour, minute, sec, dtstart.Nanosecond(), loc)
+		if len(r.ByDay) == 0 {
+			return []time.Time{weekStart.AddDate(0, 0, offset)}
+		}
+		starts := make([]time.Time, 0, len(r.ByDay))
+		for _, byDay := range r.ByDay {
+			starts = append(starts, weekStart.AddDate(0, 0, (int(byDay.Weekday)-int(r.WeekStart)+7)%7))
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+		return starts
+	case Monthly:
+		monthStart := time.Date(year, month+time.Month(n), 1, hour, minute, sec, dtstart.Nanosecond(), loc)
+		if len(r.ByDay) == 0 {
+			return validDate(monthStart.Year(), monthStart.Month(), day, monthStart)
+		}
+		return r.monthlyByDay(monthStart)
+	case Yearly:
+		return validDate(year+n, month, day, dtstart)
+	}
+
+	return nil
+}
+
+func (r *RRule) hasWeekday(weekday time.Weekday) bool {
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// monthlyByDay возвращает дни месяца, соответствующие BYDAY, с учетом порядковых номеров.
+func (r *RRule) monthlyByDay(monthStart time.Time) []time.Time {
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	starts := make([]time.Time, 0)
+	for d := 1; d <= daysInMonth; d++ {
+		start := monthStart.AddDate(0, 0, d-1)
+		for _, byDay := range r.ByDay {
+			if start.Weekday() != byDay.Weekday {
+				continue
+			}
+
+			first := (d-1)/7 + 1
+			last := -((daysInMonth-d)/7 + 1)
+			if byDay.N == 0 || byDay.N == first || byDay.N == last {
+				starts = append(starts, start)
+				break
+			}
+		}
+	}
+
+	return starts
+}
+
+// validDate возвращает дату с временем из clock, если такая дата существует (например, 30 февраля - нет).
+func validDate(year int, month time.Month, day int, clock time.Time) []time.Time {
+	hour, minute, sec := clock.Clock()
+	date := time.Date(year, month, day, hour, minute, sec, clock.Nanosecond(), clock.Location())
+	if date.Day() != day {
+		return nil
+	}
+
+	return []time.Time{date}
+}
+
+// Occurrences разворачивает событие в повторения, пересекающиеся с периодом [from, to).
+// Повторения рассчитываются по местному времени часового пояса события, поэтому переход
+// на летнее время не сдвигает их. Однократное событие возвращается как есть, если оно
+// пересекается с периодом.
+func (e Event) Occurrences(from, to time.Time) ([]Event, error) {
+	startTime := time.Time(e.StartTime).In(e.Location())
+	duration := time.Time(e.FinishTime).Sub(startTime)
+	if !e.Recurrence.IsRecurring() {
+		if startTime.Before(to) && startTime.Add(duration).After(from) {
+			return []Event{e}, nil
+		}
+		return []Event{}, nil
+	}
+
+	rule, err := ParseRRule(e.Recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Event, 0)
+	rule.Iterate(startTime, func(start time.Time) bool {
+		if !start.Before(to) {
+			return false
+		}
+
+		if start.Add(duration).After(from) && !e.isExcluded(start) {
+			result = append(result, e.occurrence(start, duration))
+		}
+		return true
+	})
+
+	return result, nil
+}
+
+// SeriesFinishTime возвращает время окончания последнего повторения события.
+// Для бесконечно повторяющегося события возвращается false.
+func (e Event) SeriesFinishTime() (time.Time, bool, error) {
+	finishTime := time.Time(e.FinishTime)
+	if !e.Recurrence.IsRecurring() {
+		return finishTime, true, nil
+	}
+
+	rule, err := ParseRRule(e.Recurrence.RRule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return time.Time{}, false, nil
+	}
+
+	startTime := time.Time(e.StartTime).In(e.Location())
+	duration := finishTime.Sub(startTime)
+	rule.Iterate(startTime, func(start time.Time) bool {
+		finishTime = start.Add(duration)
+		return true
+	})
+
+	return finishTime, true, nil
+}
+
+// Validate проверяет правило повторения события.
+func (r Recurrence) Validate() error {
+	if !r.IsRecurring() {
+		return nil
+	}
+
+	_, err := ParseRRule(r.RRule)
+	return err
+}
+
+func (e Event) isExcluded(start time.Time) bool {
+	for _, exDate := range e.Recurrence.ExDates {
+		if time.Time(exDate).Equal(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Event) occurrence(start time.Time, duration time.Duration) Event {
+	occurrence := e
+	occurrence.StartTime = EventTime(start)
+	occurrence.FinishTime = EventTime(start.Add(duration))
+	return occurrence
+}
+
+// ExpandOccurrences разворачивает повторяющиеся события в повторения внутри периода [from, to)
+// и упорядочивает результат по времени начала. Однократные события уже отобраны хранилищем
+// по периоду и возвращаются без изменений.
+func ExpandOccurrences(events []Event, from, to time.Time) ([]Event, error) {
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		if !event.Recurrence.IsRecurring() {
+			result = append(result, event)
+			continue
+		}
+
+		occurrences, err := event.Occurrences(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, occurrences...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return time.Time(result[i].StartTime).Before(time.Time(result[j].StartTime))
+	})
+
+	return result, nil
+}
+
+// FormatExDates форматирует даты исключений в виде значения свойства EXDATE.
+func FormatExDates(exDates []EventTime) string {
+	values := make([]string, 0, len(exDates))
+	for _, exDate := range exDates {
+		values = append(values, time.Time(exDate).UTC().Format(untilLayouts[0]))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// ParseExDates разбирает значение свойства EXDATE.
+func ParseExDates(value string) ([]EventTime, error) {
+	exDates := make([]EventTime, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		exDate, err := time.Parse(untilLayouts[0], v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: EXDATE=%s", ErrInvalidRRule, v)
+		}
+
+		exDates = append(exDates, EventTime(exDate))
+	}
+
+	return exDates, nil
+}
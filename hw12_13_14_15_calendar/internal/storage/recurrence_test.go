@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func starts(events []Event) []time.Time {
+	result := make([]time.Time, 0, len(events))
+	for _, event := range events {
+		result = append(result, time.Time(event.StartTime))
+	}
+	return result
+}
+
+func recurringEvent(rrule string, exDates ...EventTime) Event {
+	return Event{
+		Title:      "Standup",
+		StartTime:  EventTime(date(2024, time.January, 1, 10)), // понедельник
+		FinishTime: EventTime(date(2024, time.January, 1, 11)),
+		Reminders:  []Reminder{{ID: uuid.Must(uuid.NewV4()), Offset: 15}},
+		Recurrence: Recurrence{RRule: rrule, ExDates: exDates},
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	t.Run("valid rule", func(t *testing.T) {
+		rule, err := ParseRRule("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10")
+		require.NoError(t, err)
+		require.Equal(t, Weekly, rule.Freq)
+		require.Equal(t, 2, rule.Interval)
+		require.Equal(t, 10, rule.Count)
+		require.Equal(t, []WeekdayNum{{Weekday: time.Monday}, {Weekday: time.Friday}}, rule.ByDay)
+	})
+
+	t.Run("until", func(t *testing.T) {
+		rule, err := ParseRRule("FREQ=DAILY;UNTIL=20240105T235959Z")
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, time.January, 5, 23, 59, 59, 0, time.UTC), rule.Until)
+	})
+
+	for _, rrule := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240105",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYHOUR=10",
+	} {
+		rrule := rrule
+		t.Run("invalid rule "+rrule, func(t *testing.T) {
+			_, err := ParseRRule(rrule)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	t.Run("daily with count", func(t *testing.T) {
+		occurrences, err := recurringEvent("FREQ=DAILY;COUNT=3").Occurrences(date(2024, time.January, 1, 0),
+			date(2024, time.February, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{
+			date(2024, time.January, 1, 10), date(2024, time.January, 2, 10), date(2024, time.January, 3, 10),
+		}, starts(occurrences))
+		require.Equal(t, EventTime(date(2024, time.January, 2, 11)), occurrences[1].FinishTime)
+	})
+
+	t.Run("weekly by day inside window", func(t *testing.T) {
+		occurrences, err := recurringEvent("FREQ=WEEKLY;BYDAY=MO,WE").Occurrences(date(2024, time.January, 8, 0),
+			date(2024, time.January, 15, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{date(2024, time.January, 8, 10), date(2024, time.January, 10, 10)},
+			starts(occurrences))
+	})
+
+	t.Run("weekly with interval and until", func(t *testing.T) {
+		occurrences, err := recurringEvent("FREQ=WEEKLY;INTERVAL=2;UNTIL=20240131T000000Z").Occurrences(
+			date(2024, time.January, 1, 0), date(2024, time.March, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{
+			date(2024, time.January, 1, 10), date(2024, time.January, 15, 10), date(2024, time.January, 29, 10),
+		}, starts(occurrences))
+	})
+
+	t.Run("monthly by last friday", func(t *testing.T) {
+		occurrences, err := recurringEvent("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3").Occurrences(
+			date(2024, time.January, 1, 0), date(2025, time.January, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{
+			date(2024, time.January, 1, 10), date(2024, time.January, 26, 10), date(2024, time.February, 23, 10),
+		}, starts(occurrences))
+	})
+
+	t.Run("monthly skips missing days", func(t *testing.T) {
+		event := recurringEvent("FREQ=MONTHLY;COUNT=3")
+		event.StartTime = EventTime(date(2024, time.January, 31, 10))
+		event.FinishTime = EventTime(date(2024, time.January, 31, 11))
+		occurrences, err := event.Occurrences(date(2024, time.January, 1, 0), date(2025, time.January, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{
+			date(2024, time.January, 31, 10), date(2024, time.March, 31, 10), date(2024, time.May, 31, 10),
+		}, starts(occurrences))
+	})
+
+	t.Run("yearly", func(t *testing.T) {
+		occurrences, err := recurringEvent("FREQ=YEARLY").Occurrences(date(2025, time.January, 1, 0),
+			date(2027, time.January, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{date(2025, time.January, 1, 10), date(2026, time.January, 1, 10)},
+			starts(occurrences))
+	})
+
+	t.Run("exception dates", func(t *testing.T) {
+		event := recurringEvent("FREQ=DAILY;COUNT=3", EventTime(date(2024, time.January, 2, 10)))
+		occurrences, err := event.Occurrences(date(2024, time.January, 1, 0), date(2024, time.February, 1, 0))
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{date(2024, time.January, 1, 10), date(2024, time.January, 3, 10)},
+			starts(occurrences))
+	})
+
+	t.Run("keeps local time across daylight saving change", func(t *testing.T) {
+		loc, err := LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+
+		event := recurringEvent("FREQ=DAILY;COUNT=3")
+		event.TimeZone = "Europe/Berlin"
+		event.StartTime = EventTime(time.Date(2024, time.March, 30, 9, 0, 0, 0, loc).UTC())
+		event.FinishTime = EventTime(time.Date(2024, time.March, 30, 10, 0, 0, 0, loc).UTC())
+		occurrences, err := event.Occurrences(date(2024, time.March, 1, 0), date(2024, time.April, 2, 0))
+		require.NoError(t, err)
+		require.Len(t, occurrences, 3)
+		for _, occurrence := range occurrences {
+			require.Equal(t, 9, time.Time(occurrence.StartTime).In(loc).Hour())
+		}
+		require.Equal(t, date(2024, time.March, 30, 8), time.Time(occurrences[0].StartTime).UTC())
+		require.Equal(t, date(2024, time.March, 31, 7), time.Time(occurrences[1].StartTime).UTC())
+	})
+}
+
+func TestSeriesFinishTime(t *testing.T) {
+	finishTime, finite, err := recurringEvent("FREQ=WEEKLY;COUNT=3").SeriesFinishTime()
+	require.NoError(t, err)
+	require.True(t, finite)
+	require.Equal(t, date(2024, time.January, 15, 11), finishTime)
+
+	_, finite, err = recurringEvent("FREQ=WEEKLY").SeriesFinishTime()
+	require.NoError(t, err)
+	require.False(t, finite)
+}
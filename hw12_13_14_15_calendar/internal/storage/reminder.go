@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Ограничения напоминаний события.
+const (
+	MaxReminders      = 10
+	MaxReminderOffset = 4 * 7 * 24 * 60 // Четыре недели в минутах
+)
+
+var (
+	ErrInvalidReminder  = errors.New("invalid reminder")
+	ErrReminderNotFound = errors.New("reminder not found")
+	ErrReminderNotSent  = errors.New("reminder was not sent yet")
+)
+
+// Reminder - напоминание о событии за Offset минут до его начала (начала каждого повторения).
+type Reminder struct {
+	ID         uuid.UUID           // Уникальный идентификатор напоминания
+	Offset     int                 // За сколько минут до начала события отправить напоминание
+	Channel    NotificationChannel // Канал доставки, пустое значение - канал из настроек получателя
+	SentAt     *EventTime          // Когда напоминание последний раз было поставлено в очередь
+	Occurrence *EventTime          // Начало события (повторения), о котором было последнее напоминание
+}
+
+// DueReminder - напоминание, которое пора отправить о повторении события Occurrence.
+type DueReminder struct {
+	Reminder   Reminder
+	Occurrence Event
+}
+
+// NewReminders проверяет напоминания reminders и возвращает их с идентификаторами. Напоминание
+// с тем же смещением, что и одно из previous, сохраняет его ID, а если keepSent - и отметку об отправке.
+// Напоминания упорядочены по убыванию смещения.
+func NewReminders(reminders, previous []Reminder, keepSent bool) ([]Reminder, error) {
+	if len(reminders) > MaxReminders {
+		return nil, fmt.Errorf("%w: at most %d reminders are allowed", ErrInvalidReminder, MaxReminders)
+	}
+
+	byOffset := make(map[int]Reminder, len(previous))
+	for _, reminder := range previous {
+		byOffset[reminder.Offset] = reminder
+	}
+
+	result := make([]Reminder, 0, len(reminders))
+	seen := make(map[int]bool, len(reminders))
+	for _, reminder := range reminders {
+		if err := reminder.Validate(); err != nil {
+			return nil, err
+		}
+
+		if seen[reminder.Offset] {
+			return nil, fmt.Errorf("%w: duplicate offset %d", ErrInvalidReminder, reminder.Offset)
+		}
+		seen[reminder.Offset] = true
+
+		reminder.SentAt, reminder.Occurrence = nil, nil
+		if existing, found := byOffset[reminder.Offset]; found {
+			reminder.ID = existing.ID
+			if keepSent {
+				reminder.SentAt, reminder.Occurrence = existing.SentAt, existing.Occurrence
+			}
+		} else {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return nil, err
+			}
+			reminder.ID = id
+		}
+
+		result = append(result, reminder)
+	}
+
+	// Напоминания упорядочиваются по убыванию смещения: сначала самые ранние.
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Offset > result[j].Offset })
+	return result, nil
+}
+
+// Validate проверяет смещение и канал напоминания.
+func (r Reminder) Validate() error {
+	if r.Offset < 0 || r.Offset > MaxReminderOffset {
+		return fmt.Errorf("%w: offset must be between 0 and %d minutes", ErrInvalidReminder, MaxReminderOffset)
+	}
+
+	switch r.Channel {
+	case "", ChannelLog, ChannelEmail, ChannelWebhook:
+		return nil
+	default:
+		return fmt.Errorf("%w: %w %q", ErrInvalidReminder, ErrInvalidNotificationChannel, r.Channel)
+	}
+}
+
+// Reminder возвращает напоминание события id.
+func (e Event) Reminder(id uuid.UUID) (Reminder, bool) {
+	for _, reminder := range e.Reminders {
+		if reminder.ID == id {
+			return reminder, true
+		}
+	}
+
+	return Reminder{}, false
+}
+
+// KeepSentMarks переносит в напоминания события отметки об отправке из сохраненного события stored.
+// Планировщик отмечает напоминания, не изменяя версию события, поэтому изменение, прочитанное до отметки,
+// не должно ее стирать. Если время начала или правило повторения изменились, отметки не переносятся.
+func (e *Event) KeepSentMarks(stored Event) {
+	if !time.Time(e.StartTime).Equal(time.Time(stored.StartTime)) || e.Recurrence.RRule != stored.Recurrence.RRule {
+		return
+	}
+
+	e.Reminders = append([]Reminder(nil), e.Reminders...)
+	for i := range e.Reminders {
+		reminder := &e.Reminders[i]
+		if marked, ok := stored.Reminder(reminder.ID); ok && marked.SentAt != nil {
+			reminder.SentAt, reminder.Occurrence = marked.SentAt, marked.Occurrence
+		}
+	}
+}
+
+// IsReminded возвращает true, если по событию было отправлено хотя бы одно напоминание.
+func (e Event) IsReminded() bool {
+	for _, reminder := range e.Reminders {
+		if reminder.SentAt != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DueReminders возвращает напоминания, которые пора отправить в момент now. Напоминание о событии
+// отправляется один раз, как только до его начала остается не больше Offset минут. Напоминание
+// о повторяющемся событии отправляется о каждом еще не закончившемся повторении, которое начнется
+// не позже чем через Offset минут и о котором напоминание еще не отправлялось.
+func (e Event) DueReminders(now time.Time) ([]DueReminder, error) {
+	result := make([]DueReminder, 0)
+	for _, reminder := range e.Reminders {
+		offset := time.Duration(reminder.Offset) * time.Minute
+		if !e.Recurrence.IsRecurring() {
+			if reminder.SentAt == nil && !time.Time(e.StartTime).Add(-offset).After(now) {
+				result = append(result, DueReminder{Reminder: reminder, Occurrence: e})
+			}
+			continue
+		}
+
+		occurrences, err := e.Occurrences(now, now.Add(offset).Add(time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, occurrence := range occurrences {
+			if reminder.Occurrence != nil &&
+				!time.Time(occurrence.StartTime).After(time.Time(*reminder.Occurrence)) {
+				continue
+			}
+
+			result = append(result, DueReminder{Reminder: reminder, Occurrence: occurrence})
+		}
+	}
+
+	return result, nil
+}
+
+// NextReminderTime возвращает время, когда напоминание r нужно отправить в следующий раз после момента now,
+// или false, если отправлять его больше не нужно. Для повторяющегося события это время напоминания
+// о первом не закончившемся к now повторении, о котором напоминание еще не отправлялось.
+func (e Event) NextReminderTime(r Reminder, now time.Time) (time.Time, bool, error) {
+	offset := time.Duration(r.Offset) * time.Minute
+	if !e.Recurrence.IsRecurring() {
+		return time.Time(e.StartTime).Add(-offset), r.SentAt == nil, nil
+	}
+
+	rule, err := ParseRRule(e.Recurrence.RRule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	startTime := time.Time(e.StartTime).In(e.Location())
+	duration := time.Time(e.FinishTime).Sub(startTime)
+	var next time.Time
+	found := false
+	rule.Iterate(startTime, func(start time.Time) bool {
+		if !start.Add(duration).After(now) || e.isExcluded(start) ||
+			r.Occurrence != nil && !start.After(time.Time(*r.Occurrence)) {
+			return true
+		}
+
+		next, found = start.Add(-offset), true
+		return false
+	})
+
+	return next, found, nil
+}
+
+// MarkReminded отмечает, что напоминания due поставлены в очередь в момент now.
+func (e *Event) MarkReminded(due []DueReminder, now time.Time) {
+	e.Reminders = append([]Reminder(nil), e.Reminders...)
+	sentAt := EventTime(now)
+	for _, d := range due {
+		for i := range e.Reminders {
+			reminder := &e.Reminders[i]
+			if reminder.ID != d.Reminder.ID {
+				continue
+			}
+
+			reminder.SentAt = &sentAt
+			startTime := d.Occurrence.StartTime
+			if reminder.Occurrence == nil || time.Time(startTime).After(time.Time(*reminder.Occurrence)) {
+				reminder.Occurrence = &startTime
+			}
+		}
+	}
+}
+
+func (r Reminder) MarshalJSON() ([]byte, error) {
+	var tmp struct {
+		ID         string
+		Offset     int
+		Channel    string `json:",omitempty"`
+		SentAt     string `json:",omitempty"`
+		Occurrence string `json:",omitempty"`
+	}
+
+	tmp.ID = r.ID.String()
+	tmp.Offset = r.Offset
+	tmp.Channel = string(r.Channel)
+	if r.SentAt != nil {
+		tmp.SentAt = time.Time(*r.SentAt).Format(time.RFC3339)
+	}
+	if r.Occurrence != nil {
+		tmp.Occurrence = time.Time(*r.Occurrence).Format(time.RFC3339)
+	}
+
+	return json.Marshal(tmp)
+}
+
+func (r *Reminder) UnmarshalJSON(data []byte) (err error) {
+	var tmp struct {
+		ID         string
+		Offset     int
+		Channel    string
+		SentAt     string
+		Occurrence string
+	}
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if tmp.ID != "" {
+		if r.ID, err = uuid.FromString(tmp.ID); err != nil {
+			return err
+		}
+	}
+
+	r.Offset = tmp.Offset
+	r.Channel = NotificationChannel(tmp.Channel)
+	if r.SentAt, err = parseNullableTime(tmp.SentAt); err != nil {
+		return err
+	}
+
+	r.Occurrence, err = parseNullableTime(tmp.Occurrence)
+	return err
+}
+
+func parseNullableTime(value string) (*EventTime, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := ParseEventTime(value, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReminders(t *testing.T) {
+	t.Run("ids and order", func(t *testing.T) {
+		reminders, err := NewReminders([]Reminder{{Offset: 15}, {Offset: 60, Channel: ChannelEmail}}, nil, false)
+		require.NoError(t, err)
+		require.Len(t, reminders, 2)
+		require.Equal(t, 60, reminders[0].Offset)
+		require.Equal(t, 15, reminders[1].Offset)
+		require.NotEqual(t, uuid.Nil, reminders[0].ID)
+		require.NotEqual(t, reminders[0].ID, reminders[1].ID)
+	})
+
+	t.Run("previous reminders", func(t *testing.T) {
+		sentAt := EventTime(date(2024, time.January, 1, 9))
+		previous := []Reminder{{ID: uuid.Must(uuid.NewV4()), Offset: 15, SentAt: &sentAt, Occurrence: &sentAt}}
+
+		reminders, err := NewReminders([]Reminder{{Offset: 15}, {Offset: 5}}, previous, true)
+		require.NoError(t, err)
+		require.Equal(t, previous[0], reminders[0])
+		require.NotEqual(t, previous[0].ID, reminders[1].ID)
+		require.Nil(t, reminders[1].SentAt)
+
+		reminders, err = NewReminders([]Reminder{{Offset: 15}}, previous, false)
+		require.NoError(t, err)
+		require.Equal(t, previous[0].ID, reminders[0].ID)
+		require.Nil(t, reminders[0].SentAt)
+		require.Nil(t, reminders[0].Occurrence)
+	})
+
+	t.Run("sent state is not accepted from client", func(t *testing.T) {
+		sentAt := EventTime(date(2024, time.January, 1, 9))
+		reminders, err := NewReminders([]Reminder{{Offset: 15, SentAt: &sentAt}}, nil, true)
+		require.NoError(t, err)
+		require.Nil(t, reminders[0].SentAt)
+	})
+
+	for name, reminders := range map[string][]Reminder{
+		"negative offset":  {{Offset: -1}},
+		"too large offset": {{Offset: MaxReminderOffset + 1}},
+		"unknown channel":  {{Offset: 15, Channel: "sms"}},
+		"duplicate offset": {{Offset: 15}, {Offset: 15, Channel: ChannelEmail}},
+		"too many":         make([]Reminder, MaxReminders+1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewReminders(reminders, nil, false)
+			require.ErrorIs(t, err, ErrInvalidReminder)
+		})
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	t.Run("single event", func(t *testing.T) {
+		event := recurringEvent("")
+		due, err := event.DueReminders(date(2024, time.January, 1, 9))
+		require.NoError(t, err)
+		require.Empty(t, due)
+
+		now := date(2024, time.January, 1, 9).Add(50 * time.Minute)
+		due, err = event.DueReminders(now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, event.Reminders[0].ID, due[0].Reminder.ID)
+		require.Equal(t, date(2024, time.January, 1, 10), time.Time(due[0].Occurrence.StartTime))
+
+		event.MarkReminded(due, now)
+		require.True(t, event.IsReminded())
+		require.Equal(t, EventTime(now), *event.Reminders[0].SentAt)
+		due, err = event.DueReminders(now)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("several reminders", func(t *testing.T) {
+		event := recurringEvent("")
+		event.Reminders = append(event.Reminders, Reminder{ID: uuid.Must(uuid.NewV4()), Offset: 60})
+
+		now := date(2024, time.January, 1, 9).Add(10 * time.Minute)
+		due, err := event.DueReminders(now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, 60, due[0].Reminder.Offset)
+
+		event.MarkReminded(due, now)
+		now = now.Add(40 * time.Minute)
+		due, err = event.DueReminders(now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, 15, due[0].Reminder.Offset)
+	})
+
+	t.Run("recurring event", func(t *testing.T) {
+		event := recurringEvent("FREQ=DAILY")
+		now := date(2024, time.January, 3, 9).Add(50 * time.Minute)
+		due, err := event.DueReminders(now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, date(2024, time.January, 3, 10), time.Time(due[0].Occurrence.StartTime))
+
+		previous := event.Reminders
+		event.MarkReminded(due, now)
+		require.Nil(t, previous[0].SentAt, "reminders of the original event must not change")
+		require.Equal(t, EventTime(date(2024, time.January, 3, 10)), *event.Reminders[0].Occurrence)
+		due, err = event.DueReminders(now)
+		require.NoError(t, err)
+		require.Empty(t, due)
+
+		due, err = event.DueReminders(now.Add(24 * time.Hour))
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, date(2024, time.January, 4, 10), time.Time(due[0].Occurrence.StartTime))
+	})
+}
+
+func TestNextReminderTime(t *testing.T) {
+	t.Run("single event", func(t *testing.T) {
+		event := recurringEvent("")
+		next, found, err := event.NextReminderTime(event.Reminders[0], date(2024, time.January, 1, 9))
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, date(2024, time.January, 1, 9).Add(45*time.Minute), next)
+
+		event.MarkReminded([]DueReminder{{Reminder: event.Reminders[0], Occurrence: event}}, next)
+		_, found, err = event.NextReminderTime(event.Reminders[0], next)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("recurring event", func(t *testing.T) {
+		event := recurringEvent("FREQ=DAILY;COUNT=3", EventTime(date(2024, time.January, 2, 10)))
+		next, found, err := event.NextReminderTime(event.Reminders[0], date(2024, time.January, 1, 12))
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, date(2024, time.January, 3, 9).Add(45*time.Minute), next)
+
+		due, err := event.DueReminders(next)
+		require.NoError(t, err)
+		event.MarkReminded(due, next)
+		_, found, err = event.NextReminderTime(event.Reminders[0], next)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
+
+func TestKeepSentMarks(t *testing.T) {
+	stored := recurringEvent("FREQ=DAILY")
+	sentAt := EventTime(date(2024, time.January, 1, 9))
+	stored.Reminders[0].SentAt, stored.Reminders[0].Occurrence = &sentAt, &stored.StartTime
+
+	event := stored
+	event.Title = "Daily"
+	event.Reminders = []Reminder{{ID: stored.Reminders[0].ID, Offset: 15}}
+	event.KeepSentMarks(stored)
+	require.Equal(t, stored.Reminders, event.Reminders)
+
+	event.Reminders = []Reminder{{ID: stored.Reminders[0].ID, Offset: 15}}
+	event.StartTime = EventTime(date(2024, time.January, 1, 12))
+	event.KeepSentMarks(stored)
+	require.Nil(t, event.Reminders[0].SentAt)
+}
+
+func TestReminderJSON(t *testing.T) {
+	sentAt := EventTime(date(2024, time.January, 1, 9))
+	reminder := Reminder{ID: uuid.Must(uuid.NewV4()), Offset: 15, Channel: ChannelEmail, SentAt: &sentAt}
+
+	data, err := json.Marshal(reminder)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "Occurrence")
+
+	var decoded Reminder
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, reminder.ID, decoded.ID)
+	require.Equal(t, reminder.Offset, decoded.Offset)
+	require.Equal(t, reminder.Channel, decoded.Channel)
+	require.True(t, time.Time(sentAt).Equal(time.Time(*decoded.SentAt)))
+	require.Nil(t, decoded.Occurrence)
+}
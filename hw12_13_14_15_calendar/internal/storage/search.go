@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Веса совпадений для ранжирования результатов поиска, как веса A и B в ts_rank PostgreSQL.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// SearchTerms разбивает текст на слова для полнотекстового поиска: последовательности букв и цифр
+// в нижнем регистре без повторов, как конфигурация simple в PostgreSQL.
+func SearchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+
+	return result
+}
+
+// SearchRank возвращает релевантность события для слов terms. Совпадения в названии
+// весят больше совпадений в описании. Если какого-то слова в событии нет, возвращается 0.
+func (e Event) SearchRank(terms []string) float64 {
+	counts := make(map[string]float64)
+	for _, word := range SearchTerms(e.Title) {
+		counts[word] += titleWeight
+	}
+
+	for _, word := range SearchTerms(e.Description) {
+		counts[word] += descriptionWeight
+	}
+
+	rank := 0.0
+	for _, term := range terms {
+		if counts[term] == 0 {
+			return 0
+		}
+		rank += counts[term]
+	}
+
+	return rank
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTerms(t *testing.T) {
+	require.Equal(t, []string{"team", "stand", "up", "q3", "встреча"}, SearchTerms("Team stand-up, Q3: встреча team"))
+	require.Empty(t, SearchTerms(" ,.- "))
+}
+
+func TestSearchRank(t *testing.T) {
+	event := Event{Title: "Budget review", Description: "Review the budget for Q3"}
+	require.InDelta(t, 1.4, event.SearchRank([]string{"budget"}), 1e-9)
+	require.InDelta(t, 0.4, event.SearchRank([]string{"q3"}), 1e-9)
+	require.InDelta(t, 1.8, event.SearchRank([]string{"q3", "budget"}), 1e-9)
+	require.Zero(t, event.SearchRank([]string{"budget", "lunch"}))
+}
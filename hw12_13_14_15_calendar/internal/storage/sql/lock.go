@@ -0,0 +1,122 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// lockCheckInterval - период проверки соединения, удерживающего advisory-блокировку.
+const lockCheckInterval = 5 * time.Second
+
+// lock - сессионная advisory-блокировка PostgreSQL. Блокировка удерживается, пока открыто соединение,
+// в котором она захвачена: если процесс завершился или соединение разорвано, PostgreSQL снимает ее сам.
+type lock struct {
+	key      int64
+	conn     *sql.Conn
+	lost     chan struct{}
+	lostOnce sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	watching sync.WaitGroup
+}
+
+// TryLock захватывает advisory-блокировку name в отдельном соединении. Возвращает storage.ErrLocked,
+// если блокировку удерживает другая сессия.
+func (s *Storage) TryLock(ctx context.Context, name string) (storage.Lock, error) {
+	return s.acquire(ctx, name, "select pg_try_advisory_lock($1)")
+}
+
+// Lock захватывает advisory-блокировку name в отдельном соединении. Если блокировку удерживает
+// другая сессия, ждет ее освобождения, пока не отменен контекст.
+func (s *Storage) Lock(ctx context.Context, name string) (storage.Lock, error) {
+	return s.acquire(ctx, name, "select true from pg_advisory_lock($1)")
+}
+
+// acquire захватывает блокировку name в отдельном соединении запросом query, который возвращает
+// признак захвата блокировки.
+func (s *Storage) acquire(ctx context.Context, name, query string) (storage.Lock, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &lock{key: lockKey(name), conn: conn, lost: make(chan struct{}), stop: make(chan struct{})}
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, query, l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, storage.ErrLocked
+	}
+
+	l.watching.Add(1)
+	go l.watch()
+	return l, nil
+}
+
+// lockKey возвращает ключ advisory-блокировки для имени name.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// watch периодически проверяет соединение и закрывает канал lost, если оно разорвано.
+func (l *lock) watch() {
+	defer l.watching.Done()
+	ticker := time.NewTicker(lockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), lockCheckInterval)
+			err := l.conn.PingContext(ctx)
+			cancel()
+			if err != nil {
+				l.markLost()
+				return
+			}
+		}
+	}
+}
+
+func (l *lock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+func (l *lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Unlock снимает блокировку и закрывает соединение. Если соединение уже разорвано,
+// блокировку сняла PostgreSQL и снимать ее не нужно.
+func (l *lock) Unlock(ctx context.Context) (err error) {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+		l.watching.Wait()
+
+		select {
+		case <-l.lost:
+		default:
+			_, err = l.conn.ExecContext(ctx, "select pg_advisory_unlock($1)", l.key)
+			l.markLost()
+		}
+
+		if closeErr := l.conn.Close(); err == nil {
+			err = closeErr
+		}
+	})
+
+	return err
+}
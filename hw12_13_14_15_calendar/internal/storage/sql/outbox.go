@@ -0,0 +1,284 @@
+package sqlstorage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/storage"
+)
+
+// EnqueueNotifications ставит в outbox напоминания, которые пора отправить, и отмечает
+// их отправленными в той же транзакции. Выбираются и блокируются только события, у которых
+// наступило время следующей отправки напоминания (индекс event_reminders_due_idx). События,
+// заблокированные другим планировщиком, пропускаются. Версия события не изменяется: отметки
+// об отправке не видны клиенту как изменение события. Возвращает количество поставленных уведомлений.
+func (s *Storage) EnqueueNotifications(ctx context.Context) (enqueued int, err error) {
+	ctx, end := startQuery(ctx, "enqueue_notifications")
+	defer end()
+
+	query := `select ` + eventColumns + `
+			  from
+			    events
+			  where
+			    id in (select event_id from event_reminders where due_at <= now())
+			  for update skip locked`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryxContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		due, err := event.DueReminders(now)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, reminder := range due {
+			inserted, err := insertOutboxMessages(ctx, tx, storage.NewOutboxMessages(reminder))
+			if err != nil {
+				return 0, err
+			}
+			enqueued += inserted
+		}
+
+		event.MarkReminded(due, now)
+		if err = updateReminders(ctx, tx, event, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return enqueued, nil
+}
+
+// updateReminders сохраняет отметки об отправке напоминаний события и время их следующей отправки.
+func updateReminders(ctx context.Context, tx *sqlx.Tx, event storage.Event, now time.Time) error {
+	query := "update event_reminders set sent_at = $2, occurrence = $3, due_at = $4 where id = $1"
+	for _, reminder := range event.Reminders {
+		dueAt, err := reminderDueTime(event, reminder, now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query, reminder.ID, formatNullableTime(reminder.SentAt),
+			formatNullableTime(reminder.Occurrence), dueAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ScheduleNotification ставит сообщение в outbox, если сообщения с тем же ключом там еще не было.
+func (s *Storage) ScheduleNotification(ctx context.Context, message storage.OutboxMessage) error {
+	ctx, end := startQuery(ctx, "schedule_notification")
+	defer end()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = insertOutboxMessages(ctx, tx, []storage.OutboxMessage{message}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertOutboxMessages записывает сообщения в outbox, пропуская уже поставленные уведомления.
+// Сообщение без NotBefore можно публиковать сразу. События дайджеста хранятся в формате JSON.
+func insertOutboxMessages(ctx context.Context, tx *sqlx.Tx, messages []storage.OutboxMessage) (int, error) {
+	query := `insert into notification_outbox(dedupe_key, type, event_id, reminder_id, user_id, channel, title,
+			    start_time, recurring, not_before, agenda, trace_parent)
+			  values($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce($10::timestamptz, now()), $11, $12)
+			  on conflict (dedupe_key) do nothing`
+
+	inserted := 0
+	for _, message := range messages {
+		var notBefore *string
+		if !message.NotBefore.IsZero() {
+			value := message.NotBefore.UTC().Format(time.RFC3339)
+			notBefore = &value
+		}
+
+		agenda, err := formatAgenda(message.Agenda)
+		if err != nil {
+			return 0, err
+		}
+
+		messageType := message.Type
+		if messageType == "" {
+			messageType = storage.NotificationReminder
+		}
+
+		result, err := tx.ExecContext(ctx, query, message.Key, string(messageType), message.EventID,
+			message.ReminderID, message.UserID, string(message.Channel), message.Title,
+			time.Time(message.StartTime).UTC().Format(time.RFC3339), message.Recurring, notBefore, agenda,
+			message.TraceParent)
+		if err != nil {
+			return 0, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += int(rowsAffected)
+	}
+
+	return inserted, nil
+}
+
+// RelayNotifications публикует до limit первых неопубликованных сообщений outbox, время публикации
+// которых наступило, функцией publish и отмечает опубликованные. Сообщения, выбранные другим
+// планировщиком, пропускаются. Если отметка не сохранилась, сообщения будут опубликованы повторно.
+func (s *Storage) RelayNotifications(ctx context.Context, limit int,
+	publish storage.PublishFunc,
+) (published int, err error) {
+	ctx, end := startQuery(ctx, "relay_notifications")
+	defer end()
+
+	query := `select
+			    id, dedupe_key, type, event_id, coalesce(reminder_id, '00000000-0000-0000-0000-000000000000'),
+			    user_id, channel, title, start_time, recurring, not_before, agenda, trace_parent
+			  from
+			    notification_outbox
+			  where
+			    published_at is null and not_before <= now()
+			  order by
+			    id
+			  limit $1
+			  for update skip locked`
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	messages, err := selectOutboxMessages(ctx, tx, query, limit)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	published, publishErr := publish(ctx, messages)
+	for _, message := range messages[:published] {
+		query := "update notification_outbox set published_at = now() where id = $1"
+		if _, err = tx.ExecContext(ctx, query, message.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
+
+func selectOutboxMessages(ctx context.Context, tx *sqlx.Tx, query string,
+	args ...interface{},
+) ([]storage.OutboxMessage, error) {
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]storage.OutboxMessage, 0)
+	for rows.Next() {
+		var message storage.OutboxMessage
+		var messageType, channel, agenda string
+		err = rows.Scan(&message.ID, &message.Key, &messageType, &message.EventID, &message.ReminderID,
+			&message.UserID, &channel, &message.Title, &message.StartTime, &message.Recurring, &message.NotBefore,
+			&agenda, &message.TraceParent)
+		if err != nil {
+			return nil, err
+		}
+		message.Type = storage.NotificationType(messageType)
+		message.Channel = storage.NotificationChannel(channel)
+		if message.Agenda, err = parseAgenda(agenda); err != nil {
+			return nil, err
+		}
+
+		result = append(result, message)
+	}
+
+	return result, rows.Err()
+}
+
+// formatAgenda возвращает события дайджеста в формате JSON, пустую строку - для напоминаний.
+func formatAgenda(agenda []storage.AgendaItem) (string, error) {
+	if len(agenda) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(agenda)
+	return string(data), err
+}
+
+func parseAgenda(value string) ([]storage.AgendaItem, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var agenda []storage.AgendaItem
+	if err := json.Unmarshal([]byte(value), &agenda); err != nil {
+		return nil, err
+	}
+
+	return agenda, nil
+}
+
+// IsNotificationDelivered возвращает true, если уведомление key уже доставлено.
+func (s *Storage) IsNotificationDelivered(ctx context.Context, key string) (delivered bool, err error) {
+	ctx, end := startQuery(ctx, "is_notification_delivered")
+	defer end()
+
+	query := `select exists(
+			    select 1 from notification_outbox where dedupe_key = $1 and delivered_at is not null)`
+	err = s.db.QueryRowxContext(ctx, query, key).Scan(&delivered)
+	return delivered, err
+}
+
+// MarkNotificationDelivered отмечает уведомление key доставленным. Возвращает false,
+// если уведомление уже было доставлено раньше или его нет в outbox.
+func (s *Storage) MarkNotificationDelivered(ctx context.Context, key string) (first bool, err error) {
+	ctx, end := startQuery(ctx, "mark_notification_delivered")
+	defer end()
+
+	query := "update notification_outbox set delivered_at = now() where dedupe_key = $1 and delivered_at is null"
+	result, err := s.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,38 @@
+package sqlstorage
+
+import (
+	"context"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/logger"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// fieldQuery - поле журнала с названием запроса.
+const fieldQuery = "query"
+
+// startQuery начинает span запроса query к PostgreSQL и возвращает контекст этого span и функцию,
+// которая завершает span, учитывает длительность запроса в метриках и пишет ее в журнал
+// с полями запроса из ctx:
+//
+//	ctx, end := startQuery(ctx, "create_event")
+//	defer end()
+func startQuery(ctx context.Context, query string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "storage "+query,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(query)))
+
+	return ctx, func() {
+		span.End()
+		metrics.ObserveStorageQuery(query, start)
+		logger.FromContext(ctx).WithFields(logger.Fields{
+			fieldQuery:           query,
+			logger.FieldDuration: time.Since(start).Seconds(),
+		}).Debug("storage query")
+	}
+}
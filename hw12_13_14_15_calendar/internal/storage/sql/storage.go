@@ -2,8 +2,12 @@ package sqlstorage
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -20,6 +24,8 @@ type Storage struct {
 	db     *sqlx.DB
 }
 
+var errNotConnected = errors.New("storage is not connected")
+
 func (s *Storage) Connect() error {
 	var err error
 	s.db, err = sqlx.Open("pgx", s.dsn)
@@ -35,30 +41,77 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
+// Ping проверяет соединение с PostgreSQL.
+func (s *Storage) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return errNotConnected
+	}
+
+	return s.db.PingContext(ctx)
+}
+
 func (s *Storage) CreateEvent(ctx context.Context, event storage.Event) error {
-	query := `insert into events(id, user_id, title, description, start_time, finish_time, notify_before, 
-		        notification_sent) 
-	          values($1, $2, $3, $4, $5, $6, $7, $8)`
+	ctx, end := startQuery(ctx, "create_event")
+	defer end()
+
+	seriesFinishTime, err := getSeriesFinishTime(event)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into events(id, user_id, title, description, start_time, finish_time, rrule, exdates,
+		        series_finish_time, time_zone, transparent, trace_parent)
+	          values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// strconv.FormatInt(int64(time.Duration(event.Duration)), 10),
-	_, err := s.db.ExecContext(
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		event.ID.String(),
 		event.UserID.String(),
 		event.Title,
 		event.Description,
-		time.Time(event.StartTime).Format(time.RFC3339),
-		time.Time(event.FinishTime).Format(time.RFC3339),
-		strconv.Itoa(event.NotifyBefore),
-		false)
+		time.Time(event.StartTime).UTC().Format(time.RFC3339),
+		time.Time(event.FinishTime).UTC().Format(time.RFC3339),
+		event.Recurrence.RRule,
+		storage.FormatExDates(event.Recurrence.ExDates),
+		seriesFinishTime,
+		getTimeZone(event),
+		event.Transparent,
+		event.TraceParent)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if err = saveAttendees(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err = saveReminders(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// patchAttempts ограничивает число повторов PatchEvent при одновременном изменении события.
+const patchAttempts = 3
+
+// UpdateEvent сохраняет событие, если его версия не изменилась с момента чтения.
 func (s *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
+	ctx, end := startQuery(ctx, "update_event")
+	defer end()
+
+	seriesFinishTime, err := getSeriesFinishTime(event)
+	if err != nil {
+		return err
+	}
+
 	query := `update
 			    events
 			  set
@@ -67,132 +120,207 @@ func (s *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
 				description = $4,
 				start_time = $5,
 				finish_time = $6,
-				notify_before = $7,
-				notification_sent = $8
+				rrule = $7,
+				exdates = $8,
+				series_finish_time = $9,
+				time_zone = $10,
+				transparent = $11,
+				trace_parent = $12,
+				version = version + 1
 			  where
-			    id = $1`
+			    id = $1 and version = $13`
 
-	_, err := s.db.ExecContext(
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = keepSentMarks(ctx, tx, &event); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(
 		ctx,
 		query,
 		event.ID.String(),
 		event.UserID.String(),
 		event.Title,
 		event.Description,
-		time.Time(event.StartTime).Format(time.RFC3339),
-		time.Time(event.FinishTime).Format(time.RFC3339),
-		event.NotifyBefore,
-		event.NotificationSent)
+		time.Time(event.StartTime).UTC().Format(time.RFC3339),
+		time.Time(event.FinishTime).UTC().Format(time.RFC3339),
+		event.Recurrence.RRule,
+		storage.FormatExDates(event.Recurrence.ExDates),
+		seriesFinishTime,
+		getTimeZone(event),
+		event.Transparent,
+		event.TraceParent,
+		event.Version)
+	if err != nil {
+		return err
+	}
+
+	if err = checkEventVersion(ctx, tx, result, event.ID); err != nil {
+		return err
+	}
+
+	if err = saveAttendees(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err = saveReminders(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// keepSentMarks блокирует событие и переносит в его изменение event отметки об отправке напоминаний,
+// которые планировщик сохранил после того, как изменение было прочитано. Сохраненное событие читается
+// отдельным запросом уже после блокировки, чтобы увидеть отметки, записанные до ее получения.
+func keepSentMarks(ctx context.Context, tx *sqlx.Tx, event *storage.Event) error {
+	if _, err := tx.ExecContext(ctx, "select 1 from events where id = $1 for update", event.ID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryxContext(ctx, `select `+eventColumns+` from events where id = $1`, event.ID)
+	if err != nil {
+		return err
+	}
+
+	stored, err := scanEvents(rows)
 	if err != nil {
 		return err
 	}
 
+	if len(stored) > 0 {
+		event.KeepSentMarks(stored[0])
+	}
+
 	return nil
 }
 
-func (s *Storage) PatchEvent(ctx context.Context, id uuid.UUID, userID *uuid.UUID, title, description *string,
-	startTime, finishTime *storage.EventTime, notifyBefore *int, notificationSent *bool,
-) error {
-	var event storage.Event
-	query := `select
-		id,
-		user_id,
-		title,
-		description,
-		start_time,
-		finish_time,
-		notify_before,
-		notification_sent
-	  from
-		events
-	  where
-		  id = $1`
-	rows, err := s.db.QueryxContext(ctx, query, id)
+// checkEventVersion возвращает ошибку, если запрос не затронул событие id: storage.ErrEventNotFound,
+// если события нет, и storage.ErrVersionMismatch, если не совпала его версия.
+func checkEventVersion(ctx context.Context, db sqlx.QueryerContext, result sql.Result, id uuid.UUID) error {
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	if affected > 0 {
+		return nil
+	}
 
-	for rows.Next() {
-		err = rows.Scan(&event.ID, &event.UserID, &event.Title, &event.Description, &event.StartTime,
-			&event.FinishTime, &event.NotifyBefore, &event.NotificationSent)
-		if err != nil {
-			return err
-		}
+	var exists bool
+	if err = sqlx.GetContext(ctx, db, &exists, "select exists(select 1 from events where id = $1)", id); err != nil {
+		return err
+	}
 
-		if userID != nil {
-			event.UserID = *userID
-		}
+	if !exists {
+		return storage.ErrEventNotFound
+	}
 
-		if title != nil {
-			event.Title = *title
-		}
+	return storage.ErrVersionMismatch
+}
 
-		if description != nil {
-			event.Description = *description
-		}
+func (s *Storage) UpdateAttendeeStatus(ctx context.Context, eventID, userID uuid.UUID,
+	status storage.AttendeeStatus,
+) error {
+	ctx, end := startQuery(ctx, "update_attendee_status")
+	defer end()
 
-		if startTime != nil {
-			event.StartTime = *startTime
-		}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-		if finishTime != nil {
-			event.FinishTime = *finishTime
-		}
+	query := "update event_attendees set status = $3 where event_id = $1 and user_id = $2"
+	result, err := tx.ExecContext(ctx, query, eventID, userID, string(status))
+	if err != nil {
+		return err
+	}
 
-		if notifyBefore != nil {
-			event.NotifyBefore = *notifyBefore
-		}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 
-		if notificationSent != nil {
-			event.NotificationSent = *notificationSent
-		}
+	if updated == 0 {
+		return storage.ErrAttendeeNotFound
+	}
 
-		query = `update
-				events
-			set
-				user_id = $2,
-				title = $3,
-				description = $4,
-				start_time = $5,
-				finish_time = $6,
-				notify_before = $7,
-				notification_sent = $8
-			where
-				id = $1`
-
-		_, err = s.db.ExecContext(
-			ctx,
-			query,
-			event.ID.String(),
-			event.UserID.String(),
-			event.Title,
-			event.Description,
-			time.Time(event.StartTime).Format(time.RFC3339),
-			time.Time(event.FinishTime).Format(time.RFC3339),
-			event.NotifyBefore,
-			event.NotificationSent)
+	_, err = tx.ExecContext(ctx, "update events set version = version + 1 where id = $1", eventID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PatchEvent изменяет поля события независимо от его версии. Если событие было изменено
+// между чтением и записью, изменение повторяется.
+func (s *Storage) PatchEvent(ctx context.Context, id uuid.UUID, patch storage.EventPatch) error {
+	ctx, end := startQuery(ctx, "patch_event")
+	defer end()
+
+	var err error
+	for attempt := 0; attempt < patchAttempts; attempt++ {
+		var event storage.Event
+		event, err = s.GetEvent(ctx, id)
 		if err != nil {
 			return err
 		}
+
+		patch.Apply(&event)
+		err = s.UpdateEvent(ctx, event)
+		if !errors.Is(err, storage.ErrVersionMismatch) {
+			return err
+		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return err
+	return err
+}
+
+func (s *Storage) GetEvent(ctx context.Context, id uuid.UUID) (storage.Event, error) {
+	ctx, end := startQuery(ctx, "get_event")
+	defer end()
+
+	query := `select ` + eventColumns + `
+	  from
+		events
+	  where
+		  id = $1`
+	rows, err := s.db.QueryxContext(ctx, query, id)
+	if err != nil {
+		return storage.Event{}, err
 	}
 
-	return nil
+	events, err := scanEvents(rows)
+	if err != nil {
+		return storage.Event{}, err
+	}
+
+	if len(events) == 0 {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+
+	return events[0], nil
 }
 
-func (s *Storage) DeleteEvent(ctx context.Context, id uuid.UUID) error {
-	query := "delete from events where id = $1"
-	_, err := s.db.ExecContext(ctx, query, id)
+// DeleteEvent удаляет событие. Если version не равна 0, событие удаляется, только если его версия совпадает.
+func (s *Storage) DeleteEvent(ctx context.Context, id uuid.UUID, version int64) error {
+	ctx, end := startQuery(ctx, "delete_event")
+	defer end()
+
+	query := "delete from events where id = $1 and ($2::bigint = 0 or version = $2)"
+	result, err := s.db.ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return checkEventVersion(ctx, s.db, result, id)
 }
 
 func (s *Storage) ListEventsByDate(ctx context.Context, userID uuid.UUID,
@@ -216,72 +344,266 @@ func (s *Storage) ListEventsByMonth(ctx context.Context, userID uuid.UUID,
 func (s *Storage) ListEventsByPeriod(ctx context.Context, userID uuid.UUID, startDate,
 	finishDate storage.EventDate,
 ) ([]storage.Event, error) {
-	result := make([]storage.Event, 0)
+	ctx, end := startQuery(ctx, "list_events_by_period")
+	defer end()
 
-	query := `select
-				id,
-				user_id,
-				title,
-				description,
-				start_time,
-				finish_time,
-				notify_before,
-				notification_sent
+	query := `select ` + eventColumns + `
 			  from
 			    events
 			  where
-			  	user_id = $1 and start_time < $3 and finish_time > $2`
-	rows, err := s.db.QueryxContext(ctx, query, userID, time.Time(startDate).Format(time.RFC3339),
-		time.Time(finishDate).Format(time.RFC3339))
+			  	id in ` + visibleEventIDs + ` and
+			  	start_time < $3 and (series_finish_time is null or series_finish_time > $2)`
+	rows, err := s.db.QueryxContext(ctx, query, userID, time.Time(startDate).UTC().Format(time.RFC3339),
+		time.Time(finishDate).UTC().Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var event storage.Event
-		err := rows.Scan(&event.ID, &event.UserID, &event.Title, &event.Description, &event.StartTime,
-			&event.FinishTime, &event.NotifyBefore, &event.NotificationSent)
-		if err != nil {
-			return nil, err
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ExpandOccurrences(events, time.Time(startDate), time.Time(finishDate))
+}
+
+// ListEvents возвращает страницу событий, удовлетворяющих условиям выборки query.
+// Страницы выбираются по ключу (start_time, id), поэтому их получение не замедляется с ростом смещения.
+// События владельца и события, на которые он приглашен, выбираются отдельными запросами со своим порядком
+// и ограничением: первый читает индекс events_keyset_idx, второй - индекс event_attendees_user_idx.
+func (s *Storage) ListEvents(ctx context.Context, query storage.EventQuery) (storage.EventPage, error) {
+	ctx, end := startQuery(ctx, "list_events")
+	defer end()
+
+	var conditions []string
+	args := []interface{}{query.UserID}
+	addCondition := func(condition string, values ...interface{}) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1)
 		}
+		conditions = append(conditions, condition)
+	}
 
-		result = append(result, event)
+	if query.From != nil {
+		addCondition("(series_finish_time is null or series_finish_time > ?)",
+			time.Time(*query.From).UTC().Format(time.RFC3339Nano))
 	}
 
-	if err := rows.Err(); err != nil {
+	if query.To != nil {
+		addCondition("start_time < ?", time.Time(*query.To).UTC().Format(time.RFC3339Nano))
+	}
+
+	if query.Title != "" {
+		addCondition("strpos(lower(title), lower(?)) > 0", query.Title)
+	}
+
+	if query.NotificationSent != nil {
+		addCondition(`exists (select 1 from event_reminders r
+			where r.event_id = events.id and r.sent_at is not null) = ?`, *query.NotificationSent)
+	}
+
+	direction, comparison := "asc", ">"
+	if query.Order == storage.SortDesc {
+		direction, comparison = "desc", "<"
+	}
+
+	if query.After != nil {
+		addCondition("(start_time, id) "+comparison+" (?, ?)",
+			query.After.StartTime.UTC().Format(time.RFC3339Nano), query.After.ID)
+	}
+
+	limit := query.PageSize()
+	args = append(args, limit+1)
+	orderBy := `
+			  order by
+			    start_time ` + direction + `, id ` + direction + `
+			  limit $` + strconv.Itoa(len(args))
+	branch := func(visibility string) string {
+		return `(select ` + eventColumns + `
+			  from
+			    events
+			  where
+			    ` + strings.Join(append([]string{visibility}, conditions...), " and ") + orderBy + `)`
+	}
+
+	sqlQuery := branch("user_id = $1") + `
+			  union all
+			  ` + branch(`user_id <> $1 and id in (select event_id from event_attendees
+			    where user_id = $1 and status <> 'declined')`) + orderBy
+	rows, err := s.db.QueryxContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return storage.EventPage{}, err
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return storage.EventPage{}, err
+	}
+
+	page := storage.EventPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.Next = storage.CursorOf(page.Events[limit-1])
+	}
+
+	return page, nil
+}
+
+// SearchEvents возвращает события, видимые пользователю, в названии или описании которых встречаются
+// все слова terms, в порядке убывания релевантности. Поиск выполняется по индексу events_search_idx.
+func (s *Storage) SearchEvents(ctx context.Context, userID uuid.UUID, terms []string,
+	limit int,
+) ([]storage.Event, error) {
+	ctx, end := startQuery(ctx, "search_events")
+	defer end()
+
+	query := `select ` + eventColumns + `
+			  from
+			    events
+			  where
+			    id in ` + visibleEventIDs + ` and
+			    search_vector @@ plainto_tsquery('simple', $2)
+			  order by
+			    ts_rank(search_vector, plainto_tsquery('simple', $2)) desc, start_time, id
+			  limit $3`
+	rows, err := s.db.QueryxContext(ctx, query, userID, strings.Join(terms, " "), limit)
+	if err != nil {
 		return nil, err
 	}
 
+	return scanEvents(rows)
+}
+
+// ListBusyEvents возвращает события и повторения пользователя, которые занимают время в периоде
+// [startTime, finishTime), включая события, приглашение на которые пользователь принял.
+// Однократные события владельца выбираются по индексу list_events_idx.
+func (s *Storage) ListBusyEvents(ctx context.Context, userID uuid.UUID, startTime,
+	finishTime storage.EventTime,
+) ([]storage.Event, error) {
+	ctx, end := startQuery(ctx, "list_busy_events")
+	defer end()
+
+	query := `select ` + eventColumns + `
+			  from
+			    events
+			  where
+			    user_id = $1 and start_time < $3 and finish_time > $2 and rrule = '' and not transparent
+			  union all
+			  select ` + eventColumns + `
+			  from
+			    events
+			  where
+			    user_id = $1 and start_time < $3 and (series_finish_time is null or series_finish_time > $2) and
+			    rrule <> '' and not transparent
+			  union all
+			  select ` + eventColumns + `
+			  from
+			    events
+			    join event_attendees a on a.event_id = events.id
+			  where
+			    a.user_id = $1 and a.status = 'accepted' and start_time < $3 and
+			    (series_finish_time is null or series_finish_time > $2) and not transparent`
+	rows, err := s.db.QueryxContext(ctx, query, userID, time.Time(startTime).UTC().Format(time.RFC3339),
+		time.Time(finishTime).UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.Event, 0, len(events))
+	for _, event := range events {
+		occurrences, err := event.Occurrences(time.Time(startTime), time.Time(finishTime))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, occurrences...)
+	}
+
 	return result, nil
 }
 
-func (s *Storage) SelectEventsToNotify(ctx context.Context) ([]storage.Event, error) {
-	result := make([]storage.Event, 0)
+// PurgeEvents удаляет завершившиеся события и опубликованные уведомления старше purgeIntervalDays дней.
+func (s *Storage) PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error) {
+	ctx, end := startQuery(ctx, "purge_events")
+	defer end()
+
+	query := "delete from notification_outbox where published_at < now() - interval '1 day' * $1"
+	if _, err = s.db.ExecContext(ctx, query, purgeIntervalDays); err != nil {
+		return 0, err
+	}
+
+	query = "delete from events where series_finish_time < now() - interval '1 day' * $1"
+	result, err := s.db.ExecContext(ctx, query, purgeIntervalDays)
+	if err != nil {
+		return 0, err
+	}
+
+	purgedEvents, err = result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return purgedEvents, nil
+}
 
-	query := `select
+// visibleEventIDs выбирает идентификаторы событий, видимых пользователю $1: его собственных
+// и тех, приглашение на которые он не отклонил. Каждая часть выборки читает свой индекс.
+const visibleEventIDs = `(select id from events where user_id = $1
+			    union all
+			    select event_id from event_attendees where user_id = $1 and status <> 'declined')`
+
+const eventColumns = `
 				id,
 				user_id,
 				title,
 				description,
 				start_time,
 				finish_time,
-				notify_before,
-				notification_sent
-			  from
-			    events
-			  where
-			    notification_sent is not true and start_time <= now() + interval '1 minute' * notify_before`
-	rows, err := s.db.QueryxContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
+				rrule,
+				exdates,
+				time_zone,
+				transparent,
+				version,
+				trace_parent,
+				coalesce((select string_agg(a.user_id::text || ':' || a.status, ',' order by a.user_id)
+				  from event_attendees a where a.event_id = events.id), '') as attendees,
+				coalesce((select json_agg(json_build_object('id', r.id, 'offset', r.offset_minutes,
+				  'channel', r.channel, 'sentAt', r.sent_at, 'occurrence', r.occurrence)
+				  order by r.offset_minutes desc)
+				  from event_reminders r where r.event_id = events.id), '[]') as reminders`
+
+func scanEvents(rows *sqlx.Rows) ([]storage.Event, error) {
 	defer rows.Close()
 
+	result := make([]storage.Event, 0)
 	for rows.Next() {
 		var event storage.Event
+		var exDates, attendees, reminders string
 		err := rows.Scan(&event.ID, &event.UserID, &event.Title, &event.Description, &event.StartTime,
-			&event.FinishTime, &event.NotifyBefore, &event.NotificationSent)
+			&event.FinishTime, &event.Recurrence.RRule, &exDates, &event.TimeZone, &event.Transparent,
+			&event.Version, &event.TraceParent, &attendees, &reminders)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Attendees, err = parseAttendees(attendees)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Reminders, err = parseReminders(reminders)
+		if err != nil {
+			return nil, err
+		}
+
+		event = event.InLocation()
+		event.Recurrence.ExDates, err = storage.ParseExDates(exDates)
 		if err != nil {
 			return nil, err
 		}
@@ -296,19 +618,234 @@ func (s *Storage) SelectEventsToNotify(ctx context.Context) ([]storage.Event, er
 	return result, nil
 }
 
-func (s *Storage) PurgeEvents(ctx context.Context, purgeIntervalDays int) (purgedEvents int64, err error) {
-	query := "delete from events where finish_time < now() - interval '1 day' * $1"
-	result, err := s.db.ExecContext(ctx, query, purgeIntervalDays)
+// saveAttendees заменяет список участников события.
+func saveAttendees(ctx context.Context, tx *sqlx.Tx, event storage.Event) error {
+	_, err := tx.ExecContext(ctx, "delete from event_attendees where event_id = $1", event.ID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	purgedEvents, err = result.RowsAffected()
+	query := "insert into event_attendees(event_id, user_id, status) values($1, $2, $3)"
+	for _, attendee := range event.Attendees {
+		_, err = tx.ExecContext(ctx, query, event.ID, attendee.UserID, string(attendee.Status))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveReminders заменяет список напоминаний события.
+func saveReminders(ctx context.Context, tx *sqlx.Tx, event storage.Event) error {
+	_, err := tx.ExecContext(ctx, "delete from event_reminders where event_id = $1", event.ID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	return purgedEvents, nil
+	query := `insert into event_reminders(id, event_id, offset_minutes, channel, sent_at, occurrence, due_at)
+			  values($1, $2, $3, $4, $5, $6, $7)`
+	now := time.Now()
+	for _, reminder := range event.Reminders {
+		dueAt, err := reminderDueTime(event, reminder, now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query, reminder.ID, event.ID, reminder.Offset, string(reminder.Channel),
+			formatNullableTime(reminder.SentAt), formatNullableTime(reminder.Occurrence), dueAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reminderDueTime возвращает значение столбца due_at: время следующей отправки напоминания
+// или NULL, если отправлять его больше не нужно. Планировщик выбирает напоминания по этому столбцу.
+func reminderDueTime(event storage.Event, reminder storage.Reminder, now time.Time) (*string, error) {
+	next, found, err := event.NextReminderTime(reminder, now)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	dueAt := storage.EventTime(next)
+	return formatNullableTime(&dueAt), nil
+}
+
+// parseReminders разбирает список напоминаний в формате JSON, собранный запросом eventColumns.
+func parseReminders(value string) ([]storage.Reminder, error) {
+	var items []struct {
+		ID         uuid.UUID  `json:"id"`
+		Offset     int        `json:"offset"`
+		Channel    string     `json:"channel"`
+		SentAt     *time.Time `json:"sentAt"`
+		Occurrence *time.Time `json:"occurrence"`
+	}
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	result := make([]storage.Reminder, 0, len(items))
+	for _, item := range items {
+		result = append(result, storage.Reminder{
+			ID:         item.ID,
+			Offset:     item.Offset,
+			Channel:    storage.NotificationChannel(item.Channel),
+			SentAt:     (*storage.EventTime)(item.SentAt),
+			Occurrence: (*storage.EventTime)(item.Occurrence),
+		})
+	}
+
+	return result, nil
+}
+
+// parseAttendees разбирает список участников в формате "user_id:status,...".
+func parseAttendees(value string) ([]storage.Attendee, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	items := strings.Split(value, ",")
+	result := make([]storage.Attendee, 0, len(items))
+	for _, item := range items {
+		userID, status, _ := strings.Cut(item, ":")
+		id, err := uuid.FromString(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, storage.Attendee{UserID: id, Status: storage.AttendeeStatus(status)})
+	}
+
+	return result, nil
+}
+
+// getSeriesFinishTime возвращает время окончания последнего повторения события,
+// nil - для бесконечно повторяющегося события.
+func getSeriesFinishTime(event storage.Event) (*string, error) {
+	seriesFinishTime, finite, err := event.SeriesFinishTime()
+	if err != nil || !finite {
+		return nil, err
+	}
+
+	formatted := seriesFinishTime.UTC().Format(time.RFC3339)
+	return &formatted, nil
+}
+
+func getTimeZone(event storage.Event) string {
+	if event.TimeZone == "" {
+		return storage.DefaultTimeZone
+	}
+
+	return event.TimeZone
+}
+
+func formatNullableTime(eventTime *storage.EventTime) *string {
+	if eventTime == nil {
+		return nil
+	}
+
+	formatted := time.Time(*eventTime).UTC().Format(time.RFC3339)
+	return &formatted
+}
+
+func (s *Storage) GetUserSettings(ctx context.Context, userID uuid.UUID) (storage.UserSettings, error) {
+	ctx, end := startQuery(ctx, "get_user_settings")
+	defer end()
+
+	settings := storage.UserSettings{UserID: userID, TimeZone: storage.DefaultTimeZone}
+	query := "select time_zone from user_settings where user_id = $1"
+	err := s.db.QueryRowxContext(ctx, query, userID).Scan(&settings.TimeZone)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return settings, err
+	}
+
+	return settings, nil
+}
+
+func (s *Storage) UpdateUserSettings(ctx context.Context, settings storage.UserSettings) error {
+	ctx, end := startQuery(ctx, "update_user_settings")
+	defer end()
+
+	query := `insert into user_settings(user_id, time_zone)
+			  values($1, $2)
+			  on conflict (user_id) do update set time_zone = excluded.time_zone`
+	_, err := s.db.ExecContext(ctx, query, settings.UserID, settings.TimeZone)
+	return err
+}
+
+func (s *Storage) GetNotificationSettings(ctx context.Context,
+	userID uuid.UUID,
+) (storage.NotificationSettings, error) {
+	ctx, end := startQuery(ctx, "get_notification_settings")
+	defer end()
+
+	settings := storage.NotificationSettings{UserID: userID, Channel: storage.ChannelLog}
+	query := "select channel, address, locale, digest_time from notification_settings where user_id = $1"
+	err := s.db.QueryRowxContext(ctx, query, userID).Scan(&settings.Channel, &settings.Address, &settings.Locale,
+		&settings.DigestTime)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return settings, err
+	}
+
+	return settings, nil
+}
+
+func (s *Storage) UpdateNotificationSettings(ctx context.Context, settings storage.NotificationSettings) error {
+	ctx, end := startQuery(ctx, "update_notification_settings")
+	defer end()
+
+	query := `insert into notification_settings(user_id, channel, address, locale, digest_time)
+			  values($1, $2, $3, $4, $5)
+			  on conflict (user_id) do update
+			  set channel = excluded.channel, address = excluded.address, locale = excluded.locale,
+			      digest_time = excluded.digest_time`
+	_, err := s.db.ExecContext(ctx, query, settings.UserID, string(settings.Channel), settings.Address,
+		settings.Locale, settings.DigestTime)
+	return err
+}
+
+// ListDigestSubscribers возвращает пользователей, включивших ежедневный дайджест.
+func (s *Storage) ListDigestSubscribers(ctx context.Context) ([]storage.DigestSubscriber, error) {
+	ctx, end := startQuery(ctx, "list_digest_subscribers")
+	defer end()
+
+	query := `select n.user_id, coalesce(u.time_zone, $1), n.digest_time, coalesce(n.last_digest::text, '')
+			  from notification_settings n left join user_settings u on u.user_id = n.user_id
+			  where n.digest_time <> ''`
+	rows, err := s.db.QueryxContext(ctx, query, storage.DefaultTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]storage.DigestSubscriber, 0)
+	for rows.Next() {
+		var subscriber storage.DigestSubscriber
+		err = rows.Scan(&subscriber.UserID, &subscriber.TimeZone, &subscriber.DigestTime, &subscriber.LastDigest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, subscriber)
+	}
+
+	return result, rows.Err()
+}
+
+// MarkDigestSent отмечает, что дайджест пользователю userID за дату date поставлен в outbox.
+func (s *Storage) MarkDigestSent(ctx context.Context, userID uuid.UUID, date storage.EventDate) error {
+	ctx, end := startQuery(ctx, "mark_digest_sent")
+	defer end()
+
+	query := "update notification_settings set last_digest = $2::date where user_id = $1"
+	_, err := s.db.ExecContext(ctx, query, userID, time.Time(date).Format(time.DateOnly))
+	return err
 }
 
 func New(config *config.Config, dsn string) *Storage {
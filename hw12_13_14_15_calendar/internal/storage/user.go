@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// DefaultTimeZone - часовой пояс пользователя, если он не задан в настройках.
+const DefaultTimeZone = "UTC"
+
+// Каналы доставки уведомлений.
+const (
+	ChannelLog     NotificationChannel = "log"
+	ChannelEmail   NotificationChannel = "email"
+	ChannelWebhook NotificationChannel = "webhook"
+)
+
+var (
+	ErrInvalidNotificationChannel = errors.New("invalid notification channel")
+	ErrInvalidLocale              = errors.New("invalid locale")
+)
+
+// localePattern - языковой тег BCP 47 из языка и необязательных подтегов, например "ru" или "en-GB".
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+var locations sync.Map
+
+type UserSettings struct {
+	UserID   uuid.UUID // ID пользователя
+	TimeZone string    // Часовой пояс пользователя по умолчанию (IANA), например "Europe/Moscow"
+}
+
+// LoadLocation возвращает часовой пояс по имени IANA, пустое имя соответствует UTC.
+// Загруженные часовые пояса кэшируются.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Location возвращает часовой пояс пользователя по умолчанию.
+func (u UserSettings) Location() *time.Location {
+	loc, err := LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+type NotificationChannel string
+
+// NotificationSettings - канал, по которому пользователь получает уведомления.
+type NotificationSettings struct {
+	UserID     uuid.UUID           // ID пользователя
+	Channel    NotificationChannel // Канал доставки, по умолчанию ChannelLog
+	Address    string              // Адрес e-mail или URL вебхука, для ChannelLog не используется
+	Locale     string              // Язык уведомлений (BCP 47), пустое значение - язык по умолчанию
+	DigestTime string              // Время ежедневного дайджеста ("08:00"), пустое значение - дайджест отключен
+}
+
+// Validate проверяет канал доставки, адрес получателя, язык уведомлений и время дайджеста.
+func (n NotificationSettings) Validate() error {
+	if n.Locale != "" && !localePattern.MatchString(n.Locale) {
+		return fmt.Errorf("%w %q", ErrInvalidLocale, n.Locale)
+	}
+
+	if err := ValidateDigestTime(n.DigestTime); err != nil {
+		return err
+	}
+
+	switch n.Channel {
+	case ChannelLog:
+		return nil
+	case ChannelEmail:
+		if _, err := mail.ParseAddress(n.Address); err != nil {
+			return fmt.Errorf("%w: invalid email address %q", ErrInvalidNotificationChannel, n.Address)
+		}
+		return nil
+	case ChannelWebhook:
+		u, err := url.Parse(n.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: invalid webhook URL %q", ErrInvalidNotificationChannel, n.Address)
+		}
+		if isInternalHost(u.Hostname()) {
+			return fmt.Errorf("%w: webhook URL %q points to an internal address", ErrInvalidNotificationChannel, n.Address)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidNotificationChannel, n.Channel)
+	}
+}
+
+// IsPublicAddress возвращает false для адресов внутренних сетей: loopback, частных, link-local,
+// multicast и неопределенного адреса.
+func IsPublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// isInternalHost возвращает true для имени localhost и адресов внутренних сетей. Имена, указывающие
+// на внутренние адреса, отклоняются при соединении.
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && !IsPublicAddress(ip)
+}
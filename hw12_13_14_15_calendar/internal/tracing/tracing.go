@@ -0,0 +1,164 @@
+// Package tracing настраивает трассировку OpenTelemetry сервисов календаря и передачу контекста
+// трассировки между ними: в заголовках HTTP, метаданных GRPC, заголовках сообщений очереди
+// и в поле trace_parent событий и сообщений outbox.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+)
+
+// Экспортеры трассировок.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const (
+	instrumentationName = "github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar"
+	defaultOTLPEndpoint = "localhost:4318"
+	traceParentHeader   = "traceparent"
+)
+
+// propagator передает контекст трассировки в формате W3C Trace Context и W3C Baggage.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Init настраивает глобальный TracerProvider сервиса service по конфигурации cfg и возвращает функцию,
+// которая выгружает накопленные трассировки и останавливает экспортер. Если экспортер не задан,
+// трассировки не записываются.
+func Init(cfg config.TracingConf, service string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagator)
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch cfg.Exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = newOTLPExporter(cfg)
+	case ExporterStdout:
+		exporter, closer, err = newStdoutExporter(cfg)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+
+		return err
+	}, nil
+}
+
+func newOTLPExporter(cfg config.TracingConf) (sdktrace.SpanExporter, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultOTLPEndpoint
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+// newStdoutExporter возвращает экспортер, записывающий трассировки в файл cfg.File или в стандартный вывод.
+// Если трассировки записываются в файл, возвращается также io.Closer этого файла.
+func newStdoutExporter(cfg config.TracingConf) (sdktrace.SpanExporter, io.Closer, error) {
+	if cfg.File == "" {
+		exporter, err := stdouttrace.New()
+		return exporter, nil, err
+	}
+
+	file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return exporter, file, nil
+}
+
+// Tracer возвращает трассировщик сервисов календаря из глобального TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// SetError записывает ошибку err в span и отмечает span как завершившийся ошибкой. Если err равна nil,
+// span не изменяется.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject записывает контекст трассировки из ctx в carrier.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// Extract возвращает ctx с контекстом трассировки, прочитанным из carrier.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+// TraceParent возвращает контекст трассировки из ctx в формате заголовка W3C traceparent
+// или пустую строку, если в ctx нет трассировки.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(traceParentHeader)
+}
+
+// ContextWithTraceParent возвращает ctx с контекстом трассировки traceParent в формате W3C traceparent.
+// Если traceParent пустой или неверный, ctx возвращается без изменений.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{traceParentHeader: traceParent})
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/config"
+	"github.com/voitenkov/otus-go-pro/hw12_13_14_15_calendar/internal/tracing/tracingtest"
+)
+
+func TestTraceParent(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		ctx := ContextWithTraceParent(context.Background(), tracingtest.TraceParent)
+		spanContext := trace.SpanContextFromContext(ctx)
+		require.True(t, spanContext.IsRemote())
+		require.Equal(t, tracingtest.TraceID, spanContext.TraceID().String())
+		require.Equal(t, tracingtest.SpanID, spanContext.SpanID().String())
+		require.Equal(t, tracingtest.TraceParent, TraceParent(ctx))
+	})
+
+	t.Run("no trace", func(t *testing.T) {
+		require.Empty(t, TraceParent(context.Background()))
+		ctx := ContextWithTraceParent(context.Background(), "")
+		require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+		ctx = ContextWithTraceParent(context.Background(), "invalid")
+		require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+
+	t.Run("child span", func(t *testing.T) {
+		recorder := tracingtest.NewRecorder(t)
+		ctx := ContextWithTraceParent(context.Background(), tracingtest.TraceParent)
+		ctx, span := Tracer().Start(ctx, "child")
+		traceParent := TraceParent(ctx)
+		span.End()
+
+		require.Len(t, recorder.Ended(), 1)
+		require.Equal(t, tracingtest.SpanID, recorder.Ended()[0].Parent().SpanID().String())
+		require.Equal(t, "00-"+tracingtest.TraceID+"-"+span.SpanContext().SpanID().String()+"-01", traceParent)
+	})
+}
+
+func TestPropagation(t *testing.T) {
+	carrier := propagation.MapCarrier{}
+	Inject(ContextWithTraceParent(context.Background(), tracingtest.TraceParent), carrier)
+	require.Equal(t, tracingtest.TraceParent, carrier.Get("traceparent"))
+
+	ctx := Extract(context.Background(), carrier)
+	require.Equal(t, tracingtest.TraceID, trace.SpanContextFromContext(ctx).TraceID().String())
+}
+
+func TestSetError(t *testing.T) {
+	recorder := tracingtest.NewRecorder(t)
+	_, span := Tracer().Start(context.Background(), "failed")
+	SetError(span, errors.New("boom"))
+	span.End()
+	_, span = Tracer().Start(context.Background(), "succeeded")
+	SetError(span, nil)
+	span.End()
+
+	failed := tracingtest.Span(t, recorder, "failed")
+	require.Equal(t, codes.Error, failed.Status().Code)
+	require.Equal(t, "boom", failed.Status().Description)
+	require.Len(t, failed.Events(), 1)
+	require.Equal(t, codes.Unset, tracingtest.Span(t, recorder, "succeeded").Status().Code)
+}
+
+func TestInit(t *testing.T) {
+	t.Run("stdout exporter writes spans to file", func(t *testing.T) {
+		previous := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+		file := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Init(config.TracingConf{Exporter: ExporterStdout, File: file}, "calendar_test")
+		require.NoError(t, err)
+
+		ctx := ContextWithTraceParent(context.Background(), tracingtest.TraceParent)
+		_, span := Tracer().Start(ctx, "exported")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		type attribute struct {
+			Key   string
+			Value struct{ Value interface{} }
+		}
+		var exported struct {
+			Name        string
+			SpanContext struct{ TraceID string }
+			Resource    []attribute
+		}
+		require.NoError(t, json.Unmarshal(data, &exported))
+		require.Equal(t, "exported", exported.Name)
+		require.Equal(t, tracingtest.TraceID, exported.SpanContext.TraceID)
+
+		serviceName := attribute{Key: "service.name"}
+		serviceName.Value.Value = "calendar_test"
+		require.Contains(t, exported.Resource, serviceName)
+	})
+
+	t.Run("no exporter", func(t *testing.T) {
+		shutdown, err := Init(config.TracingConf{}, "calendar_test")
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Init(config.TracingConf{Exporter: "zipkin"}, "calendar_test")
+		require.EqualError(t, err, `unknown tracing exporter "zipkin"`)
+	})
+}
@@ -0,0 +1,45 @@
+// Package tracingtest помогает проверять в тестах span, записанные через tracing.Tracer.
+package tracingtest
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Контекст трассировки входящего запроса в формате W3C traceparent, его ID трассировки и ID span.
+const (
+	TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	TraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	SpanID      = "00f067aa0ba902b7"
+)
+
+// NewRecorder устанавливает глобальный TracerProvider, записывающий все span, и возвращает
+// их журнал. После завершения теста восстанавливается прежний TracerProvider.
+func NewRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	previous := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// Span возвращает завершенный span с именем name. Тест завершается ошибкой, если такого span нет.
+func Span(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	names := make([]string, 0)
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+		names = append(names, span.Name())
+	}
+
+	t.Fatalf("span %q not found, recorded: %v", name, names)
+	return nil
+}
@@ -0,0 +1,5 @@
+alter table if exists events
+    drop column rrule,
+    drop column exdates,
+    drop column last_notified,
+    drop column series_finish_time;
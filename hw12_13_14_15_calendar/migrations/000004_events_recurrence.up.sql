@@ -0,0 +1,6 @@
+alter table if exists events
+    add column rrule text NOT NULL DEFAULT '',
+    add column exdates text NOT NULL DEFAULT '',
+    add column last_notified timestamptz NULL,
+    add column series_finish_time timestamptz NULL;
+update events set series_finish_time = finish_time;
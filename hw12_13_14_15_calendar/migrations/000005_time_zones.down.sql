@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_settings;
+alter table if exists events
+    drop column time_zone;
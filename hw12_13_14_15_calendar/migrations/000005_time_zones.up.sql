@@ -0,0 +1,7 @@
+alter table if exists events
+    add column time_zone text NOT NULL DEFAULT 'UTC';
+CREATE TABLE IF NOT EXISTS user_settings
+(
+    user_id   uuid PRIMARY KEY,
+    time_zone text NOT NULL DEFAULT 'UTC'
+);
@@ -0,0 +1,2 @@
+alter table if exists events
+    drop column transparent;
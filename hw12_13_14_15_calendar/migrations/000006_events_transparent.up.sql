@@ -0,0 +1,2 @@
+alter table if exists events
+    add column transparent boolean NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS event_attendees
+(
+    event_id uuid NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    user_id  uuid NOT NULL,
+    status   text NOT NULL DEFAULT 'needs-action',
+    PRIMARY KEY (event_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS event_attendees_user_idx
+ON event_attendees (user_id, status);
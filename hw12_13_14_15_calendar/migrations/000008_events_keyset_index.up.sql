@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS events_keyset_idx
+ON events (user_id, start_time, id);
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS events
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS events_search_idx
+ON events USING GIN (search_vector);
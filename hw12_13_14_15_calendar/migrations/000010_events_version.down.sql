@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS events DROP COLUMN IF EXISTS version;
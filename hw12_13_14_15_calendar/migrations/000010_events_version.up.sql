@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS events
+    ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
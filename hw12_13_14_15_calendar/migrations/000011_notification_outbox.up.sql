@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_outbox
+(
+    id           bigserial PRIMARY KEY,
+    dedupe_key   text        NOT NULL UNIQUE,
+    event_id     uuid        NOT NULL,
+    user_id      uuid        NOT NULL,
+    title        varchar     NOT NULL,
+    start_time   timestamptz NOT NULL,
+    recurring    boolean     NOT NULL DEFAULT false,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    published_at timestamptz NULL,
+    delivered_at timestamptz NULL
+);
+CREATE INDEX IF NOT EXISTS notification_outbox_pending_idx
+ON notification_outbox (id) WHERE published_at IS NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS notification_settings
+(
+    user_id uuid PRIMARY KEY,
+    channel text NOT NULL DEFAULT 'log',
+    address text NOT NULL DEFAULT ''
+);
@@ -0,0 +1 @@
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS locale text NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+ALTER TABLE IF EXISTS notification_outbox
+    DROP COLUMN IF EXISTS reminder_id,
+    DROP COLUMN IF EXISTS channel,
+    DROP COLUMN IF EXISTS not_before;
+ALTER TABLE IF EXISTS events
+    ADD COLUMN IF NOT EXISTS notify_before smallint NULL,
+    ADD COLUMN IF NOT EXISTS notification_sent boolean NULL,
+    ADD COLUMN IF NOT EXISTS last_notified timestamptz NULL;
+UPDATE events e
+SET notify_before     = r.offset_minutes,
+    notification_sent = r.sent_at IS NOT NULL,
+    last_notified     = CASE WHEN e.rrule <> '' THEN r.occurrence END
+FROM (SELECT DISTINCT ON (event_id) event_id, offset_minutes, sent_at, occurrence
+      FROM event_reminders
+      ORDER BY event_id, offset_minutes DESC) r
+WHERE r.event_id = e.id;
+DROP TABLE IF EXISTS event_reminders;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS event_reminders
+(
+    id             uuid PRIMARY KEY,
+    event_id       uuid        NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    offset_minutes integer     NOT NULL,
+    channel        text        NOT NULL DEFAULT '',
+    sent_at        timestamptz NULL,
+    occurrence     timestamptz NULL,
+    UNIQUE (event_id, offset_minutes)
+);
+INSERT INTO event_reminders(id, event_id, offset_minutes, sent_at, occurrence)
+SELECT md5(id::text || ':' || notify_before)::uuid,
+       id,
+       notify_before,
+       CASE WHEN notification_sent OR last_notified IS NOT NULL THEN now() END,
+       CASE WHEN rrule = '' AND notification_sent THEN start_time ELSE last_notified END
+FROM events
+WHERE notify_before IS NOT NULL
+ON CONFLICT DO NOTHING;
+ALTER TABLE IF EXISTS events
+    DROP COLUMN IF EXISTS notify_before,
+    DROP COLUMN IF EXISTS notification_sent,
+    DROP COLUMN IF EXISTS last_notified;
+ALTER TABLE IF EXISTS notification_outbox
+    ADD COLUMN IF NOT EXISTS reminder_id uuid NULL,
+    ADD COLUMN IF NOT EXISTS channel text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS not_before timestamptz NOT NULL DEFAULT now();
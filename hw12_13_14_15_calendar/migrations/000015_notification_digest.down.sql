@@ -0,0 +1,7 @@
+DELETE FROM notification_outbox WHERE type <> 'reminder';
+ALTER TABLE IF EXISTS notification_outbox
+    DROP COLUMN IF EXISTS type,
+    DROP COLUMN IF EXISTS agenda;
+ALTER TABLE IF EXISTS notification_settings
+    DROP COLUMN IF EXISTS digest_time,
+    DROP COLUMN IF EXISTS last_digest;
@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS notification_settings
+    ADD COLUMN IF NOT EXISTS digest_time text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_digest date NULL;
+ALTER TABLE IF EXISTS notification_outbox
+    ADD COLUMN IF NOT EXISTS type text NOT NULL DEFAULT 'reminder',
+    ADD COLUMN IF NOT EXISTS agenda text NOT NULL DEFAULT '';
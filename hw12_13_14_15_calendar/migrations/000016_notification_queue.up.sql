@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS notification_queue
+(
+    id           bigserial PRIMARY KEY,
+    queue        text        NOT NULL,
+    body         text        NOT NULL,
+    retries      integer     NOT NULL DEFAULT 0,
+    available_at timestamptz NOT NULL DEFAULT now(),
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    dead_at      timestamptz NULL
+);
+CREATE INDEX IF NOT EXISTS notification_queue_pending_idx
+ON notification_queue (queue, id) WHERE dead_at IS NULL;
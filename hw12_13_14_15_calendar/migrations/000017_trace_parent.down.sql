@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS notification_outbox
+    DROP COLUMN IF EXISTS trace_parent;
+ALTER TABLE IF EXISTS events
+    DROP COLUMN IF EXISTS trace_parent;
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS events
+    ADD COLUMN IF NOT EXISTS trace_parent text NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS notification_outbox
+    ADD COLUMN IF NOT EXISTS trace_parent text NOT NULL DEFAULT '';
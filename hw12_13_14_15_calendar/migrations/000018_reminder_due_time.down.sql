@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS event_reminders_due_idx;
+ALTER TABLE IF EXISTS event_reminders
+    DROP COLUMN IF EXISTS due_at;
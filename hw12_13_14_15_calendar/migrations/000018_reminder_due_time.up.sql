@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS event_reminders
+    ADD COLUMN IF NOT EXISTS due_at timestamptz NULL;
+UPDATE event_reminders r
+SET due_at = CASE
+                 WHEN e.rrule = '' AND r.sent_at IS NULL THEN e.start_time - interval '1 minute' * r.offset_minutes
+                 WHEN e.rrule <> '' AND (e.series_finish_time IS NULL OR e.series_finish_time > now()) THEN now()
+             END
+FROM events e
+WHERE e.id = r.event_id;
+CREATE INDEX IF NOT EXISTS event_reminders_due_idx
+ON event_reminders (due_at) WHERE due_at IS NOT NULL;
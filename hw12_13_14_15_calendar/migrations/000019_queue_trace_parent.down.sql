@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS notification_queue
+    DROP COLUMN IF EXISTS trace_parent;
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS notification_queue
+    ADD COLUMN IF NOT EXISTS trace_parent text NOT NULL DEFAULT '';
@@ -62,11 +62,11 @@ func (s *HTTPTestSuite) SetupTest() {
 	s.NoError(err)
 
 	s.event = &internalhttp.EventRequest{
-		Title:        "Meeting",
-		Description:  "Very important meeting",
-		StartTime:    storage.EventTime(startTime),
-		FinishTime:   storage.EventTime(finishTime),
-		NotifyBefore: 30,
+		Title:       "Meeting",
+		Description: "Very important meeting",
+		StartTime:   storage.EventTime(startTime),
+		FinishTime:  storage.EventTime(finishTime),
+		Reminders:   []internalhttp.ReminderRequest{{Offset: 30}},
 	}
 }
 
@@ -75,6 +75,13 @@ func (s *HTTPTestSuite) TearDownTest() {
 }
 
 func (s *HTTPTestSuite) request(ctx context.Context, method, path string, body io.Reader) *http.Response {
+	return s.requestIfMatch(ctx, method, path, "", body)
+}
+
+// requestIfMatch выполняет запрос с заголовком If-Match, если etag не пуст.
+func (s *HTTPTestSuite) requestIfMatch(ctx context.Context, method, path, etag string,
+	body io.Reader,
+) *http.Response {
 	client := http.Client{
 		Timeout: 30 * time.Second,
 	}
@@ -82,11 +89,24 @@ func (s *HTTPTestSuite) request(ctx context.Context, method, path string, body i
 	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("http://%s:%s/%s", s.host, s.port, path), body)
 	s.NoError(err)
 	req.Header.Add("X-User-Id", userID)
+	if etag != "" {
+		req.Header.Add("If-Match", etag)
+	}
 	response, err := client.Do(req)
 	s.NoError(err)
 	return response
 }
 
+// eventETag возвращает текущий ETag события id.
+func (s *HTTPTestSuite) eventETag(ctx context.Context, id string) string {
+	response := s.request(ctx, http.MethodGet, "events/"+id, nil)
+	defer response.Body.Close()
+	s.Equal(http.StatusOK, response.StatusCode)
+	etag := response.Header.Get("ETag")
+	s.NotEmpty(etag)
+	return etag
+}
+
 func (s *HTTPTestSuite) respBody(response *http.Response) string {
 	respBody, err := io.ReadAll(response.Body)
 	s.NoError(err)
@@ -140,8 +160,9 @@ func (s *HTTPTestSuite) TestFull() {
 	s.Equal("Very important meeting", events[0].Description)
 	s.Equal("2024-01-02 15:00:00", time.Time(events[0].StartTime).Format(time.DateTime))
 	s.Equal("2024-01-02 16:00:00", time.Time(events[0].FinishTime).Format(time.DateTime))
-	s.Equal(30, events[0].NotifyBefore)
-	s.False(events[0].NotificationSent) // check if notification was NOT sent
+	s.Equal(1, len(events[0].Reminders))
+	s.Equal(30, events[0].Reminders[0].Offset)
+	s.False(events[0].IsReminded()) // check if notification was NOT sent
 
 	// Update event test.
 	s.event.Title = "Wedding"
@@ -152,12 +173,18 @@ func (s *HTTPTestSuite) TestFull() {
 	s.NoError(err)
 	s.event.StartTime = storage.EventTime(startTime)
 	s.event.FinishTime = storage.EventTime(finishTime)
-	s.event.NotifyBefore = 60
+	s.event.Reminders = []internalhttp.ReminderRequest{{Offset: 60}}
 	reqBody, err = json.Marshal(s.event)
 	s.NoError(err)
-	response = s.request(ctx, http.MethodPut, "events/"+eventID, bytes.NewReader(reqBody))
+	etag := s.eventETag(ctx, eventID)
+	response = s.requestIfMatch(ctx, http.MethodPut, "events/"+eventID, etag, bytes.NewReader(reqBody))
 	s.Equal(http.StatusOK, response.StatusCode)
 	s.Equal(`{"Status":200,"Message":"event was updated"}`, s.respBody(response))
+
+	// Update event with stale ETag test.
+	response = s.requestIfMatch(ctx, http.MethodPut, "events/"+eventID, etag, bytes.NewReader(reqBody))
+	s.Equal(http.StatusPreconditionFailed, response.StatusCode)
+	response.Body.Close()
 	fmt.Println("...sleeping 1 minute to wait the notification to be sent and the event to be marked by sender...")
 	time.Sleep(time.Minute)
 	fmt.Println("...waked up...")
@@ -177,11 +204,18 @@ func (s *HTTPTestSuite) TestFull() {
 	s.Equal("Very important wedding", events[0].Description)
 	s.Equal("2024-02-01 15:00:00", time.Time(events[0].StartTime).Format(time.DateTime))
 	s.Equal("2024-02-01 16:00:00", time.Time(events[0].FinishTime).Format(time.DateTime))
-	s.Equal(60, events[0].NotifyBefore)
-	s.True(events[0].NotificationSent) // check if notification was sent
+	s.Equal(1, len(events[0].Reminders))
+	s.Equal(60, events[0].Reminders[0].Offset)
+	s.True(events[0].IsReminded()) // check if notification was sent
+
+	// Snooze reminder test.
+	response = s.request(ctx, http.MethodPost, "events/"+eventID+"/reminders/"+events[0].Reminders[0].ID.String()+
+		"/snooze", bytes.NewReader([]byte(`{"minutes":5}`)))
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(`{"Status":200,"Message":"reminder was snoozed"}`, s.respBody(response))
 
 	// Delete event test.
-	response = s.request(ctx, http.MethodDelete, "events/"+eventID, nil)
+	response = s.requestIfMatch(ctx, http.MethodDelete, "events/"+eventID, s.eventETag(ctx, eventID), nil)
 	s.Equal(http.StatusOK, response.StatusCode)
 	s.Equal(`{"Status":200,"Message":"event was deleted"}`, s.respBody(response))
 